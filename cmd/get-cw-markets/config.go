@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	toml "github.com/pelletier/go-toml"
+	"github.com/profioss/clog"
+)
+
+// Config is main configuration.
+type Config struct {
+	Setup      Setup       `toml:"setup"`
+	Watchlists []Watchlist `toml:"watchlists"`
+
+	// cmd line flags, not part of the config file
+	path    string
+	list    bool
+	verbose bool
+}
+
+// Validate checks if Config is valid.
+func (c Config) Validate() error {
+	switch {
+	case c.Setup.Validate() != nil:
+		return fmt.Errorf("Config: %s", c.Setup.Validate())
+
+	case len(c.Watchlists) == 0 && !c.list:
+		return errors.New("Config: empty Watchlists definition")
+	}
+
+	files := map[string]bool{}
+	for _, wl := range c.Watchlists {
+		if wl.Validate() != nil {
+			return fmt.Errorf("Config: %s", wl.Validate())
+		}
+		if files[wl.OutputFile] {
+			return fmt.Errorf("Config: duplicate output-file %s", wl.OutputFile)
+		}
+		files[wl.OutputFile] = true
+	}
+
+	return nil
+}
+
+// Setup defines command setup.
+type Setup struct {
+	BaseURL   string        `toml:"base-url"`
+	Token     string        `toml:"token"`
+	Timeout   time.Duration `toml:"timeout"`
+	LogFile   string        `toml:"log-file"`
+	LogLevel  string        `toml:"log-level"`
+	OutputDir string        `toml:"output-dir"`
+}
+
+// Validate checks if Setup is valid.
+func (s Setup) Validate() error {
+	switch {
+	case s.BaseURL == "":
+		return errors.New("Setup: BaseURL is not specified")
+
+	case s.OutputDir == "":
+		return errors.New("Setup: Output Directory is not specified")
+
+	case s.Timeout < 1:
+		return errors.New("Setup: Timeout is set too low")
+	}
+
+	// LogLevel and LogFile can be empty, safe defaults are used in initConfig()
+
+	return nil
+}
+
+// Watchlist defines generated watchlist.
+// Empty filter list means no filtering.
+type Watchlist struct {
+	Name       string   `toml:"name"`
+	OutputFile string   `toml:"output-file"`
+	Exchanges  []string `toml:"exchanges"` // e.g. coinbase-pro, kraken
+	Quotes     []string `toml:"quotes"`    // quote currencies e.g. usd, eur, usdt
+	Bases      []string `toml:"bases"`     // base currencies e.g. btc, eth
+	FiatBase   bool     `toml:"fiat-base"` // include pairs of fiat currencies e.g. eurusd
+
+	// activity filters; they need market summaries (extra API request)
+	MinVolume float64 `toml:"min-volume"` // 24h volume in quote currency
+	MaxCnt    int     `toml:"max-cnt"`    // the most traded markets only
+
+	// output file is kept if number of markets is less than min-cnt
+	MinCnt int `toml:"min-cnt"`
+}
+
+// Validate checks if Watchlist is valid.
+func (wl Watchlist) Validate() error {
+	switch {
+	case wl.Name == "":
+		return errors.New("Watchlist: Name is not specified")
+
+	case wl.OutputFile == "":
+		return fmt.Errorf("Watchlist %s: OutputFile is not specified", wl.Name)
+
+	case wl.MinVolume < 0:
+		return fmt.Errorf("Watchlist %s: MinVolume is < 0", wl.Name)
+
+	case wl.MaxCnt < 0:
+		return fmt.Errorf("Watchlist %s: MaxCnt is < 0", wl.Name)
+
+	case wl.MinCnt < 0:
+		return fmt.Errorf("Watchlist %s: MinCnt is < 0", wl.Name)
+
+	case wl.MaxCnt > 0 && wl.MaxCnt < wl.MinCnt:
+		return fmt.Errorf("Watchlist %s: MaxCnt is < MinCnt", wl.Name)
+	}
+
+	return nil
+}
+
+// needsSummaries reports if activity filters are used.
+func (wl Watchlist) needsSummaries() bool {
+	return wl.MinVolume > 0 || wl.MaxCnt > 0
+}
+
+func initConfig() (Config, error) {
+	var conf Config
+
+	optConf := flag.String("c", "config/get-cw-markets.toml", "config file")
+	optLogLevel := flag.String("log-level", "", "log levels: disabled | error | warning | info | debug")
+	optDirOut := flag.String("o", "", "output directory")
+	optTimeout := flag.Uint("t", 0, "request timeout in seconds")
+	optList := flag.Bool("list", false, "list exchanges and quote currencies of active markets, don't write watchlists")
+	optVerb := flag.Bool("v", false, "verbose mode")
+	flag.Parse()
+
+	fd, err := os.Open(*optConf)
+	if err != nil {
+		return conf, fmt.Errorf("config open file error: %v", err)
+	}
+	defer fd.Close()
+
+	err = toml.NewDecoder(fd).Decode(&conf)
+	if err != nil {
+		return conf, fmt.Errorf("config %s parse error: %v", *optConf, err)
+	}
+
+	conf.path = *optConf
+	conf.list = *optList
+	conf.verbose = *optVerb
+	conf.Setup.Timeout = time.Duration(conf.Setup.Timeout) * time.Second
+	for i := range conf.Watchlists {
+		wl := &conf.Watchlists[i]
+		wl.Exchanges = lower(wl.Exchanges)
+		wl.Quotes = lower(wl.Quotes)
+		wl.Bases = lower(wl.Bases)
+	}
+	//
+	// override setup from config by cmdline args
+	if *optTimeout > 0 {
+		conf.Setup.Timeout = time.Duration(*optTimeout) * time.Second
+	}
+	if *optDirOut != "" {
+		conf.Setup.OutputDir = *optDirOut
+	}
+
+	// default log level
+	// log levels: disabled | error | warning | info | debug
+	if conf.Setup.LogLevel == "" {
+		conf.Setup.LogLevel = "info"
+	}
+	if *optLogLevel != "" {
+		conf.Setup.LogLevel = *optLogLevel
+	}
+	_, err = clog.LevelFromString(conf.Setup.LogLevel)
+	if err != nil {
+		return conf, fmt.Errorf("invalid log level: %v", err)
+	}
+	// disable logging if no log file was specified
+	if conf.Setup.LogFile == "" {
+		conf.Setup.LogLevel = "disabled"
+	}
+
+	return conf, conf.Validate()
+}
+
+func lower(lst []string) []string {
+	output := make([]string, 0, len(lst))
+	for _, s := range lst {
+		output = append(output, strings.ToLower(strings.TrimSpace(s)))
+	}
+	return output
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// exchange is Cryptowatch exchange.
+type exchange struct {
+	Symbol string `json:"symbol"`
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+}
+
+// market is Cryptowatch market - pair traded on exchange.
+type market struct {
+	Exchange string `json:"exchange"`
+	Pair     string `json:"pair"`
+	Active   bool   `json:"active"`
+}
+
+// key identifies market in market summaries.
+func (m market) key() string {
+	return m.Exchange + ":" + m.Pair
+}
+
+// asset is Cryptowatch asset (currency).
+type asset struct {
+	Symbol string `json:"symbol"`
+	Name   string `json:"name"`
+	Fiat   bool   `json:"fiat"`
+}
+
+// pair is Cryptowatch currency pair.
+type pair struct {
+	Symbol string `json:"symbol"`
+	Base   asset  `json:"base"`
+	Quote  asset  `json:"quote"`
+}
+
+// summary is Cryptowatch 24h market summary.
+type summary struct {
+	Volume      json.Number `json:"volume"`
+	VolumeQuote json.Number `json:"volumeQuote"`
+}
+
+// cursor is Cryptowatch pagination cursor of list responses.
+type cursor struct {
+	Last    string `json:"last"`
+	HasMore bool   `json:"hasMore"`
+}
+
+// respCW is Cryptowatch API response.
+type respCW struct {
+	Result json.RawMessage `json:"result"`
+	Cursor *cursor         `json:"cursor"`
+}
+
+// maxPages limits number of requests of paginated list.
+const maxPages = 100
+
+// errAllowance means exhausted API allowance (HTTP 429),
+// see https://docs.cryptowat.ch/rest-api/rate-limit
+var errAllowance = errors.New("API allowance exhausted")
+
+// directory is collection of Cryptowatch exchanges, markets and pairs.
+type directory struct {
+	exchanges map[string]exchange
+	markets   []market
+	pairs     map[string]pair
+	volumes   map[string]float64 // 24h volume in quote currency by market.key(); nil if not fetched
+}
+
+// getDirectory fetches exchanges, markets and pairs; market summaries
+// are fetched if summaries is true.
+func getDirectory(ctx context.Context, app App, summaries bool) (directory, error) {
+	d := directory{exchanges: map[string]exchange{}, pairs: map[string]pair{}}
+
+	exchanges := []exchange{}
+	if err := getList(ctx, app, "/exchanges", func(data json.RawMessage) error {
+		lst := []exchange{}
+		err := json.Unmarshal(data, &lst)
+		exchanges = append(exchanges, lst...)
+		return err
+	}); err != nil {
+		return d, fmt.Errorf("exchanges: %v", err)
+	}
+	for _, e := range exchanges {
+		d.exchanges[e.Symbol] = e
+	}
+	app.log.Debugf("exchanges: %d", len(d.exchanges))
+
+	if err := getList(ctx, app, "/markets", func(data json.RawMessage) error {
+		lst := []market{}
+		err := json.Unmarshal(data, &lst)
+		d.markets = append(d.markets, lst...)
+		return err
+	}); err != nil {
+		return d, fmt.Errorf("markets: %v", err)
+	}
+	app.log.Debugf("markets: %d", len(d.markets))
+
+	if err := getList(ctx, app, "/pairs", func(data json.RawMessage) error {
+		lst := []pair{}
+		err := json.Unmarshal(data, &lst)
+		for _, p := range lst {
+			d.pairs[p.Symbol] = p
+		}
+		return err
+	}); err != nil {
+		return d, fmt.Errorf("pairs: %v", err)
+	}
+	app.log.Debugf("pairs: %d", len(d.pairs))
+
+	if !summaries {
+		return d, nil
+	}
+	d.volumes = map[string]float64{}
+	if err := getList(ctx, app, "/markets/summaries", func(data json.RawMessage) error {
+		lst := map[string]summary{}
+		if err := json.Unmarshal(data, &lst); err != nil {
+			return err
+		}
+		for k, s := range lst {
+			v, err := strconv.ParseFloat(s.VolumeQuote.String(), 64)
+			if err != nil {
+				return fmt.Errorf("%s: invalid volumeQuote %q", k, s.VolumeQuote)
+			}
+			d.volumes[k] = v
+		}
+		return nil
+	}); err != nil {
+		return d, fmt.Errorf("market summaries: %v", err)
+	}
+	app.log.Debugf("market summaries: %d", len(d.volumes))
+
+	return d, nil
+}
+
+// getList fetches all pages of API endpoint; result of each page
+// is passed to parse function.
+func getList(ctx context.Context, app App, endpoint string, parse func(json.RawMessage) error) error {
+	last := ""
+	for page := 0; page < maxPages; page++ {
+		u, err := mkURL(app.Config, endpoint, last)
+		if err != nil {
+			return err
+		}
+		data, err := fetch(ctx, app, u)
+		if err != nil {
+			return err
+		}
+
+		resp := respCW{}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return fmt.Errorf("parse error: %v", err)
+		}
+		if err := parse(resp.Result); err != nil {
+			return fmt.Errorf("parse error: %v", err)
+		}
+		if resp.Cursor == nil || !resp.Cursor.HasMore || resp.Cursor.Last == "" {
+			return nil
+		}
+		last = resp.Cursor.Last
+	}
+
+	return fmt.Errorf("too many pages (> %d)", maxPages)
+}
+
+func fetch(ctx context.Context, app App, u url.URL) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if app.Setup.Token != "" {
+		req.Header.Set("X-CW-API-Key", app.Setup.Token)
+	}
+
+	resp, err := app.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, errAllowance
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch: HTTP Status: %s. URL: %s", resp.Status, u.String())
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return body, fmt.Errorf("fetch: Read Error: %s", err)
+	}
+
+	return body, nil
+}
+
+// mkURL generates API URL of endpoint; cursor is used for pagination
+// see https://docs.cryptowat.ch/rest-api
+func mkURL(conf Config, endpoint, cursor string) (url.URL, error) {
+	str := strings.TrimSuffix(conf.Setup.BaseURL, "/") + endpoint
+
+	u, err := url.Parse(str)
+	if err != nil {
+		return url.URL{}, fmt.Errorf("invalid URL string: %v", err)
+	}
+	if cursor != "" {
+		q := u.Query()
+		q.Set("cursor", cursor)
+		u.RawQuery = q.Encode()
+	}
+
+	return *u, nil
+}
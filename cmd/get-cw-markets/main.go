@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+)
+
+func main() {
+	exitCode := 0
+	wg := sync.WaitGroup{}
+
+	app, err := newApp()
+	if err != nil {
+		log.Fatal("App init error: ", err)
+	}
+	defer app.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGTERM, os.Interrupt)
+
+	// common cleanup
+	defer func() {
+		signal.Stop(sigChan)
+		cancel()
+		cleanup(app)
+		wg.Wait()
+		os.Exit(exitCode)
+	}()
+
+	wg.Add(1)
+	go func() {
+		select {
+		case s := <-sigChan:
+			app.log.Warnf("Got %s signal - exitting", s)
+			cancel()
+			app.log.Info("Stopped")
+		case <-ctx.Done():
+			app.log.Info("DONE")
+		}
+		wg.Done()
+	}()
+
+	app.log.Info("Starting")
+	err = do(ctx, app)
+	if err != nil {
+		exitCode = 1
+		app.log.Errorf("Get markets error: %s", err)
+		return
+	}
+}
+
+func do(ctx context.Context, app App) error {
+	summaries := false
+	for _, wl := range app.Watchlists {
+		summaries = summaries || wl.needsSummaries()
+	}
+
+	d, err := getDirectory(ctx, app, summaries && !app.list)
+	if err != nil {
+		return err
+	}
+	if app.list {
+		printExchanges(os.Stdout, d)
+		return nil
+	}
+
+	results := make([]result, 0, len(app.Watchlists))
+	for _, wl := range app.Watchlists {
+		res := mkWatchlist(app, wl, d)
+		if res.err != nil {
+			app.log.Error(res.err)
+		}
+		results = append(results, res)
+	}
+	printSummary(os.Stdout, results)
+
+	failed := 0
+	for _, res := range results {
+		if res.err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d watchlists failed", failed, len(results))
+	}
+
+	return nil
+}
+
+// mkWatchlist builds and saves watchlist wl.
+func mkWatchlist(app App, wl Watchlist, d directory) result {
+	res := result{wl: wl, file: filepath.Join(app.Setup.OutputDir, wl.OutputFile)}
+
+	specs, err := build(wl, d)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	res.count = len(specs)
+
+	// don't overwrite with insufficient data
+	if res.count < wl.MinCnt {
+		res.err = fmt.Errorf("%s: %d markets found, min-cnt is %d; keeping %s",
+			wl.Name, res.count, wl.MinCnt, res.file)
+		return res
+	}
+
+	err = saveData(res.file, specs)
+	if err != nil {
+		res.err = fmt.Errorf("%s: saveData to %s failed: %s", wl.Name, res.file, err)
+		return res
+	}
+	app.log.Infof("%s: %s - OK", wl.Name, res.file)
+
+	return res
+}
+
+func cleanup(app App) {
+	app.log.Info("Cleaning up...")
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/profioss/clog"
+	"github.com/profioss/trada/model/instrument"
+)
+
+// newServer serves testdata/cw/<path>.json; page of paginated
+// list is served from <path>-<cursor>.json.
+func newServer(requests *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.URL.RequestURI())
+		fname := filepath.Join("testdata", "cw", r.URL.Path)
+		if c := r.URL.Query().Get("cursor"); c != "" {
+			fname += "-" + c
+		}
+		data, err := ioutil.ReadFile(fname + ".json")
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+}
+
+func mkTestApp(t *testing.T, srv *httptest.Server, dir string, wls ...Watchlist) App {
+	t.Helper()
+
+	logger, err := clog.New(ioutil.Discard, "disabled", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := App{client: srv.Client(), log: logger}
+	app.Config.Setup = Setup{BaseURL: srv.URL, Timeout: 5 * time.Second, OutputDir: dir}
+	app.Config.Watchlists = wls
+	if err := app.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	return app
+}
+
+func TestGetDirectory(t *testing.T) {
+	requests := []string{}
+	srv := newServer(&requests)
+	defer srv.Close()
+
+	app := mkTestApp(t, srv, "testdata", Watchlist{Name: "all", OutputFile: "all.csv"})
+	d, err := getDirectory(context.Background(), app, true)
+	switch {
+	case err != nil:
+		t.Fatalf("unexpected error: %v", err)
+	case len(d.exchanges) != 3 || len(d.markets) != 9 || len(d.pairs) != 7 || len(d.volumes) != 7:
+		t.Errorf("unexpected directory: %d exchanges, %d markets, %d pairs, %d volumes",
+			len(d.exchanges), len(d.markets), len(d.pairs), len(d.volumes))
+	case d.volumes["kraken:btcusd"] != 46275000:
+		t.Errorf("unexpected volume: %v", d.volumes["kraken:btcusd"])
+	}
+	want := "/exchanges /markets /markets?cursor=page2 /pairs /markets/summaries"
+	if got := strings.Join(requests, " "); got != want {
+		t.Errorf("expected requests %s, got %s", want, got)
+	}
+
+	app.Setup.BaseURL = srv.URL + "/missing"
+	if _, err := getDirectory(context.Background(), app, false); err == nil {
+		t.Errorf("missing endpoint - should have an error")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	requests := []string{}
+	srv := newServer(&requests)
+	defer srv.Close()
+
+	app := mkTestApp(t, srv, "testdata", Watchlist{Name: "all", OutputFile: "all.csv"})
+	d, err := getDirectory(context.Background(), app, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		label     string
+		wl        Watchlist
+		noVolumes bool     // market summaries not fetched
+		output    []string // symbol@exchange
+		hasErr    bool
+	}{
+		{"all active", Watchlist{}, false,
+			[]string{"btceur@coinbase-pro", "btcusd@coinbase-pro", "btcusd@kraken",
+				"btcusdt@kraken", "ethbtc@kraken", "ethusd@coinbase-pro"}, false},
+		{"fiat base", Watchlist{Exchanges: []string{"kraken"}, FiatBase: true}, false,
+			[]string{"btcusd@kraken", "btcusdt@kraken", "ethbtc@kraken", "eurusd@kraken"}, false},
+		{"quote", Watchlist{Quotes: []string{"usd", "usdt"}}, false,
+			[]string{"btcusd@coinbase-pro", "btcusd@kraken", "btcusdt@kraken", "ethusd@coinbase-pro"}, false},
+		{"exchange and base", Watchlist{Exchanges: []string{"coinbase-pro"}, Bases: []string{"btc"}}, false,
+			[]string{"btceur@coinbase-pro", "btcusd@coinbase-pro"}, false},
+		{"min volume", Watchlist{MinVolume: 1e7}, false,
+			[]string{"btcusd@coinbase-pro", "btcusd@kraken", "ethusd@coinbase-pro"}, false},
+		{"max cnt", Watchlist{MaxCnt: 2}, false,
+			[]string{"btcusd@coinbase-pro", "btcusd@kraken"}, false},
+		{"no summaries", Watchlist{MaxCnt: 2}, true, nil, true},
+	}
+
+	for _, tt := range tests {
+		dd := d
+		if tt.noVolumes {
+			dd.volumes = nil
+		}
+		specs, err := build(tt.wl, dd)
+		switch {
+		case tt.hasErr && err == nil:
+			t.Errorf("%s - should have an error", tt.label)
+			continue
+		case !tt.hasErr && err != nil:
+			t.Errorf("%s - unexpected error: %v", tt.label, err)
+			continue
+		case tt.hasErr:
+			continue
+		}
+		got := []string{}
+		for _, s := range specs {
+			got = append(got, s.Symbol+"@"+s.Exchange)
+		}
+		if strings.Join(got, " ") != strings.Join(tt.output, " ") {
+			t.Errorf("%s - expected %v, got %v", tt.label, tt.output, got)
+		}
+	}
+}
+
+func TestDo(t *testing.T) {
+	requests := []string{}
+	srv := newServer(&requests)
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "get-cw-markets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	app := mkTestApp(t, srv, dir,
+		Watchlist{Name: "usd", OutputFile: "watchlist-crypto-usd.csv", Exchanges: []string{"coinbase-pro"},
+			Quotes: []string{"usd"}, MinCnt: 2},
+		Watchlist{Name: "eur", OutputFile: "watchlist-crypto-eur.csv", Quotes: []string{"eur"}, MinCnt: 2},
+		Watchlist{Name: "usd-all", OutputFile: "watchlist-crypto-usd-all.csv", Quotes: []string{"usd"}},
+	)
+	if err := do(context.Background(), app); err == nil {
+		t.Errorf("eur watchlist below min-cnt - should have an error")
+	}
+	for _, r := range requests {
+		if r == "/markets/summaries" {
+			t.Errorf("market summaries should not be fetched without activity filters")
+		}
+	}
+
+	fd, err := os.Open(filepath.Join(dir, "watchlist-crypto-usd.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	specs, err := instrument.SpecLstFromCSV(fd)
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case len(specs) != 2:
+		t.Errorf("expected 2 markets, got %+v", specs)
+	case specs[0].Symbol != "btcusd" || specs[0].Exchange != "coinbase-pro" ||
+		specs[0].SecurityType != instrument.Crypto || specs[0].Currency != "USD" ||
+		specs[0].Description != "Bitcoin / USD":
+		t.Errorf("unexpected spec: %+v", specs[0])
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "watchlist-crypto-eur.csv")); err == nil {
+		t.Errorf("eur watchlist should not be written")
+	}
+
+	// multi-exchange watchlist; the golden file is input of get-md-cw tests
+	got, err := ioutil.ReadFile(filepath.Join(dir, "watchlist-crypto-usd-all.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := ioutil.ReadFile(filepath.Join("testdata", "watchlist-crypto-usd-all.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(expected) {
+		t.Errorf("multi-exchange watchlist should be:\n%s\ngot:\n%s", expected, got)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// result is outcome of processing single watchlist.
+type result struct {
+	wl    Watchlist
+	file  string
+	count int
+	err   error
+}
+
+func (r result) status() string {
+	if r.err != nil {
+		return r.err.Error()
+	}
+	return "OK"
+}
+
+// printSummary writes table of processed watchlists.
+func printSummary(w io.Writer, results []result) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "WATCHLIST\tMARKETS\tFILE\tSTATUS")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", r.wl.Name, r.count, r.file, r.status())
+	}
+	tw.Flush()
+}
+
+// printExchanges writes table of active exchanges with number
+// of active markets and their quote currencies.
+func printExchanges(w io.Writer, d directory) {
+	markets := map[string]int{}
+	quotes := map[string]map[string]bool{}
+	for _, m := range d.markets {
+		if !m.Active {
+			continue
+		}
+		markets[m.Exchange]++
+		if quotes[m.Exchange] == nil {
+			quotes[m.Exchange] = map[string]bool{}
+		}
+		if p, ok := d.pairs[m.Pair]; ok {
+			quotes[m.Exchange][p.Quote.Symbol] = true
+		}
+	}
+
+	symbols := []string{}
+	for s, e := range d.exchanges {
+		if e.Active {
+			symbols = append(symbols, s)
+		}
+	}
+	sort.Strings(symbols)
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "EXCHANGE\tNAME\tMARKETS\tQUOTES")
+	for _, s := range symbols {
+		q := []string{}
+		for sym := range quotes[s] {
+			q = append(q, sym)
+		}
+		sort.Strings(q)
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", s, d.exchanges[s].Name, markets[s], strings.Join(q, ","))
+	}
+	tw.Flush()
+}
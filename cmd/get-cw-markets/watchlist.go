@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/pkg/osutil"
+)
+
+// build provides markets of directory d matching Watchlist filters.
+// Only active markets of active exchanges are used. Markets are sorted
+// by symbol and exchange.
+func build(wl Watchlist, d directory) ([]instrument.Spec, error) {
+	if wl.needsSummaries() && d.volumes == nil {
+		return nil, fmt.Errorf("%s: market summaries are not available", wl.Name)
+	}
+
+	type item struct {
+		spec   instrument.Spec
+		volume float64
+	}
+	items := []item{}
+	for _, m := range d.markets {
+		e, ok := d.exchanges[m.Exchange]
+		if !ok || !e.Active || !m.Active {
+			continue
+		}
+		p, ok := d.pairs[m.Pair]
+		switch {
+		case !ok:
+			continue
+		case !contains(wl.Exchanges, m.Exchange):
+			continue
+		case !contains(wl.Quotes, p.Quote.Symbol):
+			continue
+		case !contains(wl.Bases, p.Base.Symbol):
+			continue
+		case p.Base.Fiat && !wl.FiatBase:
+			continue
+		}
+
+		vol := d.volumes[m.key()]
+		if vol < wl.MinVolume {
+			continue
+		}
+
+		items = append(items, item{spec: mkSpec(m, p), volume: vol})
+	}
+
+	if wl.MaxCnt > 0 && len(items) > wl.MaxCnt {
+		sort.SliceStable(items, func(i, j int) bool { return items[i].volume > items[j].volume })
+		items = items[:wl.MaxCnt]
+	}
+
+	output := make([]instrument.Spec, 0, len(items))
+	for _, it := range items {
+		output = append(output, it.spec)
+	}
+	sort.Slice(output, func(i, j int) bool {
+		if output[i].Symbol != output[j].Symbol {
+			return output[i].Symbol < output[j].Symbol
+		}
+		return output[i].Exchange < output[j].Exchange
+	})
+
+	return output, nil
+}
+
+// mkSpec creates instrument of market m with pair p.
+func mkSpec(m market, p pair) instrument.Spec {
+	s := instrument.Spec{
+		Symbol:       m.Pair,
+		Description:  fmt.Sprintf("%s / %s", p.Base.Name, strings.ToUpper(p.Quote.Symbol)),
+		SecurityType: instrument.Crypto,
+		Exchange:     m.Exchange,
+	}
+	if p.Quote.Fiat { // ISO 4217 code
+		s.Currency = strings.ToUpper(p.Quote.Symbol)
+	}
+	return s
+}
+
+// contains reports if lst contains s; empty lst contains anything.
+func contains(lst []string, s string) bool {
+	if len(lst) == 0 {
+		return true
+	}
+	for _, x := range lst {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func saveData(fpath string, specs []instrument.Spec) error {
+	dirname := filepath.Dir(fpath)
+	err := os.MkdirAll(dirname, osutil.DirPerms)
+	if err != nil {
+		return err
+	}
+
+	fdTmp, err := os.Create(fpath + ".swp")
+	if err != nil {
+		return fmt.Errorf("creating temp output file failed: %s", err)
+	}
+	defer os.Remove(fdTmp.Name())
+
+	err = instrument.SpecLstToCSV(fdTmp, specs)
+	if errClose := fdTmp.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return fmt.Errorf("instrument.SpecLstToCSV: %v", err)
+	}
+
+	err = os.Chmod(fdTmp.Name(), osutil.FilePerms)
+	if err != nil {
+		return fmt.Errorf("chmod %s %s: %s", osutil.FilePerms.String(), fdTmp.Name(), err)
+	}
+	err = os.Rename(fdTmp.Name(), fpath)
+	if err != nil {
+		return fmt.Errorf("rename %s -> %s: %s", fdTmp.Name(), fpath, err)
+	}
+
+	return nil
+}
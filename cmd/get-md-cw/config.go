@@ -116,16 +116,20 @@ func (c Config) Validate() error {
 
 // Setup defines command setup.
 type Setup struct {
-	Range      dataRange
-	BaseURL    string
-	Exchange   string
-	Token      string
-	Timeout    time.Duration
-	MaxProcs   int
-	LogFile    string
-	LogLevel   string
-	OutputDir  string
-	Watchlists []string
+	Range       dataRange
+	BaseURL     string
+	Exchange    string
+	Token       string
+	Timeout     time.Duration
+	MaxProcs    int
+	LogFile     string
+	LogLevel    string
+	OutputDir   string
+	MetricsAddr string
+	MetricsFile string
+	BookDepth   int // order book snapshot levels per side; 0 disables snapshots
+	TradesLimit int // number of captured recent trades; 0 disables capture
+	Watchlists  []string
 }
 
 // Validate checks if Setup is valid.
@@ -145,6 +149,12 @@ func (s Setup) Validate() error {
 
 	case s.Timeout < 1:
 		return errors.New("Setup: Timeout is set too low")
+
+	case s.BookDepth < 0:
+		return errors.New("Setup: BookDepth is less than zero")
+
+	case s.TradesLimit < 0:
+		return errors.New("Setup: TradesLimit is less than zero")
 	}
 
 	return nil
@@ -157,6 +167,8 @@ func initConfig() (Config, error) {
 	optRange := flag.String("r", "", "data range, use one of: "+rangeLstStr(validRange))
 	optSymbols := flag.String("s", "", "symbols delimited by comma (ex: BTCUSD,ETHEUR,XRPUSD)")
 	optTimeout := flag.Uint("t", 0, "request timeout in seconds")
+	optBook := flag.Uint("book", 0, "capture order book snapshot of top n levels")
+	optTrades := flag.Uint("trades", 0, "capture n recent trades")
 	// optTstData := flag.Bool("update-test-data", false, "update test data - use with -o testdata")
 	optVerb := flag.Bool("v", false, "verbose mode")
 	flag.Parse()
@@ -188,6 +200,12 @@ func initConfig() (Config, error) {
 	if *optRange != "" {
 		conf.Setup.Range = newDataRange(*optRange)
 	}
+	if *optBook > 0 {
+		conf.Setup.BookDepth = int(*optBook)
+	}
+	if *optTrades > 0 {
+		conf.Setup.TradesLimit = int(*optTrades)
+	}
 
 	conf.symbols = []string{}
 	if *optSymbols != "" {
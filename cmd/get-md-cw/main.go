@@ -2,12 +2,14 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
 
+	"github.com/profioss/trada/pkg/manifest"
 	"github.com/profioss/trada/pkg/osutil"
 )
 
@@ -34,8 +36,8 @@ func main() {
 		os.Exit(exitCode)
 	}()
 
+	wg.Add(1)
 	go func() {
-		wg.Add(1)
 		select {
 		case s := <-sigChan:
 			app.log.Warnf("Got %s signal - exitting", s)
@@ -64,7 +66,36 @@ func main() {
 }
 
 func do(ctx context.Context, app App) error {
-	return getData(ctx, app)
+	if app.Setup.MetricsAddr != "" {
+		stop, err := app.metrics.Serve(app.Setup.MetricsAddr)
+		if err != nil {
+			return fmt.Errorf("metrics server error: %v", err)
+		}
+		defer stop()
+		app.log.Infof("Serving metrics at http://%s/metrics", app.Setup.MetricsAddr)
+	}
+
+	m := manifest.New("get-md-cw", "cryptowatch/"+app.Config.Setup.Exchange, string(app.Config.Setup.Range))
+	err := getData(ctx, app, m)
+	m.Finish()
+	app.metrics.Manifest(m)
+
+	fpath, errSave := m.Save(app.Setup.OutputDir)
+	if errSave != nil {
+		app.log.Errorf("Save manifest error: %s", errSave)
+	} else {
+		app.log.Infof("Manifest saved to %s", fpath)
+	}
+	if app.Setup.MetricsFile != "" {
+		if errMetrics := app.metrics.Registry.WriteFile(app.Setup.MetricsFile); errMetrics != nil {
+			app.log.Errorf("Write metrics error: %s", errMetrics)
+		} else {
+			app.log.Infof("Metrics written to %s", app.Setup.MetricsFile)
+		}
+	}
+	m.PrintSummary(os.Stdout)
+
+	return err
 }
 
 func cleanup(app App) {
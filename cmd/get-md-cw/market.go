@@ -1,9 +1,11 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -18,6 +20,7 @@ import (
 	"github.com/profioss/trada/model/instrument"
 	"github.com/profioss/trada/model/ohlc"
 	"github.com/profioss/trada/model/ohlc/ohlcio"
+	"github.com/profioss/trada/pkg/manifest"
 	"github.com/profioss/trada/pkg/osutil"
 	"github.com/profioss/trada/pkg/typedef"
 	"github.com/shopspring/decimal"
@@ -34,22 +37,22 @@ type allowance struct {
 	Remaining int64 `json:"remaining"`
 }
 
-func parse(data []byte) ([]ohlc.OHLC, error) {
+func parse(data []byte) ([]ohlc.OHLC, allowance, error) {
 	output := []ohlc.OHLC{}
 	resp := respCW{}
 
 	err := json.Unmarshal(data, &resp)
 	if err != nil {
-		return output, err
+		return output, resp.Allowance, err
 	}
 
 	if len(resp.Result) != 1 {
-		return output,
+		return output, resp.Allowance,
 			fmt.Errorf("parse: expected map with 1 key equal to 86400, got %d key(s)", len(resp.Result))
 	}
 	input, ok := resp.Result["86400"]
 	if !ok {
-		return output,
+		return output, resp.Allowance,
 			fmt.Errorf("parse: expected map with 1 key equal to 86400, the key not found")
 	}
 
@@ -57,12 +60,12 @@ func parse(data []byte) ([]ohlc.OHLC, error) {
 	for _, bar := range input {
 		ohlc, err := ohlcFromCWbar(bar)
 		if err != nil {
-			return output, err
+			return output, resp.Allowance, err
 		}
 		output = append(output, ohlc)
 	}
 
-	return output, nil
+	return output, resp.Allowance, nil
 }
 
 func ohlcFromCWbar(data []json.Number) (ohlc.OHLC, error) {
@@ -118,64 +121,151 @@ func ohlcFromCWbar(data []json.Number) (ohlc.OHLC, error) {
 	return output, nil
 }
 
-func getData(ctx context.Context, app App) error {
+func getData(ctx context.Context, app App, m *manifest.Manifest) error {
 	var err error
-	tickers := app.Config.symbols // specified by command line param
-	if len(tickers) == 0 {        // no symbol specified by command line param
-		tickers, err = loadTickers(app)
+	specs := []instrument.Spec{}
+	for _, sym := range app.Config.symbols { // specified by command line param
+		specs = append(specs, instrument.Spec{Symbol: sym, SecurityType: instrument.Crypto})
+	}
+	if len(specs) == 0 { // no symbol specified by command line param
+		specs, err = loadInstruments(app)
 		if err != nil {
-			return fmt.Errorf("loadTickers failed %s", err)
+			return fmt.Errorf("loadInstruments failed %s", err)
 		}
 	}
-	if len(tickers) < app.Config.Setup.MaxProcs && len(tickers) > 0 {
-		app.Config.Setup.MaxProcs = len(tickers)
+	if len(specs) < app.Config.Setup.MaxProcs && len(specs) > 0 {
+		app.Config.Setup.MaxProcs = len(specs)
 	}
 
-	for _, t := range tickers {
-		data, err := fetch(t, app)
-		if err != nil {
-			app.log.Errorf("%s: fetch error: %s", t, err)
-			continue
+	skip := func(reason string, specs []instrument.Spec) {
+		for _, s := range specs {
+			m.Skip(reason, label(app.Config, s))
 		}
-		app.log.Debugf("%s: fetch - OK", t)
+	}
+	snapshots := app.Config.Setup.BookDepth > 0 || app.Config.Setup.TradesLimit > 0
 
-		fname := filepath.Join(app.Config.Setup.OutputDir, t)
+	for i, spec := range specs {
+		select {
+		case <-ctx.Done():
+			skip("operation cancelled", specs[i:])
+			return fmt.Errorf("operation cancelled")
+		default:
+		}
 
-		dataOHLC, err := parse(data)
+		name := label(app.Config, spec)
+		rec, err := getNstore(app, spec)
+		if err == errAllowance {
+			m.Add(rec)
+			skip(err.Error(), specs[i+1:])
+			return fmt.Errorf("%s: %s - remaining symbols skipped", name, err)
+		}
 		if err != nil {
-			// store problematic data to .../dir/fname.json.swp for analysis
-			fname += ".json.swp"
-			app.log.Errorf("%s: parse error: %s; check %s", t, err, fname)
-			osutil.WriteFile(fname, data)
-			continue
+			app.log.Errorf("%s: %s", name, err)
+		} else {
+			app.log.Debugf("%s: saved to %s", name, rec.File)
 		}
-		app.log.Debugf("%s: parse - OK", t)
 
-		fname += ".csv"
-		dataCSV := ohlcio.ToCSV(dataOHLC, instrument.Crypto)
-		err = saveData(fname, dataCSV)
+		if !snapshots {
+			m.Add(rec)
+			continue
+		}
+		files, err := getSnapshot(app, spec)
+		if err != nil {
+			rec.Snapshot = manifest.StatusFailed
+			rec.SnapshotError = err.Error()
+		} else {
+			rec.Snapshot = manifest.StatusOK
+		}
+		m.Add(rec)
+		if err == errAllowance {
+			skip(err.Error(), specs[i+1:])
+			return fmt.Errorf("%s: snapshot: %s - remaining symbols skipped", name, err)
+		}
 		if err != nil {
-			app.log.Errorf("%s: saveData error: %s", t, err)
+			app.log.Errorf("%s: snapshot: %s", name, err)
+			continue
 		}
-		app.log.Debugf("%s: saved to %s", t, fname)
+		app.log.Debugf("%s: snapshot saved to %s", name, strings.Join(files, ", "))
 	}
 
 	return nil
 }
 
-func fetch(ticker string, app App) ([]byte, error) {
-	body := []byte{}
-	url, err := mkURL(app.Config, ticker)
+// getNstore fetches and stores data of spec,
+// returned record describes the outcome for run manifest.
+func getNstore(app App, spec instrument.Spec) (manifest.Symbol, error) {
+	t := label(app.Config, spec)
+	rec := manifest.Symbol{Symbol: t, Status: manifest.StatusFailed}
+	fail := func(err error) (manifest.Symbol, error) {
+		rec.Error = err.Error()
+		return rec, err
+	}
+
+	data, err := fetch(spec, app)
+	if err == errAllowance {
+		return fail(err)
+	}
+	if err != nil {
+		return fail(fmt.Errorf("fetch error: %s", err))
+	}
+	app.log.Debugf("%s: fetch - OK", t)
+
+	fname := filepath.Join(dataDir(app.Config, spec), spec.Symbol)
+
+	dataOHLC, allow, err := parse(data)
+	if err != nil {
+		// store problematic data to .../dir/fname.json.swp for analysis
+		fname += ".json.swp"
+		osutil.WriteFile(fname, data)
+		return fail(fmt.Errorf("parse error: %s; check %s", err, fname))
+	}
+	app.log.Debugf("%s: parse - OK", t)
+	app.metrics.SetAllowance("cryptowatch", allow.Remaining)
+	if len(dataOHLC) == 0 {
+		rec.Status = manifest.StatusEmpty
+		return fail(fmt.Errorf("no data; check the symbol"))
+	}
+	rec.Bars = len(dataOHLC)
+	rec.First = dataOHLC[0].Date.String()
+	rec.Last = dataOHLC[len(dataOHLC)-1].Date.String()
+
+	fname += ".csv"
+	rec.File = fname
+	dataCSV := ohlcio.ToCSV(dataOHLC, spec)
+	rec.Changes, err = saveData(fname, dataCSV)
+	if err != nil {
+		return fail(fmt.Errorf("saveData error: %s", err))
+	}
+	rec.Status = manifest.StatusOK
+
+	return rec, nil
+}
+
+// errAllowance means exhausted API allowance (HTTP 429),
+// see https://docs.cryptowat.ch/rest-api/rate-limit
+var errAllowance = errors.New("API allowance exhausted")
+
+func fetch(spec instrument.Spec, app App) ([]byte, error) {
+	url, err := mkURL(app.Config, spec)
 	if err != nil {
 		return nil, fmt.Errorf("mkUrl failed: %v", err)
 	}
 
+	return get(app, url)
+}
+
+// get fetches API response of url.
+func get(app App, url url.URL) ([]byte, error) {
+	body := []byte{}
 	resp, err := app.client.Get(url.String())
 	if err != nil {
 		return body, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return body, errAllowance
+	}
 	if resp.StatusCode != http.StatusOK {
 		return body, fmt.Errorf("fetch: HTTP Status: %s. URL: %s", resp.Status, url.String())
 	}
@@ -190,9 +280,9 @@ func fetch(ticker string, app App) ([]byte, error) {
 
 // mkURL generates proper API URL
 // see https://cryptowat.ch/docs/api#market-ohlc
-func mkURL(conf Config, ticker string) (url.URL, error) {
+func mkURL(conf Config, spec instrument.Spec) (url.URL, error) {
 	str := fmt.Sprintf("%s/markets/%s/%s/ohlc",
-		conf.Setup.BaseURL, conf.Setup.Exchange, ticker)
+		conf.Setup.BaseURL, exchange(conf, spec), spec.Symbol)
 
 	u, err := url.Parse(str)
 	if err != nil {
@@ -215,33 +305,36 @@ func mkURL(conf Config, ticker string) (url.URL, error) {
 	return *u, nil
 }
 
-func saveData(fpath string, data [][]string) error {
+func saveData(fpath string, data [][]string) (manifest.Changes, error) {
 	output := data
+	changes := manifest.Changes{New: len(data) - 1}
 	err := osutil.FileExists(fpath)
 	if err == nil {
-		dataMerged, err := mergeData(fpath, data)
+		dataMerged, merged, err := mergeData(fpath, data)
 		if err != nil {
-			return fmt.Errorf("mergeData %s failed: %v", fpath, err)
+			return changes, fmt.Errorf("mergeData %s failed: %v", fpath, err)
 		}
 		output = dataMerged
+		changes = merged
 	}
 
 	err = writeData(fpath, output)
 	if err != nil {
-		return fmt.Errorf("writeData %s failed: %v", fpath, err)
+		return changes, fmt.Errorf("writeData %s failed: %v", fpath, err)
 	}
 
-	return nil
+	return changes, nil
 }
 
-func mergeData(fpath string, dataNew [][]string) ([][]string, error) {
+func mergeData(fpath string, dataNew [][]string) ([][]string, manifest.Changes, error) {
 	header := dataNew[0]
 	output := [][]string{header}
 	data := map[string][]string{}
+	changes := manifest.Changes{}
 
 	file, err := os.Open(fpath)
 	if err != nil {
-		return output, fmt.Errorf("open data file error: %v", err)
+		return output, changes, fmt.Errorf("open data file error: %v", err)
 	}
 	defer file.Close()
 
@@ -253,12 +346,19 @@ func mergeData(fpath string, dataNew [][]string) ([][]string, error) {
 		if err == io.EOF {
 			break
 		} else if err != nil {
-			return output, fmt.Errorf("read data file error: %v", err)
+			return output, changes, fmt.Errorf("read data file error: %v", err)
 		}
 		data[record[0]] = record
 	}
 
 	for _, record := range dataNew[1:] { // skip CSV header
+		prev, ok := data[record[0]]
+		switch {
+		case !ok:
+			changes.New++
+		case strings.Join(prev, ";") != strings.Join(record, ";"):
+			changes.Updated++
+		}
 		data[record[0]] = record
 	}
 
@@ -270,7 +370,7 @@ func mergeData(fpath string, dataNew [][]string) ([][]string, error) {
 		func(i, j int) bool { return toSort[i][0] < toSort[j][0] })
 
 	output = append(output, toSort...)
-	return output, nil
+	return output, changes, nil
 }
 
 func writeData(fpath string, data [][]string) error {
@@ -305,41 +405,107 @@ func writeData(fpath string, data [][]string) error {
 	return nil
 }
 
-func loadTickers(app App) ([]string, error) {
-	// use a map for an easy unique ticker collection
-	tmap := make(map[string]string)
-	var tickers []string
+// exchange provides exchange of spec, Setup.Exchange by default.
+func exchange(conf Config, spec instrument.Spec) string {
+	if spec.Exchange != "" {
+		return spec.Exchange
+	}
+	return conf.Setup.Exchange
+}
+
+// label identifies spec in logs and manifest: pair on Setup.Exchange,
+// exchange:pair (Cryptowatch market) on other exchanges.
+func label(conf Config, spec instrument.Spec) string {
+	if e := exchange(conf, spec); e != conf.Setup.Exchange {
+		return e + ":" + spec.Symbol
+	}
+	return spec.Symbol
+}
+
+// dataDir provides output directory of spec: OutputDir for pairs
+// on Setup.Exchange, OutputDir/<exchange> for other exchanges.
+func dataDir(conf Config, spec instrument.Spec) string {
+	if e := exchange(conf, spec); e != conf.Setup.Exchange {
+		return filepath.Join(conf.Setup.OutputDir, e)
+	}
+	return conf.Setup.OutputDir
+}
+
+func loadInstruments(app App) ([]instrument.Spec, error) {
+	funcName := "loadInstruments"
+	output := []instrument.Spec{}
+	// specMap is unique (key: exchange:symbol) instrument collection
+	specMap := make(map[string]instrument.Spec)
 
 	for _, path := range app.Config.Setup.Watchlists {
 		fd, err := os.Open(path)
 		if err != nil {
-			return tickers, fmt.Errorf("open %s error: %s", path, err)
+			return output, fmt.Errorf("open %s error: %s", path, err)
 		}
 		defer fd.Close()
 
-		r := csv.NewReader(fd)
-		r.Comma = ';'
-		data, err := r.ReadAll()
+		specLst, err := readWatchlist(fd)
 		if err != nil {
-			return tickers, fmt.Errorf("csv read %s error: %s", path, err)
+			return output, fmt.Errorf("load from %s error: %s", path, err)
+		}
+		if len(specLst) == 0 {
+			app.log.Warnf("%s: %s is empty", funcName, path)
 		}
 
-		for i, row := range data {
-			if i == 0 {
-				continue // skip header
-			}
-			ticker := strings.TrimSpace(row[0])
-			name := strings.TrimSpace(row[1])
-
-			tmap[ticker] = name
+		for _, s := range specLst {
+			specMap[exchange(app.Config, s)+":"+s.Symbol] = s
 		}
+		app.log.Infof("%s: %s - OK", funcName, path)
+	}
 
-		app.log.Infof("loadTickers: %s - OK", path)
+	for _, spec := range specMap {
+		output = append(output, spec)
 	}
+	sort.Slice(output, func(i, j int) bool { return label(app.Config, output[i]) < label(app.Config, output[j]) })
 
-	for ticker := range tmap {
-		tickers = append(tickers, ticker)
+	return output, nil
+}
+
+// readWatchlist reads instruments from CSV (see instrument.SpecLstFromCSV).
+// Legacy watchlists with columns sym;name only are accepted as crypto.
+func readWatchlist(r io.Reader) ([]instrument.Spec, error) {
+	output := []instrument.Spec{}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return output, err
+	}
+
+	rcsv := csv.NewReader(bytes.NewReader(data))
+	rcsv.Comma = ';'
+	rcsv.FieldsPerRecord = -1
+	header, err := rcsv.Read()
+	if err == io.EOF {
+		return output, nil
+	}
+	if err != nil {
+		return output, fmt.Errorf("CSV read error: %v", err)
+	}
+	if len(header) > 2 {
+		return instrument.SpecLstFromCSV(bytes.NewReader(data))
 	}
 
-	return tickers, nil
+	rows, err := rcsv.ReadAll()
+	if err != nil {
+		return output, fmt.Errorf("CSV read error: %v", err)
+	}
+	for _, row := range rows {
+		spec := instrument.Spec{
+			Symbol:       strings.TrimSpace(row[0]),
+			SecurityType: instrument.Crypto,
+		}
+		if len(row) > 1 {
+			spec.Description = strings.TrimSpace(row[1])
+		}
+		if err := spec.Validate(); err != nil {
+			return output, fmt.Errorf("%s: %v", spec.Symbol, err)
+		}
+		output = append(output, spec)
+	}
+
+	return output, nil
 }
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/profioss/clog"
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/pkg/manifest"
+	"github.com/profioss/trada/pkg/mdtest"
+)
+
+func mkTestApp(t *testing.T, srv *mdtest.Server, dir string, symbols ...string) App {
+	t.Helper()
+
+	logger, err := clog.New(ioutil.Discard, "disabled", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := App{client: srv.Client(), log: logger}
+	app.Config.Setup = Setup{
+		Range:     "3m",
+		BaseURL:   srv.URL,
+		Exchange:  "coinbase-pro",
+		Timeout:   5 * time.Second,
+		MaxProcs:  1,
+		OutputDir: dir,
+	}
+	app.Config.symbols = symbols
+	if err := app.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	return app
+}
+
+func TestGetData(t *testing.T) {
+	srv := mdtest.NewServer("testdata")
+	defer srv.Close()
+	srv.SetMode("ethusd", mdtest.ModeEmpty)
+	srv.SetMode("xrpusd", mdtest.ModeServerError)
+	srv.SetMode("ltcusd", mdtest.ModeMalformed)
+
+	dir, err := ioutil.TempDir("", "get-md-cw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	app := mkTestApp(t, srv, dir, "btcusd", "ethusd", "xrpusd", "ltcusd", "dogeusd")
+	m := manifest.New("get-md-cw", "test", "3m")
+	if err := getData(context.Background(), app, m); err != nil {
+		t.Fatal(err)
+	}
+	m.Finish()
+	statuses := map[string]manifest.Status{}
+	for _, rec := range m.Symbols {
+		statuses[rec.Symbol] = rec.Status
+	}
+
+	tests := []struct {
+		symbol string
+		rows   int // data rows of CSV; 0 means no file expected
+		swp    bool
+		status manifest.Status
+	}{
+		{symbol: "btcusd", rows: 3, status: manifest.StatusOK},
+		{symbol: "ethusd", status: manifest.StatusEmpty},             // empty data
+		{symbol: "xrpusd", status: manifest.StatusFailed},            // 500
+		{symbol: "ltcusd", swp: true, status: manifest.StatusFailed}, // malformed JSON stored for analysis
+		{symbol: "dogeusd", status: manifest.StatusFailed},           // 404 - no fixture
+	}
+
+	for _, tc := range tests {
+		if statuses[tc.symbol] != tc.status {
+			t.Errorf("%s - manifest status should be %q, got %q", tc.symbol, tc.status, statuses[tc.symbol])
+		}
+
+		fname := filepath.Join(dir, tc.symbol+".csv")
+		data, err := ioutil.ReadFile(fname)
+		switch {
+		case tc.rows == 0 && err == nil:
+			t.Errorf("%s - unexpected output file %s", tc.symbol, fname)
+		case tc.rows > 0 && err != nil:
+			t.Errorf("%s - unexpected error: %v", tc.symbol, err)
+		case tc.rows > 0:
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			if len(lines) != tc.rows+1 {
+				t.Errorf("%s - expected %d rows, got %d", tc.symbol, tc.rows, len(lines)-1)
+			}
+			// CW timestamp is end of bar
+			if lines[1] != "2019-10-27;9551.71000000;9794.98000000;9160.00000000;9256.21000000;16040.22000000" {
+				t.Errorf("%s - unexpected first row: %s", tc.symbol, lines[1])
+			}
+		}
+
+		_, err = os.Stat(filepath.Join(dir, tc.symbol+".json.swp"))
+		if tc.swp != (err == nil) {
+			t.Errorf("%s - .json.swp file expected: %v, got error: %v", tc.symbol, tc.swp, err)
+		}
+	}
+}
+
+func TestGetDataAllowance(t *testing.T) {
+	srv := mdtest.NewServer("testdata")
+	defer srv.Close()
+	srv.SetAllowance(2000000, 1000000) // enough for 2 requests
+
+	dir, err := ioutil.TempDir("", "get-md-cw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	app := mkTestApp(t, srv, dir, "btcusd", "ethusd", "ltcusd", "xrpusd")
+	m := manifest.New("get-md-cw", "test", "3m")
+	if err := getData(context.Background(), app, m); err == nil {
+		t.Errorf("allowance exhausted - should have an error")
+	}
+	if m.Count(manifest.StatusOK) != 2 || m.Count(manifest.StatusFailed) != 1 || m.Count(manifest.StatusSkipped) != 1 {
+		t.Errorf("unexpected manifest: %+v", m.Symbols)
+	}
+
+	if n := len(srv.Requests()); n != 3 {
+		t.Errorf("expected 3 requests (the last rejected), got %d: %v", n, srv.Requests())
+	}
+	for _, sym := range []string{"btcusd", "ethusd"} {
+		if _, err := os.Stat(filepath.Join(dir, sym+".csv")); err != nil {
+			t.Errorf("%s - unexpected error: %v", sym, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ltcusd.csv")); err == nil {
+		t.Errorf("ltcusd - unexpected output file")
+	}
+}
+
+func TestLoadInstruments(t *testing.T) {
+	srv := mdtest.NewServer("testdata")
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "get-md-cw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"legacy.csv":  "sym;name\nbtcusd;Bitcoin\nethusd;Ether\n",
+		"current.csv": "sym;name;security;exchange;attrs\nltcusd;Litecoin;crypto;;price-decimals=2\n",
+		"invalid.csv": "sym;name;security\nxrpusd;XRP\n",
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		label    string
+		files    []string
+		expected []string
+		hasErr   bool
+	}{
+		{label: "legacy format", files: []string{"legacy.csv"}, expected: []string{"btcusd", "ethusd"}},
+		{label: "mixed formats", files: []string{"legacy.csv", "current.csv"}, expected: []string{"btcusd", "ethusd", "ltcusd"}},
+		{label: "missing security", files: []string{"invalid.csv"}, hasErr: true},
+	}
+
+	for _, tc := range tests {
+		app := mkTestApp(t, srv, dir, "btcusd")
+		app.Config.Setup.Watchlists = []string{}
+		for _, f := range tc.files {
+			app.Config.Setup.Watchlists = append(app.Config.Setup.Watchlists, filepath.Join(dir, f))
+		}
+
+		specs, err := loadInstruments(app)
+		switch {
+		case tc.hasErr && err == nil:
+			t.Errorf("%s - should have an error", tc.label)
+		case !tc.hasErr && err != nil:
+			t.Errorf("%s - unexpected error: %v", tc.label, err)
+		case tc.hasErr:
+		default:
+			symbols := []string{}
+			for _, s := range specs {
+				if s.SecurityType != instrument.Crypto {
+					t.Errorf("%s - %s: expected crypto, got %s", tc.label, s.Symbol, s.SecurityType)
+				}
+				symbols = append(symbols, s.Symbol)
+			}
+			sort.Strings(symbols)
+			if strings.Join(symbols, ",") != strings.Join(tc.expected, ",") {
+				t.Errorf("%s - expected %v, got %v", tc.label, tc.expected, symbols)
+			}
+		}
+	}
+}
+
+func TestGetDataMultiExchange(t *testing.T) {
+	srv := mdtest.NewServer("testdata")
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "get-md-cw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// watchlist generated by get-cw-markets: btcusd on coinbase-pro and kraken
+	app := mkTestApp(t, srv, dir, "btcusd")
+	app.Config.symbols = []string{}
+	app.Config.Setup.Watchlists = []string{"../get-cw-markets/testdata/watchlist-crypto-usd-all.csv"}
+	app.Config.Setup.TradesLimit = 10
+	m := manifest.New("get-md-cw", "test", "3m")
+	if err := getData(context.Background(), app, m); err != nil {
+		t.Fatal(err)
+	}
+	m.Finish()
+
+	expected := map[string]manifest.Status{
+		"btcusd":        manifest.StatusOK,
+		"ethusd":        manifest.StatusOK,
+		"kraken:btcusd": manifest.StatusOK,
+	}
+	if len(m.Symbols) != len(expected) {
+		t.Errorf("expected %d manifest records, got %+v", len(expected), m.Symbols)
+	}
+	for _, rec := range m.Symbols {
+		if st, ok := expected[rec.Symbol]; !ok || rec.Status != st {
+			t.Errorf("%s - manifest status should be %q, got %q", rec.Symbol, st, rec.Status)
+		}
+	}
+
+	files := map[string]string{ // file: first row
+		filepath.Join(dir, "btcusd.csv"):           "2019-10-27;9551.71000000;9794.98000000;9160.00000000;9256.21000000;16040.22000000",
+		filepath.Join(dir, "kraken", "btcusd.csv"): "2019-10-27;9550.10000000;9790.50000000;9161.20000000;9255.40000000;5120.33000000",
+	}
+	for fname, row := range files {
+		data, err := ioutil.ReadFile(fname)
+		if err != nil {
+			t.Errorf("%s - unexpected error: %v", fname, err)
+			continue
+		}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if len(lines) < 2 || lines[1] != row {
+			t.Errorf("%s - unexpected data:\n%s", fname, data)
+		}
+	}
+
+	requests := strings.Join(srv.Requests(), " ")
+	for _, r := range []string{"/markets/kraken/btcusd/ohlc", "/markets/kraken/btcusd/trades"} {
+		if !strings.Contains(requests, r) {
+			t.Errorf("missing request %s in %s", r, requests)
+		}
+	}
+}
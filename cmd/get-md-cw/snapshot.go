@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/tick"
+	"github.com/profioss/trada/pkg/osutil"
+	"github.com/shopspring/decimal"
+)
+
+// snapshotTimeFormat is format of capture time in snapshot file names.
+const snapshotTimeFormat = "20060102T150405Z"
+
+// respBook is Cryptowatch representation of order book.
+type respBook struct {
+	Result struct {
+		Asks   [][]json.Number `json:"asks"`
+		Bids   [][]json.Number `json:"bids"`
+		SeqNum int64           `json:"seqNum"`
+	} `json:"result"`
+	Allowance allowance `json:"allowance"`
+}
+
+// respTrades is Cryptowatch representation of recent trades:
+// [id, timestamp, price, amount].
+type respTrades struct {
+	Result    [][]json.Number `json:"result"`
+	Allowance allowance       `json:"allowance"`
+}
+
+func parseBook(data []byte, t time.Time, exchange string) (tick.Book, allowance, error) {
+	output := tick.Book{Time: t, Exchange: exchange}
+	resp := respBook{}
+
+	err := json.Unmarshal(data, &resp)
+	if err != nil {
+		return output, resp.Allowance, err
+	}
+	output.Seq = resp.Result.SeqNum
+
+	output.Bids, err = parseLevels(resp.Result.Bids)
+	if err != nil {
+		return output, resp.Allowance, fmt.Errorf("bids: %v", err)
+	}
+	output.Asks, err = parseLevels(resp.Result.Asks)
+	if err != nil {
+		return output, resp.Allowance, fmt.Errorf("asks: %v", err)
+	}
+
+	return output, resp.Allowance, output.Validate()
+}
+
+func parseLevels(data [][]json.Number) ([]tick.Level, error) {
+	output := make([]tick.Level, 0, len(data))
+	for _, row := range data {
+		if len(row) < 2 {
+			return output, fmt.Errorf("unexpected CW level: wanted 2 elements; data: %v", row)
+		}
+		p, err := decimal.NewFromString(row[0].String())
+		if err != nil {
+			return output, fmt.Errorf("invalid price %q; data: %v", row[0].String(), row)
+		}
+		s, err := decimal.NewFromString(row[1].String())
+		if err != nil {
+			return output, fmt.Errorf("invalid size %q; data: %v", row[1].String(), row)
+		}
+		output = append(output, tick.Level{Price: p, Size: s})
+	}
+
+	return output, nil
+}
+
+func parseTrades(data []byte, exchange string) ([]tick.Trade, allowance, error) {
+	output := []tick.Trade{}
+	resp := respTrades{}
+
+	err := json.Unmarshal(data, &resp)
+	if err != nil {
+		return output, resp.Allowance, err
+	}
+
+	for _, row := range resp.Result {
+		if len(row) < 4 {
+			return output, resp.Allowance,
+				fmt.Errorf("unexpected CW trade: wanted 4 elements; data: %v", row)
+		}
+		ts, err := row[1].Int64()
+		if err != nil {
+			return output, resp.Allowance, fmt.Errorf("invalid timestamp %q; data: %v", row[1].String(), row)
+		}
+		t := tick.Trade{Time: time.Unix(ts, 0).UTC(), Exchange: exchange}
+		if t.Price, err = decimal.NewFromString(row[2].String()); err != nil {
+			return output, resp.Allowance, fmt.Errorf("invalid price %q; data: %v", row[2].String(), row)
+		}
+		if t.Size, err = decimal.NewFromString(row[3].String()); err != nil {
+			return output, resp.Allowance, fmt.Errorf("invalid amount %q; data: %v", row[3].String(), row)
+		}
+		if err := t.Validate(); err != nil {
+			return output, resp.Allowance, fmt.Errorf("invalid trade: %v; data: %v", err, row)
+		}
+		output = append(output, t)
+	}
+	sort.SliceStable(output, func(i, j int) bool { return output[i].Time.Before(output[j].Time) })
+
+	return output, resp.Allowance, nil
+}
+
+// getSnapshot fetches and stores order book snapshot and/or recent
+// trades of spec; stored files are returned. Files are stored to
+// OutputDir/snapshots/<pair>/<pair>-<time>-{book,trades}.csv
+// where time is capture time of the response (see dataDir for pairs
+// on other than Setup.Exchange).
+func getSnapshot(app App, spec instrument.Spec) ([]string, error) {
+	output := []string{}
+	sym := spec.Symbol
+	dir := filepath.Join(dataDir(app.Config, spec), "snapshots", sym)
+	fname := func(t time.Time, kind string) string {
+		return filepath.Join(dir, sym+"-"+t.Format(snapshotTimeFormat)+"-"+kind+".csv")
+	}
+
+	if n := app.Config.Setup.BookDepth; n > 0 {
+		u, err := mkSnapshotURL(app.Config, spec, "orderbook", n)
+		if err != nil {
+			return output, fmt.Errorf("mkSnapshotURL failed: %v", err)
+		}
+		data, err := get(app, u)
+		captured := time.Now().UTC()
+		if err == errAllowance {
+			return output, err
+		}
+		if err != nil {
+			return output, fmt.Errorf("orderbook fetch error: %s", err)
+		}
+		book, allow, err := parseBook(data, captured, exchange(app.Config, spec))
+		if err != nil {
+			return output, fmt.Errorf("orderbook parse error: %s", err)
+		}
+		app.metrics.SetAllowance("cryptowatch", allow.Remaining)
+
+		fpath := fname(captured, "book")
+		if err := writeSnapshot(fpath, func(fd *os.File) error { return tick.BookToCSV(fd, book.Top(n)) }); err != nil {
+			return output, fmt.Errorf("orderbook write error: %s", err)
+		}
+		output = append(output, fpath)
+	}
+
+	if n := app.Config.Setup.TradesLimit; n > 0 {
+		u, err := mkSnapshotURL(app.Config, spec, "trades", n)
+		if err != nil {
+			return output, fmt.Errorf("mkSnapshotURL failed: %v", err)
+		}
+		data, err := get(app, u)
+		captured := time.Now().UTC()
+		if err == errAllowance {
+			return output, err
+		}
+		if err != nil {
+			return output, fmt.Errorf("trades fetch error: %s", err)
+		}
+		trades, allow, err := parseTrades(data, exchange(app.Config, spec))
+		if err != nil {
+			return output, fmt.Errorf("trades parse error: %s", err)
+		}
+		app.metrics.SetAllowance("cryptowatch", allow.Remaining)
+
+		fpath := fname(captured, "trades")
+		if err := writeSnapshot(fpath, func(fd *os.File) error { return tick.TradesToCSV(fd, trades) }); err != nil {
+			return output, fmt.Errorf("trades write error: %s", err)
+		}
+		output = append(output, fpath)
+	}
+
+	return output, nil
+}
+
+// mkSnapshotURL generates API URL of orderbook or trades endpoint
+// see https://docs.cryptowat.ch/rest-api/markets
+func mkSnapshotURL(conf Config, spec instrument.Spec, endpoint string, limit int) (url.URL, error) {
+	str := fmt.Sprintf("%s/markets/%s/%s/%s",
+		conf.Setup.BaseURL, exchange(conf, spec), spec.Symbol, endpoint)
+
+	u, err := url.Parse(str)
+	if err != nil {
+		return url.URL{}, fmt.Errorf("invalid URL string: %v", err)
+	}
+
+	q := u.Query()
+	q.Set("limit", fmt.Sprintf("%d", limit))
+	u.RawQuery = q.Encode()
+
+	return *u, nil
+}
+
+// writeSnapshot atomically writes file fpath by write function.
+func writeSnapshot(fpath string, write func(fd *os.File) error) error {
+	err := os.MkdirAll(filepath.Dir(fpath), osutil.DirPerms)
+	if err != nil {
+		return err
+	}
+
+	fdTmp, err := os.Create(fpath + ".swp")
+	if err != nil {
+		return fmt.Errorf("creating temp output file failed: %s", err)
+	}
+	defer os.Remove(fdTmp.Name())
+
+	err = write(fdTmp)
+	if errClose := fdTmp.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(fdTmp.Name(), osutil.FilePerms)
+	if err != nil {
+		return fmt.Errorf("chmod %s %s: %s", osutil.FilePerms.String(), fdTmp.Name(), err)
+	}
+	err = os.Rename(fdTmp.Name(), fpath)
+	if err != nil {
+		return fmt.Errorf("rename %s -> %s: %s", fdTmp.Name(), fpath, err)
+	}
+
+	return nil
+}
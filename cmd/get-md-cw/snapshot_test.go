@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/tick"
+	"github.com/profioss/trada/pkg/manifest"
+	"github.com/profioss/trada/pkg/mdtest"
+)
+
+func TestGetSnapshot(t *testing.T) {
+	srv := mdtest.NewServer("testdata")
+	defer srv.Close()
+	srv.SetMode("ltcusd", mdtest.ModeMalformed)
+
+	dir, err := ioutil.TempDir("", "get-md-cw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	app := mkTestApp(t, srv, dir, "btcusd")
+	app.Config.Setup.BookDepth = 2
+	app.Config.Setup.TradesLimit = 100
+	before := time.Now().UTC()
+
+	files, err := getSnapshot(app, instrument.Spec{Symbol: "btcusd", SecurityType: instrument.Crypto})
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := time.Now().UTC()
+	if len(files) != 2 || !strings.HasSuffix(files[0], "-book.csv") || !strings.HasSuffix(files[1], "-trades.csv") {
+		t.Fatalf("unexpected files: %v", files)
+	}
+	for _, f := range files {
+		name := strings.TrimPrefix(filepath.Base(f), "btcusd-")
+		captured, err := time.Parse(snapshotTimeFormat, name[:len(snapshotTimeFormat)])
+		switch {
+		case filepath.Dir(f) != filepath.Join(dir, "snapshots", "btcusd"):
+			t.Errorf("unexpected directory of %s", f)
+		case err != nil:
+			t.Errorf("%s - invalid capture time: %v", f, err)
+		case captured.Before(before.Truncate(time.Second)) || captured.After(after):
+			t.Errorf("%s - capture time should be between %s and %s", f, before, after)
+		}
+	}
+
+	fd, err := os.Open(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	book, err := tick.BookFromCSV(fd)
+	q := book.Quote()
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case len(book.Bids) != 2 || len(book.Asks) != 2 || book.Seq != 123456 ||
+		book.Time.Before(before) || book.Time.After(after):
+		t.Errorf("unexpected book: %+v", book)
+	case q.Spread().String() != "0.01" || book.Exchange != "coinbase-pro":
+		t.Errorf("unexpected quote: %+v", q)
+	}
+
+	fd, err = os.Open(files[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	trades, err := tick.TradesFromCSV(fd)
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case len(trades) != 3 || trades[2].Size.String() != "0.125" || trades[0].Time.Unix() != 1572480000:
+		t.Errorf("unexpected trades: %+v", trades)
+	}
+
+	for _, sym := range []string{"ltcusd", "dogeusd"} {
+		if _, err := getSnapshot(app, instrument.Spec{Symbol: sym, SecurityType: instrument.Crypto}); err == nil {
+			t.Errorf("%s - should have an error", sym)
+		}
+	}
+
+	reqs := srv.Requests()
+	if len(reqs) == 0 || reqs[0] != "/markets/coinbase-pro/btcusd/orderbook" {
+		t.Errorf("unexpected requests: %v", reqs)
+	}
+}
+
+func TestGetDataSnapshot(t *testing.T) {
+	srv := mdtest.NewServer("testdata")
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "get-md-cw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	app := mkTestApp(t, srv, dir, "btcusd", "ethusd")
+	app.Config.Setup.TradesLimit = 10
+	m := manifest.New("get-md-cw", "test", "3m")
+	if err := getData(context.Background(), app, m); err != nil {
+		t.Fatal(err)
+	}
+
+	lst, _ := filepath.Glob(filepath.Join(dir, "snapshots", "*", "*-trades.csv"))
+	if len(lst) != 1 {
+		t.Errorf("expected trades of btcusd only (no ethusd fixture), got %v", lst)
+	}
+	m.Finish()
+	if m.Count(manifest.StatusOK) != 2 || len(m.Symbols) != 2 {
+		t.Errorf("snapshots should not change data status: %+v", m.Symbols)
+	}
+	for i, st := range []manifest.Status{manifest.StatusOK, manifest.StatusFailed} { // btcusd, ethusd
+		if rec := m.Symbols[i]; rec.Snapshot != st || (st == manifest.StatusFailed) != (rec.SnapshotError != "") {
+			t.Errorf("%s - snapshot status should be %q, got %q (%s)", rec.Symbol, st, rec.Snapshot, rec.SnapshotError)
+		}
+	}
+}
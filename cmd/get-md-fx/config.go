@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/profioss/trada/model/instrument"
+
+	toml "github.com/pelletier/go-toml"
+)
+
+// supported rate providers
+const (
+	providerECB = "ecb" // ECB euro foreign exchange reference rates XML
+	providerCSV = "csv" // CSV file per currency pair
+)
+
+// Config is main configuration.
+type Config struct {
+	Setup Setup
+
+	// cmd line flag, not part of the config file
+	symbols []string
+	verbose bool
+}
+
+// Validate checks if Config is valid.
+func (c Config) Validate() error {
+	switch {
+	case c.Setup.Validate() != nil:
+		return fmt.Errorf("Config: %s", c.Setup.Validate())
+
+	case len(c.Setup.Watchlists) == 0 && len(c.symbols) == 0:
+		return errors.New("Setup: empty Watchlists definition nor -s flag used")
+	}
+
+	return nil
+}
+
+// Setup defines command setup.
+type Setup struct {
+	Provider    string
+	URL         string
+	CrossVia    []string
+	Timeout     time.Duration
+	LogFile     string
+	LogLevel    string
+	OutputDir   string
+	MetricsAddr string
+	MetricsFile string
+	Watchlists  []string
+}
+
+// Validate checks if Setup is valid.
+func (s Setup) Validate() error {
+	switch {
+	case s.Provider != providerECB && s.Provider != providerCSV:
+		return fmt.Errorf("Setup: Provider %q is not valid; use one of: %s, %s",
+			s.Provider, providerECB, providerCSV)
+
+	case s.URL == "":
+		return errors.New("Setup: URL is not specified")
+
+	case s.Provider == providerCSV && !strings.Contains(s.URL, "{symbol}") &&
+		!(strings.Contains(s.URL, "{base}") && strings.Contains(s.URL, "{quote}")):
+		return errors.New("Setup: URL of csv provider needs {symbol} or {base} and {quote} placeholders")
+
+	case s.OutputDir == "":
+		return errors.New("Setup: Output Directory is not specified")
+
+	case s.Timeout < 1:
+		return errors.New("Setup: Timeout is set too low")
+	}
+
+	for _, c := range s.CrossVia {
+		if instrument.ValidateCurrency(c) != nil {
+			return fmt.Errorf("Setup: CrossVia: %v", instrument.ValidateCurrency(c))
+		}
+	}
+
+	return nil
+}
+
+func initConfig() (Config, error) {
+	optConf := flag.String("c", "config/get-md-fx.toml", "config file")
+	optLogLevel := flag.String("log-level", "", "log levels: disabled | error | warning | info | debug")
+	optDirOut := flag.String("o", "", "output data directory")
+	optSymbols := flag.String("s", "", "currency pairs delimited by comma (ex: EURUSD,EURJPY,GBPUSD)")
+	optTimeout := flag.Uint("t", 0, "request timeout in seconds")
+	optVerb := flag.Bool("v", false, "verbose mode")
+	flag.Parse()
+
+	var conf Config
+	fd, err := os.Open(*optConf)
+	if err != nil {
+		return conf, err
+	}
+	defer fd.Close()
+
+	err = toml.NewDecoder(fd).Decode(&conf)
+	if err != nil {
+		return conf, err
+	}
+
+	conf.verbose = *optVerb
+
+	conf.Setup.Timeout = time.Duration(conf.Setup.Timeout) * time.Second
+	//
+	// override setup from config by cmdline args
+	if *optTimeout > 0 {
+		conf.Setup.Timeout = time.Duration(*optTimeout) * time.Second
+	}
+	if *optDirOut != "" {
+		conf.Setup.OutputDir = *optDirOut
+	}
+
+	conf.Setup.Provider = strings.ToLower(strings.TrimSpace(conf.Setup.Provider))
+	if len(conf.Setup.CrossVia) == 0 {
+		conf.Setup.CrossVia = []string{"USD", "EUR"}
+	}
+	for i := range conf.Setup.CrossVia {
+		conf.Setup.CrossVia[i] = strings.ToUpper(strings.TrimSpace(conf.Setup.CrossVia[i]))
+	}
+
+	conf.symbols = []string{}
+	if *optSymbols != "" {
+		conf.symbols = strings.Split(*optSymbols, ",")
+	}
+
+	// default log level
+	// log levels: disabled | error | warning | info | debug
+	if conf.Setup.LogLevel == "" {
+		conf.Setup.LogLevel = "info"
+	}
+	if *optLogLevel != "" {
+		conf.Setup.LogLevel = *optLogLevel
+	}
+	// disable logging if no log file was specified
+	if conf.Setup.LogFile == "" {
+		conf.Setup.LogLevel = "disabled"
+	}
+
+	return conf, conf.Validate()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/profioss/trada/pkg/manifest"
+	"github.com/profioss/trada/pkg/osutil"
+)
+
+func main() {
+	exitCode := 0
+	wg := sync.WaitGroup{}
+
+	app, err := newApp()
+	if err != nil {
+		log.Fatal("App init error: ", err)
+	}
+	defer app.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGTERM, os.Interrupt)
+
+	// common cleanup
+	defer func() {
+		signal.Stop(sigChan)
+		cancel()
+		cleanup(app)
+		wg.Wait()
+		os.Exit(exitCode)
+	}()
+
+	wg.Add(1)
+	go func() {
+		select {
+		case s := <-sigChan:
+			app.log.Warnf("Got %s signal - exitting", s)
+			cancel()
+			app.log.Info("Stopped")
+		case <-ctx.Done():
+			app.log.Info("DONE")
+		}
+		wg.Done()
+	}()
+
+	err = os.MkdirAll(app.Setup.OutputDir, osutil.DirPerms)
+	if err != nil {
+		exitCode = 1
+		app.log.Errorf("Prepare OutputDir error: %s", err)
+		return
+	}
+
+	app.log.Info("Starting")
+	err = do(ctx, app)
+	if err != nil {
+		exitCode = 1
+		app.log.Errorf("Get data error: %s", err)
+		return
+	}
+}
+
+func do(ctx context.Context, app App) error {
+	if app.Setup.MetricsAddr != "" {
+		stop, err := app.metrics.Serve(app.Setup.MetricsAddr)
+		if err != nil {
+			return fmt.Errorf("metrics server error: %v", err)
+		}
+		defer stop()
+		app.log.Infof("Serving metrics at http://%s/metrics", app.Setup.MetricsAddr)
+	}
+
+	m := manifest.New("get-md-fx", app.Config.Setup.Provider, "")
+	err := getData(ctx, app, m)
+	m.Finish()
+	app.metrics.Manifest(m)
+
+	fpath, errSave := m.Save(app.Setup.OutputDir)
+	if errSave != nil {
+		app.log.Errorf("Save manifest error: %s", errSave)
+	} else {
+		app.log.Infof("Manifest saved to %s", fpath)
+	}
+	if app.Setup.MetricsFile != "" {
+		if errMetrics := app.metrics.Registry.WriteFile(app.Setup.MetricsFile); errMetrics != nil {
+			app.log.Errorf("Write metrics error: %s", errMetrics)
+		} else {
+			app.log.Infof("Metrics written to %s", app.Setup.MetricsFile)
+		}
+	}
+	m.PrintSummary(os.Stdout)
+
+	return err
+}
+
+func cleanup(app App) {
+	app.log.Info("Cleaning up...")
+}
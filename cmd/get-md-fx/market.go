@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/profioss/trada/model/forex"
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/ohlc/ohlcio"
+	"github.com/profioss/trada/pkg/manifest"
+	"github.com/profioss/trada/pkg/osutil"
+)
+
+func getData(ctx context.Context, app App, m *manifest.Manifest) error {
+	specs, err := loadPairs(app)
+	if err != nil {
+		return fmt.Errorf("loadPairs failed %s", err)
+	}
+
+	prov, err := newProvider(app)
+	if err != nil {
+		return err
+	}
+
+	pairs := make([]instrument.ForexSpec, 0, len(specs))
+	for _, s := range specs {
+		pairs = append(pairs, *s.Forex)
+	}
+	rates, err := prov.rates(ctx, pairs)
+	if err != nil {
+		for _, s := range specs {
+			m.Skip("rates not available", s.Symbol)
+		}
+		return fmt.Errorf("%s rates error: %s", app.Config.Setup.Provider, err)
+	}
+
+	failed := 0
+	for _, spec := range specs {
+		rec, err := store(app, rates, spec)
+		m.Add(rec)
+		if err != nil {
+			if rec.Status == manifest.StatusFailed {
+				failed++
+			}
+			app.log.Errorf("%s: %s", spec.Symbol, err)
+			continue
+		}
+		app.log.Debugf("%s: saved to %s", spec.Symbol, rec.File)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d pairs failed", failed, len(specs))
+	}
+
+	return nil
+}
+
+// store derives rates of pair spec and stores them,
+// returned record describes the outcome for run manifest.
+func store(app App, rates forex.Rates, spec instrument.Spec) (manifest.Symbol, error) {
+	rec := manifest.Symbol{Symbol: spec.Symbol, Status: manifest.StatusFailed}
+	fail := func(err error) (manifest.Symbol, error) {
+		rec.Error = err.Error()
+		return rec, err
+	}
+
+	bars, err := rates.Pair(*spec.Forex, app.Config.Setup.CrossVia)
+	if err != nil {
+		return fail(err)
+	}
+	if len(bars) == 0 {
+		rec.Status = manifest.StatusEmpty
+		return fail(fmt.Errorf("no data"))
+	}
+	rec.Bars = len(bars)
+	rec.First = bars[0].Date.String()
+	rec.Last = bars[len(bars)-1].Date.String()
+
+	rec.File = filepath.Join(app.Config.Setup.OutputDir, spec.Symbol+".csv")
+	dataCSV := ohlcio.ToCSV(bars, spec)
+	rec.Changes, err = saveData(rec.File, dataCSV)
+	if err != nil {
+		return fail(fmt.Errorf("saveData error: %s", err))
+	}
+	rec.Status = manifest.StatusOK
+
+	return rec, nil
+}
+
+// fetch reads data from HTTP(S) URL or from local file
+// (path or file:// URL).
+func fetch(ctx context.Context, app App, u string) ([]byte, error) {
+	if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		return ioutil.ReadFile(strings.TrimPrefix(u, "file://"))
+	}
+
+	body := []byte{}
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return body, err
+	}
+
+	resp, err := app.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return body, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return body, fmt.Errorf("fetch: HTTP Status: %s. URL: %s", resp.Status, u)
+	}
+
+	body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return body, fmt.Errorf("fetch: Read Error: %s", err)
+	}
+
+	return body, nil
+}
+
+func saveData(fpath string, data [][]string) (manifest.Changes, error) {
+	output := data
+	changes := manifest.Changes{New: len(data) - 1}
+	err := osutil.FileExists(fpath)
+	if err == nil {
+		dataMerged, merged, err := mergeData(fpath, data)
+		if err != nil {
+			return changes, fmt.Errorf("mergeData %s failed: %v", fpath, err)
+		}
+		output = dataMerged
+		changes = merged
+	}
+
+	err = writeData(fpath, output)
+	if err != nil {
+		return changes, fmt.Errorf("writeData %s failed: %v", fpath, err)
+	}
+
+	return changes, nil
+}
+
+func mergeData(fpath string, dataNew [][]string) ([][]string, manifest.Changes, error) {
+	header := dataNew[0]
+	output := [][]string{header}
+	data := map[string][]string{}
+	changes := manifest.Changes{}
+
+	file, err := os.Open(fpath)
+	if err != nil {
+		return output, changes, fmt.Errorf("open data file error: %v", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comma = ';'
+	_, _ = reader.Read() // read CSV header
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return output, changes, fmt.Errorf("read data file error: %v", err)
+		}
+		data[record[0]] = record
+	}
+
+	for _, record := range dataNew[1:] { // skip CSV header
+		prev, ok := data[record[0]]
+		switch {
+		case !ok:
+			changes.New++
+		case strings.Join(prev, ";") != strings.Join(record, ";"):
+			changes.Updated++
+		}
+		data[record[0]] = record
+	}
+
+	toSort := [][]string{}
+	for _, record := range data {
+		toSort = append(toSort, record)
+	}
+	sort.Slice(toSort,
+		func(i, j int) bool { return toSort[i][0] < toSort[j][0] })
+
+	output = append(output, toSort...)
+	return output, changes, nil
+}
+
+func writeData(fpath string, data [][]string) error {
+	dirname := filepath.Dir(fpath)
+	err := os.MkdirAll(dirname, osutil.DirPerms)
+	if err != nil {
+		return err
+	}
+
+	fdTmp, err := os.Create(fpath + ".swp")
+	if err != nil {
+		return fmt.Errorf("creating temp output file failed: %s", err)
+	}
+	defer os.Remove(fdTmp.Name())
+
+	w := csv.NewWriter(fdTmp)
+	w.Comma = ';'
+	w.WriteAll(data)
+	if w.Error() != nil {
+		return fmt.Errorf("CSV temp file error: %s", w.Error())
+	}
+
+	err = os.Chmod(fdTmp.Name(), osutil.FilePerms)
+	if err != nil {
+		return fmt.Errorf("chmod %s %s: %s", osutil.FilePerms.String(), fdTmp.Name(), err)
+	}
+	err = os.Rename(fdTmp.Name(), fpath)
+	if err != nil {
+		return fmt.Errorf("rename %s -> %s: %s", fdTmp.Name(), fpath, err)
+	}
+
+	return nil
+}
+
+// loadPairs loads currency pairs specified by -s flag or by watchlists.
+// Pairs specified by -s flag use watchlist specs (e.g. precision overrides)
+// when the pair is in a watchlist, defaults otherwise.
+func loadPairs(app App) ([]instrument.Spec, error) {
+	output := []instrument.Spec{}
+
+	specMap, err := loadWatchlists(app)
+	if err != nil {
+		return output, err
+	}
+
+	if len(app.Config.symbols) == 0 { // no symbol specified by command line param
+		for _, spec := range specMap {
+			output = append(output, spec)
+		}
+		sort.Slice(output, func(i, j int) bool { return output[i].Symbol < output[j].Symbol })
+		return output, nil
+	}
+
+	for _, sym := range app.Config.symbols { // specified by command line param
+		spec, err := instrument.ParseSpec(strings.TrimSpace(sym), instrument.Forex)
+		if err != nil {
+			return output, err
+		}
+		if s, ok := specMap[spec.Forex.Symbol()]; ok {
+			output = append(output, s)
+			continue
+		}
+		spec.Symbol = spec.Forex.Symbol()
+		output = append(output, spec)
+	}
+
+	return output, nil
+}
+
+// loadWatchlists loads currency pairs from watchlists (key: pair symbol).
+// Watchlist instruments of other security types are skipped.
+func loadWatchlists(app App) (map[string]instrument.Spec, error) {
+	funcName := "loadWatchlists"
+	// specMap is unique (key: symbol) instrument collection
+	specMap := make(map[string]instrument.Spec)
+
+	for _, path := range app.Config.Setup.Watchlists {
+		fd, err := os.Open(path)
+		if err != nil {
+			return specMap, fmt.Errorf("open %s error: %s", path, err)
+		}
+		defer fd.Close()
+
+		specLst, err := instrument.SpecLstFromCSV(fd)
+		if err != nil {
+			return specMap, fmt.Errorf("load from %s error: %s", path, err)
+		}
+
+		for _, s := range specLst {
+			if s.Forex == nil {
+				app.log.Warnf("%s: %s: %s is not %s - skipped", funcName, path, s.Symbol, instrument.Forex)
+				continue
+			}
+			s.Symbol = s.Forex.Symbol() // e.g. EUR/USD -> EURUSD for file names
+			specMap[s.Symbol] = s
+		}
+		app.log.Infof("%s: %s - OK", funcName, path)
+	}
+
+	return specMap, nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/profioss/clog"
+)
+
+func TestLoadPairs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "get-md-fx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	watchlist := filepath.Join(dir, "watchlist-fx.csv")
+	data := "sym;name;security;exchange;attrs\n" +
+		"EURUSD;Euro / US Dollar;forex;;price-decimals=5\n" +
+		"USDJPY;US Dollar / Yen;forex;;price-decimals=3\n" +
+		"GBP/USD;Pound / US Dollar;forex;;price-decimals=4\n"
+	if err := ioutil.WriteFile(watchlist, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	logger, err := clog.New(ioutil.Discard, "disabled", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		label    string
+		symbols  []string
+		expected map[string]int // symbol: price decimals
+	}{
+		{
+			label:    "watchlist",
+			expected: map[string]int{"EURUSD": 5, "USDJPY": 3, "GBPUSD": 4},
+		},
+		{
+			label:    "symbols in watchlist",
+			symbols:  []string{"usdjpy"},
+			expected: map[string]int{"USDJPY": 3},
+		},
+		{
+			label:    "symbols not in watchlist",
+			symbols:  []string{"EURUSD", "AUDUSD"},
+			expected: map[string]int{"EURUSD": 5, "AUDUSD": 0},
+		},
+	}
+
+	for _, tc := range tests {
+		app := App{log: logger}
+		app.Config.Setup.Watchlists = []string{watchlist}
+		app.Config.symbols = tc.symbols
+
+		specs, err := loadPairs(app)
+		if err != nil {
+			t.Errorf("%s - unexpected error: %v", tc.label, err)
+			continue
+		}
+		if len(specs) != len(tc.expected) {
+			t.Errorf("%s - expected %d pairs, got %d", tc.label, len(tc.expected), len(specs))
+		}
+		for _, s := range specs {
+			decimals, ok := tc.expected[s.Symbol]
+			switch {
+			case !ok:
+				t.Errorf("%s - unexpected pair %s", tc.label, s.Symbol)
+			case s.PriceDecimals != decimals:
+				t.Errorf("%s - %s: price decimals should be %d, got %d", tc.label, s.Symbol, decimals, s.PriceDecimals)
+			}
+		}
+	}
+}
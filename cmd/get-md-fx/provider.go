@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/profioss/trada/model/forex"
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/model/ohlc/ohlcio"
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+// provider fetches rates needed to get requested currency pairs.
+type provider interface {
+	rates(ctx context.Context, pairs []instrument.ForexSpec) (forex.Rates, error)
+}
+
+func newProvider(app App) (provider, error) {
+	switch app.Config.Setup.Provider {
+	case providerECB:
+		return ecbProvider{app: app}, nil
+	case providerCSV:
+		return csvProvider{app: app}, nil
+	}
+	return nil, fmt.Errorf("unknown provider %q", app.Config.Setup.Provider)
+}
+
+// ecbProvider reads ECB euro foreign exchange reference rates XML
+// e.g. https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist-90d.xml
+// All rates are EUR based (EURUSD, EURJPY, ...), other pairs are derived.
+type ecbProvider struct {
+	app App
+}
+
+func (p ecbProvider) rates(ctx context.Context, pairs []instrument.ForexSpec) (forex.Rates, error) {
+	data, err := fetch(ctx, p.app, p.app.Config.Setup.URL)
+	if err != nil {
+		return nil, fmt.Errorf("fetch error: %s", err)
+	}
+	p.app.log.Debugf("ecb: fetch - OK")
+
+	return parseECB(data)
+}
+
+// ecbEnvelope is ECB representation of reference rates.
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// parseECB parses ECB reference rates into daily bars of EUR based pairs.
+// Reference rate is single daily value so Open, High, Low and Close
+// are equal and Volume is zero.
+func parseECB(data []byte) (forex.Rates, error) {
+	output := forex.Rates{}
+	env := ecbEnvelope{}
+
+	err := xml.NewDecoder(bytes.NewReader(data)).Decode(&env)
+	if err != nil {
+		return output, fmt.Errorf("parse: %v", err)
+	}
+	if len(env.Days) == 0 {
+		return output, fmt.Errorf("parse: no rates found")
+	}
+
+	for _, day := range env.Days {
+		d, err := typedef.DateFromStr(day.Time)
+		if err != nil {
+			return output, fmt.Errorf("parse: invalid time %q: %v", day.Time, err)
+		}
+		for _, r := range day.Rates {
+			rate, err := decimal.NewFromString(r.Rate)
+			if err != nil {
+				return output, fmt.Errorf("parse: %s %s: invalid rate %q: %v", day.Time, r.Currency, r.Rate, err)
+			}
+			pair := instrument.ForexSpec{Base: "EUR", Quote: strings.ToUpper(r.Currency)}
+			if pair.Validate() != nil {
+				return output, fmt.Errorf("parse: %s: %v", day.Time, pair.Validate())
+			}
+			bar := ohlc.OHLC{Date: d, Open: rate, High: rate, Low: rate, Close: rate}
+			output[pair.Symbol()] = append(output[pair.Symbol()], bar)
+		}
+	}
+
+	// ECB lists the latest rates first
+	for _, bars := range output {
+		sort.Slice(bars, func(i, j int) bool {
+			return bars[i].Date.Time().Before(bars[j].Date.Time())
+		})
+	}
+
+	return output, nil
+}
+
+// csvProvider reads CSV file per currency pair, URL is a template
+// with {symbol}, {base} and {quote} placeholders e.g.
+// https://example.com/fx/{symbol}.csv or var/data/fx-raw/{base}-{quote}.csv
+// See ohlcio.FromCSV for supported CSV format.
+// Pairs which are not available are derived from inverse pairs
+// or legs of cross rates via Setup.CrossVia currencies.
+type csvProvider struct {
+	app App
+}
+
+func (p csvProvider) rates(ctx context.Context, pairs []instrument.ForexSpec) (forex.Rates, error) {
+	output := forex.Rates{}
+	tried := map[string]bool{}
+
+	// get fetches pair or its inverse; reports if any of them is available
+	get := func(pair instrument.ForexSpec) bool {
+		for _, fp := range []instrument.ForexSpec{pair, pair.Inverse()} {
+			sym := fp.Symbol()
+			if len(output[sym]) > 0 {
+				return true
+			}
+			if tried[sym] {
+				continue
+			}
+			tried[sym] = true
+
+			bars, err := p.fetchPair(ctx, fp)
+			if err != nil {
+				p.app.log.Debugf("%s: %s", sym, err)
+				continue
+			}
+			p.app.log.Debugf("%s: fetch - OK", sym)
+			output[sym] = bars
+			return true
+		}
+		return false
+	}
+
+	for _, pair := range pairs {
+		select {
+		case <-ctx.Done():
+			return output, fmt.Errorf("operation cancelled")
+		default:
+		}
+
+		if get(pair) {
+			continue
+		}
+		for _, c := range p.app.Config.Setup.CrossVia {
+			if c == pair.Base || c == pair.Quote {
+				continue
+			}
+			if get(instrument.ForexSpec{Base: pair.Base, Quote: c}) &&
+				get(instrument.ForexSpec{Base: c, Quote: pair.Quote}) {
+				break
+			}
+		}
+	}
+
+	return output, nil
+}
+
+func (p csvProvider) fetchPair(ctx context.Context, pair instrument.ForexSpec) ([]ohlc.OHLC, error) {
+	u := mkURL(p.app.Config.Setup.URL, pair)
+	data, err := fetch(ctx, p.app, u)
+	if err != nil {
+		return nil, fmt.Errorf("fetch error: %s", err)
+	}
+
+	bars, err := ohlcio.FromCSV(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse %s error: %s", u, err)
+	}
+
+	return bars, nil
+}
+
+// mkURL fills URL template placeholders by currency pair.
+func mkURL(tmpl string, pair instrument.ForexSpec) string {
+	return strings.NewReplacer(
+		"{symbol}", pair.Symbol(),
+		"{base}", pair.Base,
+		"{quote}", pair.Quote,
+	).Replace(tmpl)
+}
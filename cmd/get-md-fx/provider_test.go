@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/profioss/trada/model/instrument"
+)
+
+const ecbSample = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender>
+		<gesmes:name>European Central Bank</gesmes:name>
+	</gesmes:Sender>
+	<Cube>
+		<Cube time="2019-10-30">
+			<Cube currency="USD" rate="1.1134"/>
+			<Cube currency="JPY" rate="121.45"/>
+		</Cube>
+		<Cube time="2019-10-29">
+			<Cube currency="USD" rate="1.1105"/>
+			<Cube currency="JPY" rate="121.06"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestParseECB(t *testing.T) {
+	rates, err := parseECB([]byte(ecbSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rates["EURUSD"]) != 2 || len(rates["EURJPY"]) != 2 ||
+		rates["EURUSD"][0].Date.String() != "2019-10-29" {
+		t.Fatalf("unexpected rates: %v", rates)
+	}
+
+	usdjpy, err := rates.Pair(instrument.ForexSpec{Base: "USD", Quote: "JPY"}, []string{"EUR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usdjpy) != 2 || usdjpy[1].Close.StringFixed(3) != "109.080" {
+		t.Errorf("unexpected USDJPY cross rate: %v", usdjpy)
+	}
+
+	for _, input := range []string{"", "<Envelope/>", `<Envelope><Cube><Cube time="x"/></Cube></Envelope>`} {
+		if _, err := parseECB([]byte(input)); err == nil {
+			t.Errorf("%q - should have an error", input)
+		}
+	}
+}
+
+func TestMkURL(t *testing.T) {
+	pair := instrument.ForexSpec{Base: "EUR", Quote: "USD"}
+	tests := []struct {
+		tmpl string
+		out  string
+	}{
+		{tmpl: "https://example.com/fx/{symbol}.csv", out: "https://example.com/fx/EURUSD.csv"},
+		{tmpl: "var/data/fx-raw/{base}-{quote}.csv", out: "var/data/fx-raw/EUR-USD.csv"},
+	}
+
+	for _, tc := range tests {
+		if u := mkURL(tc.tmpl, pair); u != tc.out {
+			t.Errorf("%s - expected %s, got %s", tc.tmpl, tc.out, u)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/profioss/clog"
+	"github.com/profioss/trada/pkg/metrics"
 )
 
 // App defines application.
@@ -15,6 +16,7 @@ type App struct {
 	client  *http.Client
 	log     clog.Logger
 	logFile *os.File
+	metrics *metrics.Fetcher
 }
 
 // Close finishes App by closing open resources.
@@ -49,7 +51,9 @@ func newApp() (App, error) {
 	}
 	app.Config = conf
 
+	app.metrics = metrics.NewFetcher("get-md-iex")
 	app.client = mkClient(conf)
+	app.client.Transport = app.metrics.Transport(app.client.Transport)
 
 	logf, err := clog.OpenFile(conf.Setup.LogFile)
 	if err != nil {
@@ -60,6 +64,12 @@ func newApp() (App, error) {
 		return app, err
 	}
 	app.log = logger
+	if conf.Setup.MetricsFile != "" {
+		// failed run keeps time of the last success
+		if err := app.metrics.RestoreSuccess(conf.Setup.MetricsFile); err != nil {
+			app.log.Warnf("metrics: %s", err)
+		}
+	}
 
 	return app, app.Validate()
 }
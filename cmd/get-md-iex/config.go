@@ -68,15 +68,17 @@ func (c Config) Validate() error {
 
 // Setup defines command setup.
 type Setup struct {
-	Range      dataRange
-	BaseURL    string
-	Token      string
-	Timeout    time.Duration
-	MaxProcs   int
-	LogFile    string
-	LogLevel   string
-	OutputDir  string
-	Watchlists []string
+	Range       dataRange
+	BaseURL     string
+	Token       string
+	Timeout     time.Duration
+	MaxProcs    int
+	LogFile     string
+	LogLevel    string
+	OutputDir   string
+	MetricsAddr string
+	MetricsFile string
+	Watchlists  []string
 }
 
 // Validate checks if Setup is valid.
@@ -106,7 +108,7 @@ func initConfig() (Config, error) {
 	optLogLevel := flag.String("log-level", "", "log levels: disabled | error | warning | info | debug")
 	optDirOut := flag.String("o", "", "output data directory")
 	optRange := flag.String("r", "", "data range, use one of: "+rangeLstStr(validRange))
-	optSymbols := flag.String("s", "", "symbols delimited by comma (ex: SPY,QQQ,DIA:equity) with possible security type")
+	optSymbols := flag.String("s", "", "symbols delimited by comma (ex: SPY,QQQ,DIA:equity,ESZ19:future) with possible security type")
 	optTimeout := flag.Uint("t", 0, "request timeout in seconds")
 	// optTstData := flag.Bool("update-test-data", false, "update test data - use with -o testdata")
 	optVerb := flag.Bool("v", false, "verbose mode")
@@ -170,28 +172,29 @@ func initConfig() (Config, error) {
 // or with specified security type: SPY:equity,DIA:equity
 // arg can be mix of these two definitions.
 // if no security type is specified equity is used.
+// futures and options use CME and OCC style symbols
+// e.g. ESZ19:future,AAPL191220C00150000:option
 func parseSymbols(str string) ([]instrument.Spec, error) {
 	output := []instrument.Spec{}
 	symLst := strings.Split(str, ",")
 
 	for _, s := range symLst {
-		spec := instrument.Spec{
-			Symbol:       s,
-			SecurityType: instrument.Equity, // default
-		}
+		sym := s
+		sec := instrument.Equity // default
 
 		if strings.ContainsRune(s, ':') {
 			symSec := strings.Split(s, ":")
-			spec.Symbol = symSec[0]
-			sec, err := instrument.SecurityFromString(symSec[1])
+			sym = symSec[0]
+			secParsed, err := instrument.SecurityFromString(symSec[1])
 			if err != nil {
 				return output, fmt.Errorf("symbol %q of invalid security type %q", symSec[0], symSec[1])
 			}
-			spec.SecurityType = sec
+			sec = secParsed
 		}
 
-		if spec.Validate() != nil {
-			return output, fmt.Errorf("invalid symbol %q: %v", s, spec.Validate())
+		spec, err := instrument.ParseSpec(sym, sec)
+		if err != nil {
+			return output, fmt.Errorf("invalid symbol %q: %v", s, err)
 		}
 		output = append(output, spec)
 	}
@@ -12,14 +12,16 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/profioss/trada/model/instrument"
 	"github.com/profioss/trada/model/ohlc"
 	"github.com/profioss/trada/model/ohlc/ohlcio"
+	"github.com/profioss/trada/pkg/manifest"
 	"github.com/profioss/trada/pkg/osutil"
 )
 
-func work(ctx context.Context, app App) error {
+func work(ctx context.Context, app App, m *manifest.Manifest) error {
 	var err error
 	instruments := app.Config.instrSpecs // specified by command line param
 	if len(instruments) == 0 {           // no symbol specified by command line param
@@ -37,34 +39,46 @@ func work(ctx context.Context, app App) error {
 		app.Config.Setup.MaxProcs = len(instruments)
 	}
 
-	for _, spec := range instruments {
+	for i, spec := range instruments {
 		select {
 		case <-ctx.Done():
+			for _, s := range instruments[i:] {
+				m.Skip("operation cancelled", s.Symbol)
+			}
 			return fmt.Errorf("operation cancelled")
 		default:
 		}
-		fname, err := getNstore(ctx, app, spec)
+		rec, err := getNstore(ctx, app, spec)
+		m.Add(rec)
 		if err != nil {
 			app.log.Errorf("%s: %v", spec.Symbol, err)
 			continue
 		}
-		app.log.Infof("%s: saved to %s", spec.Symbol, fname)
+		app.log.Infof("%s: saved to %s", spec.Symbol, rec.File)
 	}
 
 	return nil
 }
 
-func getNstore(ctx context.Context, app App, spec instrument.Spec) (string, error) {
+// getNstore fetches and stores data of spec,
+// returned record describes the outcome for run manifest.
+func getNstore(ctx context.Context, app App, spec instrument.Spec) (manifest.Symbol, error) {
+	rec := manifest.Symbol{Symbol: spec.Symbol, Status: manifest.StatusFailed}
+	fail := func(err error) (manifest.Symbol, error) {
+		rec.Error = err.Error()
+		return rec, err
+	}
+
 	select {
 	case <-ctx.Done():
-		return "", fmt.Errorf("operation cancelled")
+		rec.Status = manifest.StatusSkipped
+		return fail(fmt.Errorf("operation cancelled"))
 	default:
 	}
 
 	data, err := fetch(ctx, spec, app)
 	if err != nil {
-		app.log.Errorf("%s: fetch error: %s", spec.Symbol, err)
-		return "", fmt.Errorf("%s: fetch error: %s", spec.Symbol, err)
+		return fail(fmt.Errorf("fetch error: %s", err))
 	}
 	app.log.Debugf("%s: fetch - OK", spec.Symbol)
 
@@ -82,20 +96,29 @@ func getNstore(ctx context.Context, app App, spec instrument.Spec) (string, erro
 	fnameFetch := fname + ".json.swp"
 	if err != nil {
 		osutil.WriteFile(fnameFetch, data)
-		return "", fmt.Errorf("%s: unmarshal error: %s; check %s",
-			spec.Symbol, err, fnameFetch)
+		return fail(fmt.Errorf("unmarshal error: %s; check %s", err, fnameFetch))
 	}
 	// clean up after possible previous errors
 	os.Remove(fnameFetch)
+	// unknown symbols may return empty data with HTTP 200
+	if len(dataOHLC) == 0 || dataOHLC[0].Date.Time().IsZero() {
+		rec.Status = manifest.StatusEmpty
+		return fail(fmt.Errorf("no data; check the symbol"))
+	}
+	rec.Bars = len(dataOHLC)
+	rec.First = dataOHLC[0].Date.String()
+	rec.Last = dataOHLC[len(dataOHLC)-1].Date.String()
 
 	fname += ".csv"
-	dataCSV := ohlcio.ToCSV(dataOHLC, spec.SecurityType)
-	err = saveData(ctx, fname, dataCSV)
+	rec.File = fname
+	dataCSV := ohlcio.ToCSV(dataOHLC, spec)
+	rec.Changes, err = saveData(ctx, fname, dataCSV)
 	if err != nil {
-		return fname, fmt.Errorf("%s: saveData error: %s", spec.Symbol, err)
+		return fail(fmt.Errorf("saveData error: %s", err))
 	}
+	rec.Status = manifest.StatusOK
 
-	return fname, nil
+	return rec, nil
 }
 
 func fetch(ctx context.Context, spec instrument.Spec, app App) ([]byte, error) {
@@ -106,19 +129,22 @@ func fetch(ctx context.Context, spec instrument.Spec, app App) ([]byte, error) {
 	default:
 	}
 
-	url, err := mkURL(app.Config, spec.Symbol)
+	u, err := mkURL(app.Config, spec.Symbol)
 	if err != nil {
 		return output, fmt.Errorf("mkUrl failed: %v", err)
 	}
 
-	resp, err := app.client.Get(url.String())
+	resp, err := app.client.Get(u.String())
 	if err != nil {
+		if e, ok := err.(*url.Error); ok {
+			e.URL = redactURL(u)
+		}
 		return output, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return output, fmt.Errorf("fetch: HTTP Status: %s. URL: %s", resp.Status, url.String())
+		return output, fmt.Errorf("fetch: HTTP Status: %s. URL: %s", resp.Status, redactURL(u))
 	}
 
 	output, err = ioutil.ReadAll(resp.Body)
@@ -129,6 +155,15 @@ func fetch(ctx context.Context, spec instrument.Spec, app App) ([]byte, error) {
 	return output, nil
 }
 
+// redactURL provides u without API token e.g. for error messages
+// which are stored in manifest.
+func redactURL(u url.URL) string {
+	q := u.Query()
+	q.Del("token")
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
 func mkURL(conf Config, ticker string) (url.URL, error) {
 	str := fmt.Sprintf("%s/stock/%s/chart/%s",
 		conf.Setup.BaseURL, ticker, conf.Setup.Range)
@@ -149,39 +184,42 @@ func mkURL(conf Config, ticker string) (url.URL, error) {
 	return *u, nil
 }
 
-func saveData(ctx context.Context, fpath string, data [][]string) error {
+func saveData(ctx context.Context, fpath string, data [][]string) (manifest.Changes, error) {
 	select {
 	case <-ctx.Done():
-		return fmt.Errorf("operation cancelled")
+		return manifest.Changes{}, fmt.Errorf("operation cancelled")
 	default:
 	}
 
 	output := data
+	changes := manifest.Changes{New: len(data) - 1}
 	err := osutil.FileExists(fpath)
 	if err == nil {
-		dataMerged, err := mergeData(fpath, data)
+		dataMerged, merged, err := mergeData(fpath, data)
 		if err != nil {
-			return fmt.Errorf("mergeData %s failed: %v", fpath, err)
+			return changes, fmt.Errorf("mergeData %s failed: %v", fpath, err)
 		}
 		output = dataMerged
+		changes = merged
 	}
 
 	err = writeData(ctx, fpath, output)
 	if err != nil {
-		return fmt.Errorf("writeData %s failed: %v", fpath, err)
+		return changes, fmt.Errorf("writeData %s failed: %v", fpath, err)
 	}
 
-	return nil
+	return changes, nil
 }
 
-func mergeData(fpath string, dataNew [][]string) ([][]string, error) {
+func mergeData(fpath string, dataNew [][]string) ([][]string, manifest.Changes, error) {
 	header := dataNew[0]
 	output := [][]string{header}
 	data := map[string][]string{}
+	changes := manifest.Changes{}
 
 	file, err := os.Open(fpath)
 	if err != nil {
-		return output, fmt.Errorf("open data file error: %v", err)
+		return output, changes, fmt.Errorf("open data file error: %v", err)
 	}
 	defer file.Close()
 
@@ -193,12 +231,19 @@ func mergeData(fpath string, dataNew [][]string) ([][]string, error) {
 		if err == io.EOF {
 			break
 		} else if err != nil {
-			return output, fmt.Errorf("read data file error: %v", err)
+			return output, changes, fmt.Errorf("read data file error: %v", err)
 		}
 		data[record[0]] = record
 	}
 
 	for _, record := range dataNew[1:] { // skip CSV header
+		prev, ok := data[record[0]]
+		switch {
+		case !ok:
+			changes.New++
+		case strings.Join(prev, ";") != strings.Join(record, ";"):
+			changes.Updated++
+		}
 		data[record[0]] = record
 	}
 
@@ -211,7 +256,7 @@ func mergeData(fpath string, dataNew [][]string) ([][]string, error) {
 		func(i, j int) bool { return sorted[i][0] < sorted[j][0] })
 
 	output = append(output, sorted...)
-	return output, nil
+	return output, changes, nil
 }
 
 func writeData(ctx context.Context, fpath string, data [][]string) error {
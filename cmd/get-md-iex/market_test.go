@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/profioss/clog"
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/pkg/manifest"
+	"github.com/profioss/trada/pkg/mdtest"
+)
+
+func mkTestApp(t *testing.T, srv *mdtest.Server, dir string, r dataRange, symbols ...string) App {
+	t.Helper()
+
+	logger, err := clog.New(ioutil.Discard, "disabled", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := App{client: srv.Client(), log: logger}
+	app.Config.Setup = Setup{
+		Range:      r,
+		BaseURL:    srv.URL,
+		Token:      "test",
+		Timeout:    5 * time.Second,
+		MaxProcs:   1,
+		OutputDir:  dir,
+		Watchlists: []string{"unused.csv"},
+	}
+	for _, sym := range symbols {
+		app.Config.instrSpecs = append(app.Config.instrSpecs,
+			instrument.Spec{Symbol: sym, SecurityType: instrument.Equity})
+	}
+	if err := app.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	return app
+}
+
+func TestWork(t *testing.T) {
+	srv := mdtest.NewServer("testdata")
+	defer srv.Close()
+	srv.SetMode("MSFT", mdtest.ModeEmpty)
+	srv.SetMode("SPY", mdtest.ModeRateLimited)
+	srv.SetMode("QQQ", mdtest.ModeServerError)
+	srv.SetMode("IWM", mdtest.ModeMalformed)
+
+	dir, err := ioutil.TempDir("", "get-md-iex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	app := mkTestApp(t, srv, dir, "3m", "AAPL", "MSFT", "SPY", "QQQ", "IWM", "GOOG")
+	m := manifest.New("get-md-iex", "test", "3m")
+	if err := work(context.Background(), app, m); err != nil {
+		t.Fatal(err)
+	}
+	m.Finish()
+	statuses := map[string]manifest.Status{}
+	for _, rec := range m.Symbols {
+		statuses[rec.Symbol] = rec.Status
+		if rec.Symbol == "SPY" && (rec.Error == "" || strings.Contains(rec.Error, "token=")) {
+			t.Errorf("SPY - error should not contain API token: %q", rec.Error)
+		}
+	}
+
+	tests := []struct {
+		symbol string
+		rows   int // data rows of CSV; 0 means no file expected
+		swp    bool
+		status manifest.Status
+	}{
+		{symbol: "AAPL", rows: 3, status: manifest.StatusOK},
+		{symbol: "MSFT", status: manifest.StatusEmpty},            // empty data
+		{symbol: "SPY", status: manifest.StatusFailed},            // 429
+		{symbol: "QQQ", status: manifest.StatusFailed},            // 500
+		{symbol: "IWM", swp: true, status: manifest.StatusFailed}, // malformed JSON stored for analysis
+		{symbol: "GOOG", status: manifest.StatusFailed},           // 404 - no fixture
+	}
+
+	for _, tc := range tests {
+		if statuses[tc.symbol] != tc.status {
+			t.Errorf("%s - manifest status should be %q, got %q", tc.symbol, tc.status, statuses[tc.symbol])
+		}
+
+		fname := filepath.Join(dir, tc.symbol+".csv")
+		data, err := ioutil.ReadFile(fname)
+		switch {
+		case tc.rows == 0 && err == nil:
+			t.Errorf("%s - unexpected output file %s", tc.symbol, fname)
+		case tc.rows > 0 && err != nil:
+			t.Errorf("%s - unexpected error: %v", tc.symbol, err)
+		case tc.rows > 0:
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			if len(lines) != tc.rows+1 {
+				t.Errorf("%s - expected %d rows, got %d", tc.symbol, tc.rows, len(lines)-1)
+			}
+			if lines[1] != "2019-10-28;247.42;249.25;246.72;249.05;24143241" {
+				t.Errorf("%s - unexpected first row: %s", tc.symbol, lines[1])
+			}
+		}
+
+		_, err = os.Stat(filepath.Join(dir, tc.symbol+".json.swp"))
+		if tc.swp != (err == nil) {
+			t.Errorf("%s - .json.swp file expected: %v, got error: %v", tc.symbol, tc.swp, err)
+		}
+	}
+
+	// previous day merged into existing data
+	app = mkTestApp(t, srv, dir, "1d", "AAPL")
+	m = manifest.New("get-md-iex", "test", "1d")
+	if err := work(context.Background(), app, m); err != nil {
+		t.Fatal(err)
+	}
+	if rec := m.Symbols[0]; rec.Bars != 1 || rec.New != 0 || rec.Updated != 0 {
+		t.Errorf("AAPL - unchanged previous day expected, got %+v", rec)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "AAPL.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "\n"); n != 4 {
+		t.Errorf("AAPL - expected 4 lines after merge, got %d", n)
+	}
+
+	if n := len(srv.Requests()); n != 7 {
+		t.Errorf("expected 7 requests, got %d: %v", n, srv.Requests())
+	}
+}
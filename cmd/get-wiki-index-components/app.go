@@ -7,14 +7,20 @@ import (
 	"time"
 
 	"github.com/profioss/clog"
+	"github.com/profioss/trada/pkg/httputil"
+	"github.com/profioss/trada/pkg/metrics"
+	"github.com/profioss/trada/pkg/wiki"
 )
 
 // App defines application.
 type App struct {
 	Config
 	client  *http.Client
+	wiki    *wiki.Client
+	cache   respCache
 	log     clog.Logger
 	logFile *os.File
+	metrics *metrics.Fetcher
 }
 
 // Close finishes App by closing open resources.
@@ -32,6 +38,9 @@ func (a App) Validate() error {
 	case a.client == nil:
 		return fmt.Errorf("client is not initialized")
 
+	case a.wiki == nil:
+		return fmt.Errorf("wiki client is not initialized")
+
 	case a.log == nil:
 		return fmt.Errorf("log is not initialized")
 	}
@@ -49,7 +58,10 @@ func newApp() (App, error) {
 	}
 	app.Config = conf
 
-	app.client = mkClient(conf)
+	app.metrics = metrics.NewFetcher("get-wiki-index-components")
+	app.client = mkClient(conf, app.metrics)
+	app.wiki = mkWikiClient(conf, app.client)
+	app.cache = respCache{dir: conf.Setup.CacheDir}
 
 	logf, err := clog.OpenFile(conf.Setup.LogFile)
 	if err != nil {
@@ -60,15 +72,35 @@ func newApp() (App, error) {
 		return app, err
 	}
 	app.log = logger
+	if conf.Setup.MetricsFile != "" {
+		// failed run keeps time of the last success
+		if err := app.metrics.RestoreSuccess(conf.Setup.MetricsFile); err != nil {
+			app.log.Warnf("metrics: %s", err)
+		}
+	}
 
 	return app, app.Validate()
 }
 
-func mkClient(conf Config) *http.Client {
+// mkClient creates HTTP client; requests are recorded to m (if not nil).
+func mkClient(conf Config, m *metrics.Fetcher) *http.Client {
 	return &http.Client{
-		Transport: &http.Transport{
-			DisableKeepAlives: false,
+		Transport: &httputil.Transport{
+			Base: m.Transport(&http.Transport{
+				DisableKeepAlives: false,
+			}),
+			UserAgent: conf.Setup.UserAgent,
+			Limiter:   httputil.NewHostLimiter(conf.Setup.RequestInterval),
 		},
 		Timeout: time.Duration(conf.Setup.Timeout),
 	}
 }
+
+func mkWikiClient(conf Config, client *http.Client) *wiki.Client {
+	return &wiki.Client{
+		API:     conf.Setup.WikiAPI,
+		HTTP:    client,
+		MaxLag:  conf.Setup.MaxLag,
+		Retries: conf.Setup.Retries,
+	}
+}
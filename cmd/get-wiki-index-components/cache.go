@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/profioss/trada/pkg/osutil"
+)
+
+// respCache stores raw API responses in directory.
+// Each response is stored as <key>.json with <key>.meta sidecar
+// describing the request and fetch time.
+type respCache struct {
+	dir string
+}
+
+// cacheMeta describes cached API response.
+type cacheMeta struct {
+	URL     string    `json:"url"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// cacheEntry is cached API response.
+type cacheEntry struct {
+	cacheMeta
+	Data []byte
+}
+
+// age returns how old cached response is.
+func (e cacheEntry) age() time.Duration {
+	return time.Since(e.Fetched)
+}
+
+func (c respCache) dataPath(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+func (c respCache) metaPath(key string) string {
+	return filepath.Join(c.dir, key+".meta")
+}
+
+// load reads cached response by key.
+func (c respCache) load(key string) (cacheEntry, error) {
+	entry := cacheEntry{}
+
+	meta, err := ioutil.ReadFile(c.metaPath(key))
+	if err != nil {
+		return entry, fmt.Errorf("cache: %v", err)
+	}
+	err = json.Unmarshal(meta, &entry.cacheMeta)
+	if err != nil {
+		return entry, fmt.Errorf("cache: invalid %s: %v", c.metaPath(key), err)
+	}
+
+	entry.Data, err = ioutil.ReadFile(c.dataPath(key))
+	if err != nil {
+		return entry, fmt.Errorf("cache: %v", err)
+	}
+
+	return entry, nil
+}
+
+// store writes response to cache.
+func (c respCache) store(key string, entry cacheEntry) error {
+	meta, err := json.MarshalIndent(entry.cacheMeta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cache: %v", err)
+	}
+
+	// data first - meta marks complete entry
+	if err := osutil.WriteFile(c.dataPath(key), entry.Data); err != nil {
+		return fmt.Errorf("cache: %v", err)
+	}
+	if err := osutil.WriteFile(c.metaPath(key), meta); err != nil {
+		return fmt.Errorf("cache: %v", err)
+	}
+
+	return nil
+}
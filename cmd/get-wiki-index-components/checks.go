@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/pkg/osutil"
+)
+
+// Names of sanity checks.
+const (
+	checkMinCnt   = "min-cnt"
+	checkMaxCnt   = "max-cnt"
+	checkSymbol   = "symbol-regex"
+	checkUnique   = "unique-symbols"
+	checkRequired = "required"
+	checkMaxChurn = "max-churn"
+	checkPrevious = "previous"
+)
+
+const checkSeparator = ", "
+
+// checkFailure describes failed sanity check.
+type checkFailure struct {
+	check string
+	msg   string
+}
+
+func (cf checkFailure) String() string {
+	return cf.check + ": " + cf.msg
+}
+
+// checkComponents runs sanity checks defined by DataSrc on parsed components.
+// prev are components from previous output file; empty if there is none.
+// All checks are evaluated, list of failed ones is returned.
+func checkComponents(ds DataSrc, components, prev []instrument.Spec) []checkFailure {
+	failed := []checkFailure{}
+	cnt := len(components)
+
+	if cnt < ds.MinCnt {
+		failed = append(failed, checkFailure{checkMinCnt,
+			fmt.Sprintf("expected at least %d components, got %d", ds.MinCnt, cnt)})
+	}
+
+	if ds.MaxCnt > 0 && cnt > ds.MaxCnt {
+		failed = append(failed, checkFailure{checkMaxCnt,
+			fmt.Sprintf("expected at most %d components, got %d", ds.MaxCnt, cnt)})
+	}
+
+	if ds.SymbolRegex != "" {
+		re, err := regexp.Compile(ds.SymbolRegex)
+		if err != nil {
+			failed = append(failed, checkFailure{checkSymbol, err.Error()})
+		} else {
+			invalid := []string{}
+			for _, c := range components {
+				if !re.MatchString(c.Symbol) {
+					invalid = append(invalid, fmt.Sprintf("%q", c.Symbol))
+				}
+			}
+			if len(invalid) > 0 {
+				failed = append(failed, checkFailure{checkSymbol,
+					fmt.Sprintf("symbols not matching %s: %s", ds.SymbolRegex, strings.Join(invalid, checkSeparator))})
+			}
+		}
+	}
+
+	if ds.UniqueSymbols {
+		seen := make(map[string]bool, cnt)
+		dups := []string{}
+		for _, c := range components {
+			if seen[c.Symbol] {
+				dups = append(dups, c.Symbol)
+			}
+			seen[c.Symbol] = true
+		}
+		if len(dups) > 0 {
+			failed = append(failed, checkFailure{checkUnique,
+				fmt.Sprintf("duplicate symbols: %s", strings.Join(dups, checkSeparator))})
+		}
+	}
+
+	if len(ds.Required) > 0 {
+		symbols := symbolSet(components)
+		missing := []string{}
+		for _, sym := range ds.Required {
+			if !symbols[sym] {
+				missing = append(missing, sym)
+			}
+		}
+		if len(missing) > 0 {
+			failed = append(failed, checkFailure{checkRequired,
+				fmt.Sprintf("missing symbols: %s", strings.Join(missing, checkSeparator))})
+		}
+	}
+
+	if ds.MaxChurn > 0 && len(prev) > 0 {
+		ratio, added, removed := churn(prev, components)
+		if ratio > ds.MaxChurn {
+			failed = append(failed, checkFailure{checkMaxChurn,
+				fmt.Sprintf("%.1f%% of components changed (max %.1f%%); added: %s; removed: %s",
+					ratio*100, ds.MaxChurn*100,
+					strings.Join(added, checkSeparator), strings.Join(removed, checkSeparator))})
+		}
+	}
+
+	return failed
+}
+
+// churn returns ratio of changed components compared to previous ones
+// with lists of added and removed symbols.
+// Replacing one component by another is counted as 1 change.
+func churn(prev, components []instrument.Spec) (float64, []string, []string) {
+	prevSymbols := symbolSet(prev)
+	symbols := symbolSet(components)
+
+	added := []string{}
+	for _, c := range components {
+		if !prevSymbols[c.Symbol] {
+			added = append(added, c.Symbol)
+		}
+	}
+	removed := []string{}
+	for _, c := range prev {
+		if !symbols[c.Symbol] {
+			removed = append(removed, c.Symbol)
+		}
+	}
+
+	if len(prevSymbols) == 0 {
+		return 0, added, removed
+	}
+	changed := len(added)
+	if len(removed) > changed {
+		changed = len(removed)
+	}
+
+	return float64(changed) / float64(len(prevSymbols)), added, removed
+}
+
+func symbolSet(components []instrument.Spec) map[string]bool {
+	output := make(map[string]bool, len(components))
+	for _, c := range components {
+		output[c.Symbol] = true
+	}
+	return output
+}
+
+// loadPrevious loads components from previous output file.
+// Empty list is returned if the file does not exist.
+func loadPrevious(fpath string) ([]instrument.Spec, error) {
+	if osutil.FileExists(fpath) != nil {
+		return []instrument.Spec{}, nil
+	}
+
+	fd, err := os.Open(fpath)
+	if err != nil {
+		return []instrument.Spec{}, err
+	}
+	defer fd.Close()
+
+	return instrument.SpecLstFromCSV(fd)
+}
+
+func checkNames(failed []checkFailure) string {
+	names := make([]string, 0, len(failed))
+	for _, cf := range failed {
+		names = append(names, cf.check)
+	}
+	return strings.Join(names, checkSeparator)
+}
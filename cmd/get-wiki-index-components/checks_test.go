@@ -0,0 +1,98 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/profioss/trada/model/instrument"
+)
+
+func mkSpecs(symbols ...string) []instrument.Spec {
+	output := make([]instrument.Spec, 0, len(symbols))
+	for _, s := range symbols {
+		output = append(output, instrument.Spec{Symbol: s, SecurityType: instrument.Equity})
+	}
+	return output
+}
+
+func TestCheckComponents(t *testing.T) {
+	prev := mkSpecs("AAPL", "AMZN", "JPM", "MSFT", "XOM", "BRK.B", "V", "MA", "KO", "PG")
+
+	tests := []struct {
+		label      string
+		ds         DataSrc
+		components []instrument.Spec
+		failed     []string
+	}{
+		{
+			label:      "no checks",
+			ds:         DataSrc{},
+			components: mkSpecs("AAPL"),
+		},
+		{
+			label:      "min-cnt",
+			ds:         DataSrc{MinCnt: 2},
+			components: mkSpecs("AAPL"),
+			failed:     []string{checkMinCnt},
+		},
+		{
+			label:      "max-cnt",
+			ds:         DataSrc{MaxCnt: 1},
+			components: mkSpecs("AAPL", "MSFT"),
+			failed:     []string{checkMaxCnt},
+		},
+		{
+			label:      "symbol-regex",
+			ds:         DataSrc{SymbolRegex: "^[A-Z][A-Z.]{0,5}$"},
+			components: mkSpecs("AAPL", "BRK.B", "[1]"),
+			failed:     []string{checkSymbol},
+		},
+		{
+			label:      "unique-symbols",
+			ds:         DataSrc{UniqueSymbols: true},
+			components: mkSpecs("AAPL", "MSFT", "AAPL"),
+			failed:     []string{checkUnique},
+		},
+		{
+			label:      "required",
+			ds:         DataSrc{Required: []string{"AAPL", "JPM"}},
+			components: mkSpecs("AAPL", "MSFT"),
+			failed:     []string{checkRequired},
+		},
+		{
+			label:      "max-churn pass",
+			ds:         DataSrc{MaxChurn: 0.1},
+			components: mkSpecs("AAPL", "AMZN", "JPM", "MSFT", "XOM", "BRK.B", "V", "MA", "KO", "PEP"),
+		},
+		{
+			label:      "max-churn",
+			ds:         DataSrc{MaxChurn: 0.1},
+			components: mkSpecs("AAPL", "AMZN", "JPM", "MSFT", "XOM", "BRK.B", "V", "MA", "WMT", "PEP"),
+			failed:     []string{checkMaxChurn},
+		},
+		{
+			label:      "multiple",
+			ds:         DataSrc{MinCnt: 5, UniqueSymbols: true, Required: []string{"JPM"}},
+			components: mkSpecs("AAPL", "AAPL"),
+			failed:     []string{checkMinCnt, checkUnique, checkRequired},
+		},
+	}
+
+	for _, tc := range tests {
+		failed := checkComponents(tc.ds, tc.components, prev)
+		if len(failed) != len(tc.failed) {
+			t.Errorf("%s - expected failed checks: %v, got: %v", tc.label, tc.failed, failed)
+			continue
+		}
+		for i := range failed {
+			if failed[i].check != tc.failed[i] {
+				t.Errorf("%s - expected failed check %s, got %s", tc.label, tc.failed[i], failed[i])
+			}
+		}
+	}
+
+	// no previous data - churn is not checked
+	failed := checkComponents(DataSrc{MaxChurn: 0.1}, mkSpecs("AAPL"), nil)
+	if len(failed) > 0 {
+		t.Errorf("max-churn without previous data should pass, got: %v", failed)
+	}
+}
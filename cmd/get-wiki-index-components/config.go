@@ -5,12 +5,15 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
 	toml "github.com/pelletier/go-toml"
 	"github.com/profioss/clog"
 	"github.com/profioss/trada/cmd/get-wiki-index-components/parser"
+	"github.com/profioss/trada/pkg/wiki"
 )
 
 // Config is main configuration.
@@ -21,6 +24,8 @@ type Config struct {
 	// cmd line flags, not part of the config file
 	path          string
 	updateTstData bool
+	offline       bool
+	maxAge        time.Duration
 	verbose       bool
 }
 
@@ -45,14 +50,25 @@ func (c Config) Validate() error {
 
 // Setup defines command setup.
 type Setup struct {
-	WikiAPI   string        `toml:"wiki-api"`
-	Timeout   time.Duration `toml:"timeout"`
-	MaxProcs  int           `toml:"max-procs"`
-	LogFile   string        `toml:"log-file"`
-	LogLevel  string        `toml:"log-level"`
-	OutputDir string        `toml:"output-dir"`
+	WikiAPI         string        `toml:"wiki-api"`
+	UserAgent       string        `toml:"user-agent"`
+	Timeout         time.Duration `toml:"timeout"`
+	RequestInterval time.Duration `toml:"request-interval"`
+	MaxLag          int           `toml:"max-lag"`
+	Retries         int           `toml:"retries"`
+	MaxProcs        int           `toml:"max-procs"`
+	LogFile         string        `toml:"log-file"`
+	LogLevel        string        `toml:"log-level"`
+	OutputDir       string        `toml:"output-dir"`
+	CacheDir        string        `toml:"cache-dir"`
+	MetricsAddr     string        `toml:"metrics-addr"`
+	MetricsFile     string        `toml:"metrics-file"`
 }
 
+// defaultUserAgent identifies the client as required by Wikimedia
+// User-Agent policy: https://meta.wikimedia.org/wiki/User-Agent_policy
+const defaultUserAgent = "trada-get-wiki-index-components/0.1 (https://github.com/profioss/trada)"
+
 // Validate checks if Setup is valid.
 func (s Setup) Validate() error {
 	switch {
@@ -64,6 +80,21 @@ func (s Setup) Validate() error {
 
 	case s.Timeout < 1:
 		return errors.New("Setup: Timeout is set too low")
+
+	case s.RequestInterval < 0:
+		return errors.New("Setup: RequestInterval is < 0")
+
+	case s.CacheDir == "":
+		return errors.New("Setup: CacheDir is not specified")
+
+	case s.MaxLag < 0:
+		return errors.New("Setup: MaxLag is < 0")
+
+	case s.Retries < 0:
+		return errors.New("Setup: Retries is < 0")
+
+	case s.MaxProcs < 1:
+		return errors.New("Setup: MaxProcs is < 1")
 	}
 
 	// LogLevel and LogFile can be empty, safe defaults are used in initConfig()
@@ -73,11 +104,20 @@ func (s Setup) Validate() error {
 
 // DataSrc defines data sources.
 type DataSrc struct {
-	Name       string `toml:"name"`
-	PageName   string `toml:"page-name"`
-	Section    int    `toml:"section"`
-	MinCnt     int    `toml:"min-cnt"`
-	OutputFile string `toml:"output-file"`
+	Name         string `toml:"name"`
+	PageName     string `toml:"page-name"`
+	OldID        int64  `toml:"oldid"`
+	Section      int    `toml:"section"`
+	SectionTitle string `toml:"section-title"` // overrides Section
+	MinCnt       int    `toml:"min-cnt"`
+	OutputFile   string `toml:"output-file"`
+
+	// optional sanity checks
+	MaxCnt        int      `toml:"max-cnt"`
+	SymbolRegex   string   `toml:"symbol-regex"`
+	UniqueSymbols bool     `toml:"unique-symbols"`
+	Required      []string `toml:"required"`
+	MaxChurn      float64  `toml:"max-churn"`
 }
 
 // Validate checks if DataSrc is valid.
@@ -92,8 +132,21 @@ func (ds DataSrc) Validate() error {
 	case ds.OutputFile == "":
 		return errors.New("DataSrc: OutputFile is not specified")
 
-	case ds.Section < 1:
-		return errors.New("DataSrc: Section is < 1")
+	case ds.Section < 1 && ds.SectionTitle == "":
+		return errors.New("DataSrc: Section is < 1 and SectionTitle is not specified")
+
+	case ds.OldID < 0:
+		return errors.New("DataSrc: OldID is < 0")
+
+	case ds.MaxCnt > 0 && ds.MaxCnt < ds.MinCnt:
+		return errors.New("DataSrc: MaxCnt is < MinCnt")
+
+	case ds.MaxChurn < 0 || ds.MaxChurn > 1:
+		return errors.New("DataSrc: MaxChurn is not in range 0 - 1")
+	}
+
+	if _, err := regexp.Compile(ds.SymbolRegex); err != nil {
+		return fmt.Errorf("DataSrc: invalid SymbolRegex: %v", err)
 	}
 
 	_, err := parser.Get(ds.Name)
@@ -105,12 +158,20 @@ func (ds DataSrc) Validate() error {
 	return nil
 }
 
+// page returns wiki page defined by DataSrc.
+func (ds DataSrc) page() wiki.Page {
+	return wiki.Page{Name: ds.PageName, OldID: ds.OldID}
+}
+
 func initSettings() Config {
 	cfg := Config{}
 
 	flag.StringVar(&cfg.path, "c", "config/get-wiki-index-components.toml", "config file")
 	flag.StringVar(&cfg.Setup.LogLevel, "log-level", "", "log levels: disabled | error | warning | info | debug")
 	flag.StringVar(&cfg.Setup.OutputDir, "o", "", "output data directory")
+	flag.StringVar(&cfg.Setup.CacheDir, "cache-dir", "", "API response cache directory")
+	flag.BoolVar(&cfg.offline, "offline", false, "offline mode - parse cached API responses, don't fetch")
+	flag.DurationVar(&cfg.maxAge, "max-age", 0, "reuse cached API responses younger than max-age (ex: 12h)")
 	flag.BoolVar(&cfg.updateTstData, "update-test-data", false, "update test data - use with -o testdata")
 	flag.BoolVar(&cfg.verbose, "v", false, "verbose mode")
 
@@ -143,7 +204,16 @@ func initConfig(settings Config) (Config, error) {
 
 	conf.verbose = settings.verbose
 	conf.updateTstData = settings.updateTstData
+	conf.offline = settings.offline
+	conf.maxAge = settings.maxAge
 	conf.Setup.Timeout = time.Duration(conf.Setup.Timeout) * time.Second
+	conf.Setup.RequestInterval = time.Duration(conf.Setup.RequestInterval) * time.Second
+	if conf.Setup.MaxProcs == 0 { // not set; negative values fail validation
+		conf.Setup.MaxProcs = 1
+	}
+	if conf.Setup.UserAgent == "" {
+		conf.Setup.UserAgent = defaultUserAgent
+	}
 	//
 	// override setup from config by cmdline args
 	if settings.Setup.Timeout > 0 {
@@ -152,6 +222,12 @@ func initConfig(settings Config) (Config, error) {
 	if settings.Setup.OutputDir != "" {
 		conf.Setup.OutputDir = settings.Setup.OutputDir
 	}
+	if settings.Setup.CacheDir != "" {
+		conf.Setup.CacheDir = settings.Setup.CacheDir
+	}
+	if conf.Setup.CacheDir == "" && conf.Setup.OutputDir != "" {
+		conf.Setup.CacheDir = filepath.Join(conf.Setup.OutputDir, "cache")
+	}
 
 	// default log level
 	// log levels: disabled | error | warning | info | debug
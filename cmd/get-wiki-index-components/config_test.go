@@ -1,6 +1,11 @@
 package main
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -66,6 +71,24 @@ func TestInitConfig(t *testing.T) {
 			}(),
 			hasErr: true,
 		},
+		{
+			label: "invalid Setup.MaxProcs",
+			conf: func() Config {
+				c := conf
+				c.Setup.MaxProcs = 0
+				return c
+			}(),
+			hasErr: true,
+		},
+		{
+			label: "invalid Setup.RequestInterval",
+			conf: func() Config {
+				c := conf
+				c.Setup.RequestInterval = -1
+				return c
+			}(),
+			hasErr: true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -100,6 +123,24 @@ func TestInitConfigOverrides(t *testing.T) {
 		t.Errorf("Config.updateTstData should be %v, got %v", settings.updateTstData, config.updateTstData)
 	}
 
+	settings.offline = true
+	config, err = initConfig(settings)
+	switch {
+	case err != nil:
+		t.Fatalf("Config.offline - unexpected error: %v", err)
+	case config.offline != settings.offline:
+		t.Errorf("Config.offline should be %v, got %v", settings.offline, config.offline)
+	}
+
+	settings.maxAge = 12 * time.Hour
+	config, err = initConfig(settings)
+	switch {
+	case err != nil:
+		t.Fatalf("Config.maxAge - unexpected error: %v", err)
+	case config.maxAge != settings.maxAge:
+		t.Errorf("Config.maxAge should be %v, got %v", settings.maxAge, config.maxAge)
+	}
+
 	var seconds float64 = 999
 	settings.Setup.Timeout = time.Duration(seconds) * time.Second
 	config, err = initConfig(settings)
@@ -121,6 +162,15 @@ func TestInitConfigOverrides(t *testing.T) {
 		t.Errorf("Config.Setup.OutputDir should be %v, got %v", settings.Setup.OutputDir, config.Setup.OutputDir)
 	}
 
+	settings.Setup.CacheDir = "xy9xy9/cache"
+	config, err = initConfig(settings)
+	switch {
+	case err != nil:
+		t.Fatalf("Config.Setup.CacheDir - unexpected error: %v", err)
+	case config.Setup.CacheDir != settings.Setup.CacheDir:
+		t.Errorf("Config.Setup.CacheDir should be %v, got %v", settings.Setup.CacheDir, config.Setup.CacheDir)
+	}
+
 	settings.Setup.LogLevel = "debug"
 	config, err = initConfig(settings)
 	switch {
@@ -165,6 +215,47 @@ func TestInitConfigSettings(t *testing.T) {
 	}
 }
 
+func TestInitConfigMaxProcs(t *testing.T) {
+	src, err := ioutil.ReadFile("testdata/get-wiki-index-components.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "wiki-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		label    string
+		maxProcs string
+		expected int
+		hasErr   bool
+	}{
+		{label: "configured", maxProcs: "max-procs = 2", expected: 2},
+		{label: "not set", maxProcs: "", expected: 1},
+		{label: "zero", maxProcs: "max-procs = 0", expected: 1},
+		{label: "negative", maxProcs: "max-procs = -1", hasErr: true},
+	}
+
+	for i, tc := range tests {
+		data := strings.Replace(string(src), "max-procs = 4", tc.maxProcs, 1)
+		path := filepath.Join(dir, fmt.Sprintf("config-%d.toml", i))
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		conf, err := initConfig(Config{path: path})
+		switch {
+		case tc.hasErr && err == nil:
+			t.Errorf("%s - should have an error", tc.label)
+		case !tc.hasErr && err != nil:
+			t.Errorf("%s - unexpected error: %s", tc.label, err)
+		case !tc.hasErr && conf.Setup.MaxProcs != tc.expected:
+			t.Errorf("%s - MaxProcs should be %d, got %d", tc.label, tc.expected, conf.Setup.MaxProcs)
+		}
+	}
+}
+
 func TestDataSrcValidation(t *testing.T) {
 	tdata := []struct {
 		label  string
@@ -237,6 +328,29 @@ func TestDataSrcValidation(t *testing.T) {
 			},
 			hasErr: true,
 		},
+		{
+			label: "valid SectionTitle",
+			ds: DataSrc{
+				Name:         "DJIA",
+				PageName:     "Dow_Jones_Industrial_Average",
+				OutputFile:   "DJIA-components.csv",
+				SectionTitle: "Components",
+				MinCnt:       25,
+			},
+			hasErr: false,
+		},
+		{
+			label: "invalid OldID",
+			ds: DataSrc{
+				Name:       "DJIA",
+				PageName:   "Dow_Jones_Industrial_Average",
+				OutputFile: "DJIA-components.csv",
+				OldID:      -1,
+				Section:    1,
+				MinCnt:     25,
+			},
+			hasErr: true,
+		},
 	}
 
 	for _, tc := range tdata {
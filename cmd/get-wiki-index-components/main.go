@@ -1,23 +1,25 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"net/url"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/profioss/trada/cmd/get-wiki-index-components/parser"
 	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/pkg/manifest"
+	"github.com/profioss/trada/pkg/osutil"
 	"github.com/profioss/trada/pkg/wiki"
 
 	// Mapping of DataSrc.Name in Config with content parser.
@@ -54,8 +56,8 @@ func main() {
 		os.Exit(exitCode)
 	}()
 
+	wg.Add(1)
 	go func() {
-		wg.Add(1)
 		select {
 		case s := <-sigChan:
 			app.log.Warnf("Got %s signal - exitting", s)
@@ -84,63 +86,180 @@ func main() {
 }
 
 func do(ctx context.Context, app App) error {
-	for _, r := range app.Resources {
-		app.log.Info("Fetching ", r.Name)
-		err := getNparse(ctx, app, r)
+	if app.Setup.MetricsAddr != "" {
+		stop, err := app.metrics.Serve(app.Setup.MetricsAddr)
 		if err != nil {
-			app.log.Error(err)
-			// don't stop - get next resource
+			return fmt.Errorf("metrics server error: %v", err)
+		}
+		defer stop()
+		app.log.Infof("Serving metrics at http://%s/metrics", app.Setup.MetricsAddr)
+	}
+
+	jobs := make(chan int)
+	results := make([]result, len(app.Resources))
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < app.Setup.MaxProcs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				ds := app.Resources[idx]
+				app.log.Info("Fetching ", ds.Name)
+				res := getNparse(ctx, app, ds)
+				if res.err != nil {
+					app.log.Error(res.err)
+					// don't stop - get next resource
+				}
+				results[idx] = res
+			}
+		}()
+	}
+
+	for i, ds := range app.Resources {
+		select {
+		case <-ctx.Done():
+			results[i] = result{ds: ds, err: fmt.Errorf("%s: operation cancelled", ds.Name)}
+		case jobs <- i:
 		}
 	}
+	close(jobs)
+	wg.Wait()
+
+	printSummary(os.Stdout, results)
+
+	failed := 0
+	for _, res := range results {
+		if res.err != nil {
+			failed++
+			app.metrics.Status(manifest.StatusFailed)
+			continue
+		}
+		app.metrics.Status(manifest.StatusOK)
+		app.metrics.Written(res.count)
+	}
+	if failed == 0 {
+		app.metrics.Success(time.Now())
+	}
+	if app.Setup.MetricsFile != "" {
+		if err := app.metrics.Registry.WriteFile(app.Setup.MetricsFile); err != nil {
+			app.log.Errorf("Write metrics error: %s", err)
+		} else {
+			app.log.Infof("Metrics written to %s", app.Setup.MetricsFile)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d resources failed", failed, len(results))
+	}
 
 	return nil
 }
 
-func getNparse(ctx context.Context, app App, ds DataSrc) error {
-	fnameSrc, err := getData(ctx, app, ds)
+func getNparse(ctx context.Context, app App, ds DataSrc) result {
+	res := result{ds: ds}
+
+	if ds.SectionTitle != "" {
+		section, err := findSection(ctx, app, ds)
+		if err != nil {
+			app.log.Errorf("%s: findSection %q failed: %s", ds.Name, ds.SectionTitle, err)
+			res.err = err
+			return res
+		}
+		app.log.Debugf("%s: section %q is %d", ds.Name, ds.SectionTitle, section)
+		ds.Section = section
+		res.ds = ds
+	}
+
+	entry, err := getData(ctx, app, ds)
 	if err != nil {
-		app.log.Errorf("%s: getData failed: %s. Leaving file %s", ds.Name, err, fnameSrc)
-		return err
+		app.log.Errorf("%s: getData failed: %s", ds.Name, err)
+		res.err = err
+		return res
 	}
-	app.log.Debugf("%s: getData to %s - OK", ds.Name, fnameSrc)
+	app.log.Debugf("%s: getData - OK", ds.Name)
 
-	if !app.updateTstData { // update testing data mode
-		defer os.Remove(fnameSrc)
+	if app.updateTstData { // update testing data mode
+		fname := filepath.Join(app.Setup.OutputDir, ds.OutputFile+".json")
+		err = osutil.WriteFile(fname, entry.Data)
+		if err != nil {
+			res.err = fmt.Errorf("write test data %s error: %v", fname, err)
+			return res
+		}
 	}
 
-	fd, err := os.Open(fnameSrc)
-	if err != nil {
-		return fmt.Errorf("open %s error: %v", fnameSrc, err)
+	wd, err := wiki.Parse(bytes.NewReader(entry.Data))
+	switch {
+	case errors.Is(err, wiki.ErrInvalidSection):
+		res.err = fmt.Errorf("%s: %v; check section or section-title", ds.Name, err)
+		return res
+	case err != nil:
+		res.err = fmt.Errorf("%s: %v", ds.Name, err)
+		return res
 	}
-	defer fd.Close()
-	wd, err := wiki.Parse(fd)
-	if err != nil {
-		return err
+	for _, r := range wd.Parsed.Redirects {
+		app.log.Warnf("%s: page %q redirects to %q; update page-name", ds.Name, r.From, r.To)
+	}
+
+	prov := newProvenance(app.Config, ds, wd, entry.Fetched)
+	if wd.Parsed.RevID > 0 {
+		rev, err := getRevision(ctx, app, ds, wd.Parsed.RevID)
+		if err != nil {
+			// not critical - revision id is known
+			app.log.Warnf("%s: getRevision %d failed: %s", ds.Name, wd.Parsed.RevID, err)
+		}
+		prov.RevisionTime = rev.Timestamp
 	}
 
 	components, err := parseData(app, wd, ds)
 	if err != nil {
 		app.log.Errorf("%s: parseData failed: %s", ds.Name, err)
-		return err
+		res.err = err
+		return res
 	}
 	app.log.Debugf("%s: parseData - OK", ds.Name)
 	sort.Slice(components, func(i, j int) bool { return components[i].Symbol < components[j].Symbol })
+	res.parsed = true
+	res.count = len(components)
 
-	// don't overwrite with insufficient data
-	if len(components) < ds.MinCnt {
-		return fmt.Errorf("%s: expected at least %d components, got %d", ds.Name, ds.MinCnt, len(components))
+	fnameDst := filepath.Join(app.Setup.OutputDir, ds.OutputFile)
+	prev := []instrument.Spec{}
+	if ds.MaxChurn > 0 { // previous components are needed only for churn check
+		prev, err = loadPrevious(fnameDst)
+		if err != nil {
+			res.checks = append(res.checks, checkFailure{checkPrevious,
+				fmt.Sprintf("load %s failed: %v", fnameDst, err)})
+		}
+	}
+
+	// don't overwrite with insufficient or suspicious data
+	res.checks = append(res.checks, checkComponents(ds, components, prev)...)
+	if len(res.checks) > 0 {
+		for _, cf := range res.checks {
+			app.log.Errorf("%s: check %s", ds.Name, cf)
+		}
+		res.err = fmt.Errorf("%s: sanity check(s) failed: %s; keeping %s",
+			ds.Name, checkNames(res.checks), fnameDst)
+		return res
 	}
 
-	fnameDst := filepath.Join(app.Setup.OutputDir, ds.OutputFile)
 	err = saveData(fnameDst, components)
 	if err != nil {
 		app.log.Errorf("%s: saveData to %s failed: %s", ds.Name, fnameDst, err)
-		return err
+		res.err = err
+		return res
 	}
 	app.log.Debugf("%s: saveData to %s - OK", ds.Name, fnameDst)
 
+	prov.Rows = len(components)
+	err = saveProvenance(fnameDst, prov)
+	if err != nil {
+		app.log.Errorf("%s: saveProvenance failed: %s", ds.Name, err)
+		res.err = err
+		return res
+	}
+
 	app.log.Infof("%s: %s - OK", ds.Name, fnameDst)
-	return nil
+	return res
 }
 
 func parseData(app App, wd wiki.Data, ds DataSrc) ([]instrument.Spec, error) {
@@ -190,52 +309,107 @@ func saveData(fpath string, components []instrument.Spec) error {
 	return nil
 }
 
-func getData(ctx context.Context, app App, ds DataSrc) (string, error) {
+// getData provides raw API response for DataSrc.
+func getData(ctx context.Context, app App, ds DataSrc) (cacheEntry, error) {
 	u, err := mkURL(app.Config, ds)
 	if err != nil {
-		return "", err
+		return cacheEntry{}, err
 	}
 
-	resp, err := app.client.Get(u.String())
+	return getCached(ctx, app, ds.Name, ds.OutputFile, u, app.maxAge)
+}
+
+// findSection resolves DataSrc.SectionTitle to section index.
+func findSection(ctx context.Context, app App, ds DataSrc) (int, error) {
+	u, err := mkSectionsURL(app.Config, ds)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		// TODO - check what codes the API returns - if it behaves correctly
-		return "", fmt.Errorf("HTTP error: %d for %s", resp.StatusCode, u.String())
+
+	entry, err := getCached(ctx, app, ds.Name, ds.OutputFile+".sections", u, app.maxAge)
+	if err != nil {
+		return 0, err
+	}
+	sections, err := wiki.ParseSections(bytes.NewReader(entry.Data))
+	if err != nil {
+		return 0, err
+	}
+	s, err := wiki.FindSection(sections, ds.SectionTitle)
+	if err != nil {
+		return 0, err
 	}
 
-	fname := filepath.Join(app.Setup.OutputDir, ds.OutputFile+".json")
-	fd, err := os.Create(fname)
+	return s.ID()
+}
+
+// getRevision provides info about page revision.
+// Revisions are immutable so cached response is always reused.
+func getRevision(ctx context.Context, app App, ds DataSrc, revID int64) (wiki.Revision, error) {
+	u, err := mkRevURL(app.Config, revID)
 	if err != nil {
-		return "", err
+		return wiki.Revision{}, err
 	}
-	defer fd.Close()
 
-	_, err = io.Copy(fd, resp.Body)
+	const forever = time.Duration(1<<63 - 1)
+	entry, err := getCached(ctx, app, ds.Name, ds.OutputFile+".rev", u, forever)
 	if err != nil {
-		return "", err
+		return wiki.Revision{}, err
 	}
 
-	return fd.Name(), nil
+	return wiki.ParseRevision(bytes.NewReader(entry.Data))
 }
 
-func mkURL(conf Config, ds DataSrc) (url.URL, error) {
-	u, err := url.Parse(conf.Setup.WikiAPI)
+// getCached provides raw API response stored in cache under key.
+// Cached response is used in offline mode or if it is younger than maxAge.
+// Fetched response is stored to cache.
+func getCached(ctx context.Context, app App, name, key string, u url.URL, maxAge time.Duration) (cacheEntry, error) {
+	cached, errCache := app.cache.load(key)
+	switch {
+	case app.offline && errCache != nil:
+		return cached, fmt.Errorf("offline mode: %v", errCache)
+
+	case app.offline && cached.URL != u.String():
+		return cached, fmt.Errorf("offline mode: cached response is for %s, not %s", cached.URL, u.String())
+
+	case app.offline, errCache == nil && cached.URL == u.String() && cached.age() < maxAge:
+		app.log.Debugf("%s: using cached response fetched at %s",
+			name, cached.Fetched.Format(time.RFC3339))
+		return cached, nil
+	}
+
+	data, err := app.wiki.Get(ctx, u)
 	if err != nil {
-		return url.URL{}, err
+		return cacheEntry{}, err
 	}
 
-	q := u.Query()
-	q.Set("action", "parse")
-	q.Set("format", "json")
-	q.Set("prop", "text")
-	q.Set("page", ds.PageName)
-	q.Set("section", strconv.Itoa(ds.Section))
-	u.RawQuery = q.Encode()
+	entry := cacheEntry{
+		cacheMeta: cacheMeta{URL: u.String(), Fetched: time.Now().UTC()},
+		Data:      data,
+	}
+	err = app.cache.store(key, entry)
+	if err != nil {
+		// data are fine, just can not be reused
+		app.log.Warnf("%s: %v", name, err)
+	}
+
+	return entry, nil
+}
+
+func mkURL(conf Config, ds DataSrc) (url.URL, error) {
+	c := wiki.Client{API: conf.Setup.WikiAPI}
+	return c.ParseURL(ds.page(), ds.Section)
+}
+
+// mkSectionsURL creates URL of page sections list.
+func mkSectionsURL(conf Config, ds DataSrc) (url.URL, error) {
+	c := wiki.Client{API: conf.Setup.WikiAPI}
+	return c.SectionsURL(ds.page())
+}
 
-	return *u, nil
+// mkRevURL creates URL of revision info query.
+func mkRevURL(conf Config, revID int64) (url.URL, error) {
+	c := wiki.Client{API: conf.Setup.WikiAPI}
+	return c.RevisionURL(revID)
 }
 
 func cleanup(app App) {
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/profioss/clog"
+	"github.com/profioss/trada/model/instrument"
+)
+
+// mkOfflineApp creates App in offline mode with cache populated by testdata.
+func mkOfflineApp(t *testing.T, dir string) App {
+	t.Helper()
+
+	conf, err := initConfig(Config{path: "testdata/get-wiki-index-components.toml"})
+	if err != nil {
+		t.Fatalf("initConfig error: %v", err)
+	}
+	conf.offline = true
+	conf.Setup.OutputDir = filepath.Join(dir, "out")
+	conf.Setup.CacheDir = filepath.Join(dir, "cache")
+
+	logger, err := clog.New(ioutil.Discard, "disabled", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := App{
+		Config: conf,
+		client: mkClient(conf, nil),
+		wiki:   mkWikiClient(conf, mkClient(conf, nil)),
+		cache:  respCache{dir: conf.Setup.CacheDir},
+		log:    logger,
+	}
+
+	for _, ds := range conf.Resources {
+		data, err := ioutil.ReadFile(filepath.Join("testdata", ds.OutputFile+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := mkURL(conf, ds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry := cacheEntry{
+			cacheMeta: cacheMeta{URL: u.String(), Fetched: time.Now().UTC()},
+			Data:      data,
+		}
+		if err := app.cache.store(ds.OutputFile, entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return app
+}
+
+func TestOffline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "get-wiki-index-components")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	app := mkOfflineApp(t, dir)
+	for _, ds := range app.Resources {
+		res := getNparse(context.Background(), app, ds)
+		if res.err != nil {
+			t.Errorf("%s: unexpected error: %v", ds.Name, res.err)
+			continue
+		}
+
+		fd, err := os.Open(filepath.Join(app.Setup.OutputDir, ds.OutputFile))
+		if err != nil {
+			t.Errorf("%s: %v", ds.Name, err)
+			continue
+		}
+		specs, err := instrument.SpecLstFromCSV(fd)
+		fd.Close()
+		switch {
+		case err != nil:
+			t.Errorf("%s: unexpected error: %v", ds.Name, err)
+		case len(specs) != res.count:
+			t.Errorf("%s: expected %d components in output file, got %d", ds.Name, res.count, len(specs))
+		}
+
+		data, err := ioutil.ReadFile(filepath.Join(app.Setup.OutputDir, ds.OutputFile+".meta.json"))
+		if err != nil {
+			t.Errorf("%s: %v", ds.Name, err)
+			continue
+		}
+		prov := provenance{}
+		err = json.Unmarshal(data, &prov)
+		switch {
+		case err != nil:
+			t.Errorf("%s: invalid provenance: %v", ds.Name, err)
+		case prov.Parser != ds.Name:
+			t.Errorf("%s: provenance Parser should be %q, got %q", ds.Name, ds.Name, prov.Parser)
+		case prov.Rows != res.count:
+			t.Errorf("%s: provenance Rows should be %d, got %d", ds.Name, res.count, prov.Rows)
+		case prov.Source == "":
+			t.Errorf("%s: provenance Source is empty", ds.Name)
+		}
+	}
+
+	// unreadable previous output blocks update only when churn is checked
+	ds := app.Resources[0]
+	fnameDst := filepath.Join(app.Setup.OutputDir, ds.OutputFile)
+	if err := ioutil.WriteFile(fnameDst, []byte("symbol;name;security\ncorrupted\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if res := getNparse(context.Background(), app, ds); res.err == nil {
+		t.Errorf("%s: corrupted previous output with max-churn should have an error", ds.Name)
+	}
+	ds.MaxChurn = 0
+	if res := getNparse(context.Background(), app, ds); res.err != nil {
+		t.Errorf("%s: corrupted previous output without max-churn: unexpected error: %v", ds.Name, res.err)
+	}
+
+	// cached response of different request is not used
+	ds = app.Resources[0]
+	ds.Section++
+	if res := getNparse(context.Background(), app, ds); res.err == nil {
+		t.Errorf("%s: offline mode with mismatched cache should have an error", ds.Name)
+	}
+
+	// missing cache
+	app.cache = respCache{dir: filepath.Join(dir, "nonexistent")}
+	if res := getNparse(context.Background(), app, app.Resources[0]); res.err == nil {
+		t.Errorf("offline mode without cache should have an error")
+	}
+}
+
+func TestMkURL(t *testing.T) {
+	conf := Config{Setup: Setup{WikiAPI: "https://en.wikipedia.org/w/api.php"}}
+	ds := DataSrc{Name: "NDX", PageName: "NASDAQ-100", Section: 9}
+
+	u, err := mkURL(conf, ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("page") != ds.PageName || q.Get("oldid") != "" {
+		t.Errorf("latest revision URL should use page, got %s", u.String())
+	}
+
+	ds.OldID = 123
+	u, err = mkURL(conf, ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q = u.Query()
+	if q.Get("oldid") != "123" || q.Get("page") != "" {
+		t.Errorf("pinned revision URL should use oldid, got %s", u.String())
+	}
+
+	link := permalink(conf, ds.PageName, ds.OldID)
+	expected := "https://en.wikipedia.org/w/index.php?oldid=123&title=NASDAQ-100"
+	if link != expected {
+		t.Errorf("permalink should be %s, got %s", expected, link)
+	}
+}
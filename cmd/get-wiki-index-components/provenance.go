@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/profioss/trada/pkg/osutil"
+	"github.com/profioss/trada/pkg/wiki"
+)
+
+// provenance describes origin of generated components file.
+// It is stored as sidecar <output-file>.meta.json file.
+type provenance struct {
+	Source       string    `json:"source"` // permanent link to page revision
+	Page         string    `json:"page"`
+	PageID       int64     `json:"page-id"`
+	Section      int       `json:"section"`
+	RevisionID   int64     `json:"revision-id"`
+	RevisionTime time.Time `json:"revision-time"`
+	Pinned       bool      `json:"pinned"` // revision is pinned by DataSrc.OldID
+	Fetched      time.Time `json:"fetched"`
+	Parser       string    `json:"parser"`
+	Rows         int       `json:"rows"`
+}
+
+func newProvenance(conf Config, ds DataSrc, wd wiki.Data, fetched time.Time) provenance {
+	return provenance{
+		Source:     permalink(conf, ds.PageName, wd.Parsed.RevID),
+		Page:       wd.Parsed.Title,
+		PageID:     wd.Parsed.PageID,
+		Section:    ds.Section,
+		RevisionID: wd.Parsed.RevID,
+		Pinned:     ds.OldID > 0,
+		Fetched:    fetched,
+		Parser:     ds.Name,
+	}
+}
+
+// permalink creates URL of wiki page revision
+// e.g. https://en.wikipedia.org/w/index.php?title=NASDAQ-100&oldid=123
+// Page URL is returned if revision is unknown.
+func permalink(conf Config, page string, revID int64) string {
+	u, err := url.Parse(conf.Setup.WikiAPI)
+	if err != nil {
+		return ""
+	}
+	u.Path = path.Join(path.Dir(u.Path), "index.php")
+
+	q := url.Values{}
+	q.Set("title", page)
+	if revID > 0 {
+		q.Set("oldid", strconv.FormatInt(revID, 10))
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+// saveProvenance writes provenance as sidecar file of fpath.
+func saveProvenance(fpath string, prov provenance) error {
+	data, err := json.MarshalIndent(prov, "", "  ")
+	if err != nil {
+		return fmt.Errorf("provenance: %v", err)
+	}
+
+	return osutil.WriteFile(fpath+".meta.json", append(data, '\n'))
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// result is outcome of processing single resource.
+type result struct {
+	ds     DataSrc
+	parsed bool // components were parsed; count is valid
+	count  int
+	checks []checkFailure
+	err    error
+}
+
+// minCntStatus describes result of DataSrc.MinCnt check.
+func (r result) minCntStatus() string {
+	switch {
+	case !r.parsed:
+		return "-"
+	case r.count < r.ds.MinCnt:
+		return fmt.Sprintf("FAIL (< %d)", r.ds.MinCnt)
+	}
+	return "OK"
+}
+
+// checksStatus describes result of all sanity checks.
+func (r result) checksStatus() string {
+	switch {
+	case !r.parsed:
+		return "-"
+	case len(r.checks) > 0:
+		return "FAIL (" + checkNames(r.checks) + ")"
+	}
+	return "OK"
+}
+
+func (r result) status() string {
+	if r.err != nil {
+		return r.err.Error()
+	}
+	return "OK"
+}
+
+// printSummary writes table of processed resources.
+func printSummary(w io.Writer, results []result) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "RESOURCE\tCOMPONENTS\tMIN-CNT\tCHECKS\tSTATUS")
+	for _, r := range results {
+		cnt := "-"
+		if r.parsed {
+			cnt = fmt.Sprintf("%d", r.count)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			r.ds.Name, cnt, r.minCntStatus(), r.checksStatus(), r.status())
+	}
+	tw.Flush()
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/profioss/clog"
+)
+
+// App defines application.
+type App struct {
+	Config
+	history *history
+	log     clog.Logger
+	logFile *os.File
+}
+
+// Close finishes App by closing open resources.
+func (a *App) Close() {
+	a.logFile.Close()
+}
+
+// Validate checks if App is valid.
+func (a App) Validate() error {
+	switch {
+	case a.Config.Validate() != nil:
+		return fmt.Errorf("config validation error: %s", a.Config.Validate())
+
+	case a.history == nil:
+		return fmt.Errorf("history is not initialized")
+
+	case a.log == nil:
+		return fmt.Errorf("log is not initialized")
+	}
+
+	return nil
+}
+
+// newApp creates new App.
+func newApp() (App, error) {
+	app := App{}
+
+	conf, err := initConfig(initSettings())
+	if err != nil {
+		return app, err
+	}
+	app.Config = conf
+
+	app.history = newHistory(conf.Setup.StateDir)
+
+	logf, err := clog.OpenFile(conf.Setup.LogFile)
+	if err != nil {
+		return app, err
+	}
+	app.logFile = logf
+	logger, err := clog.New(logf, conf.Setup.LogLevel, conf.verbose)
+	if err != nil {
+		return app, err
+	}
+	app.log = logger
+
+	return app, app.Validate()
+}
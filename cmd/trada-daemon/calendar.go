@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// calendar decides which days are trading days.
+type calendar interface {
+	isTradingDay(t time.Time) bool
+}
+
+// calendarFromString provides calendar by name.
+func calendarFromString(name string) (calendar, error) {
+	switch strings.ToLower(name) {
+	case "", "weekdays":
+		return weekdays{}, nil
+	case "nyse", "nasdaq", "us":
+		return nyse{}, nil
+	case "crypto", "all":
+		return allDays{}, nil
+	}
+
+	return nil, fmt.Errorf("unknown calendar %q; use one of: weekdays, nyse, crypto", name)
+}
+
+// weekdays calendar trades Monday - Friday.
+type weekdays struct{}
+
+func (weekdays) isTradingDay(t time.Time) bool {
+	wd := t.Weekday()
+	return wd != time.Saturday && wd != time.Sunday
+}
+
+// allDays calendar trades every day e.g. crypto markets.
+type allDays struct{}
+
+func (allDays) isTradingDay(time.Time) bool { return true }
+
+// nyse calendar trades on weekdays except regular NYSE holidays.
+// Unscheduled closures (e.g. national days of mourning) are not known.
+type nyse struct{}
+
+func (nyse) isTradingDay(t time.Time) bool {
+	if !(weekdays{}).isTradingDay(t) {
+		return false
+	}
+
+	y, m, d := t.Date()
+	for _, h := range nyseHolidays(y) {
+		if h.Month() == m && h.Day() == d {
+			return false
+		}
+	}
+
+	return true
+}
+
+// nyseHolidays provides observed NYSE holidays of the year.
+func nyseHolidays(year int) []time.Time {
+	date := func(m time.Month, d int) time.Time {
+		return time.Date(year, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	// holidays falling on Saturday are observed on Friday,
+	// on Sunday on Monday
+	observed := func(t time.Time) time.Time {
+		switch t.Weekday() {
+		case time.Saturday:
+			return t.AddDate(0, 0, -1)
+		case time.Sunday:
+			return t.AddDate(0, 0, 1)
+		}
+		return t
+	}
+
+	output := []time.Time{}
+
+	// New Year's Day on Saturday is not observed on Friday Dec 31
+	newYear := date(time.January, 1)
+	if newYear.Weekday() != time.Saturday {
+		output = append(output, observed(newYear))
+	}
+
+	output = append(output,
+		nthWeekday(year, time.January, time.Monday, 3),  // Martin Luther King Jr. Day
+		nthWeekday(year, time.February, time.Monday, 3), // Washington's Birthday
+		easter(year).AddDate(0, 0, -2),                  // Good Friday
+		lastWeekday(year, time.May, time.Monday),        // Memorial Day
+	)
+	if year >= 2022 {
+		output = append(output, observed(date(time.June, 19))) // Juneteenth
+	}
+	output = append(output,
+		observed(date(time.July, 4)),                      // Independence Day
+		nthWeekday(year, time.September, time.Monday, 1),  // Labor Day
+		nthWeekday(year, time.November, time.Thursday, 4), // Thanksgiving Day
+		observed(date(time.December, 25)),                 // Christmas Day
+	)
+
+	return output
+}
+
+// nthWeekday provides n-th weekday of month.
+func nthWeekday(year int, month time.Month, wd time.Weekday, n int) time.Time {
+	t := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	shift := (int(wd) - int(t.Weekday()) + 7) % 7
+	return t.AddDate(0, 0, shift+7*(n-1))
+}
+
+// lastWeekday provides last weekday of month.
+func lastWeekday(year int, month time.Month, wd time.Weekday) time.Time {
+	t := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+	shift := (int(t.Weekday()) - int(wd) + 7) % 7
+	return t.AddDate(0, 0, -shift)
+}
+
+// easter provides Easter Sunday of Gregorian calendar
+// (anonymous Gregorian algorithm).
+func easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
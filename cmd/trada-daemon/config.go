@@ -0,0 +1,230 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	toml "github.com/pelletier/go-toml"
+	"github.com/profioss/clog"
+)
+
+// Config is main configuration.
+type Config struct {
+	Setup Setup `toml:"setup"`
+	Jobs  []Job `toml:"jobs"`
+
+	// cmd line flags, not part of the config file
+	path    string
+	run     string
+	list    bool
+	last    int
+	verbose bool
+}
+
+// Validate checks if Config is valid.
+func (c Config) Validate() error {
+	switch {
+	case c.Setup.Validate() != nil:
+		return fmt.Errorf("Config: %s", c.Setup.Validate())
+
+	case len(c.Jobs) == 0:
+		return errors.New("Config: empty Jobs definition")
+	}
+
+	names := map[string]bool{}
+	for _, j := range c.Jobs {
+		if j.Validate() != nil {
+			return fmt.Errorf("Config: %s", j.Validate())
+		}
+		if names[j.Name] {
+			return fmt.Errorf("Config: duplicate Job %q", j.Name)
+		}
+		names[j.Name] = true
+	}
+
+	if c.run != "" {
+		if _, ok := c.job(c.run); !ok {
+			return fmt.Errorf("Config: unknown Job %q", c.run)
+		}
+	}
+
+	return nil
+}
+
+// job provides Job by name.
+func (c Config) job(name string) (Job, bool) {
+	for _, j := range c.Jobs {
+		if j.Name == name {
+			return j, true
+		}
+	}
+	return Job{}, false
+}
+
+// Setup defines command setup.
+type Setup struct {
+	StateDir    string        `toml:"state-dir"`    // locks and job history
+	KillTimeout time.Duration `toml:"kill-timeout"` // after SIGTERM of cancelled job
+	OutputTail  int           `toml:"output-tail"`  // bytes of output of failed job kept in history
+	LogFile     string        `toml:"log-file"`
+	LogLevel    string        `toml:"log-level"`
+}
+
+// Validate checks if Setup is valid.
+func (s Setup) Validate() error {
+	switch {
+	case s.StateDir == "":
+		return errors.New("Setup: StateDir is not specified")
+
+	case s.KillTimeout < 1:
+		return errors.New("Setup: KillTimeout is set too low")
+
+	case s.OutputTail < 0:
+		return errors.New("Setup: OutputTail is < 0")
+	}
+
+	// LogLevel and LogFile can be empty, safe defaults are used in initConfig()
+
+	return nil
+}
+
+// Job defines scheduled command.
+type Job struct {
+	Name     string        `toml:"name"`
+	Command  string        `toml:"command"`
+	Args     []string      `toml:"args"`
+	Dir      string        `toml:"dir"` // working directory, current if empty
+	Schedule string        `toml:"schedule"`
+	Timezone string        `toml:"timezone"` // of schedule clock time, local if empty
+	Calendar string        `toml:"calendar"` // trading days calendar
+	Lock     string        `toml:"lock"`     // jobs sharing lock never run concurrently
+	Timeout  time.Duration `toml:"timeout"`  // 0 means no timeout
+	Disabled bool          `toml:"disabled"`
+
+	sched schedule
+}
+
+// Validate checks if Job is valid.
+func (j Job) Validate() error {
+	switch {
+	case j.Name == "":
+		return errors.New("Job: Name is not specified")
+
+	case j.Command == "":
+		return fmt.Errorf("Job %s: Command is not specified", j.Name)
+
+	case j.Timeout < 0:
+		return fmt.Errorf("Job %s: Timeout is < 0", j.Name)
+	}
+
+	if _, err := j.mkSchedule(); err != nil {
+		return fmt.Errorf("Job %s: %v", j.Name, err)
+	}
+
+	return nil
+}
+
+// mkSchedule creates schedule of Job.
+func (j Job) mkSchedule() (schedule, error) {
+	loc := time.Local
+	if j.Timezone != "" {
+		l, err := time.LoadLocation(j.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Timezone: %v", err)
+		}
+		loc = l
+	}
+
+	cal, err := calendarFromString(j.Calendar)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSchedule(j.Schedule, loc, cal)
+}
+
+// lockName provides name of Job lock.
+func (j Job) lockName() string {
+	if j.Lock != "" {
+		return j.Lock
+	}
+	return j.Name
+}
+
+func initSettings() Config {
+	cfg := Config{}
+
+	flag.StringVar(&cfg.path, "c", "config/trada-daemon.toml", "config file")
+	flag.StringVar(&cfg.Setup.LogLevel, "log-level", "", "log levels: disabled | error | warning | info | debug")
+	flag.StringVar(&cfg.run, "run", "", "run the job now and exit")
+	flag.BoolVar(&cfg.list, "list", false, "list jobs with their next run and exit")
+	flag.IntVar(&cfg.last, "history", 0, "print last n records of job history and exit (with -run only of the job)")
+	flag.BoolVar(&cfg.verbose, "v", false, "verbose mode")
+	flag.Parse()
+
+	return cfg
+}
+
+func initConfig(settings Config) (Config, error) {
+	var conf Config
+
+	if settings.path == "" {
+		return conf, fmt.Errorf("empty config path")
+	}
+	fd, err := os.Open(settings.path)
+	if err != nil {
+		return conf, fmt.Errorf("config open file error: %v", err)
+	}
+	defer fd.Close()
+
+	err = toml.NewDecoder(fd).Decode(&conf)
+	if err != nil {
+		return conf, fmt.Errorf("config %s parse error: %v", settings.path, err)
+	}
+
+	conf.path = settings.path
+	conf.run = settings.run
+	conf.list = settings.list
+	conf.last = settings.last
+	conf.verbose = settings.verbose
+
+	conf.Setup.KillTimeout = time.Duration(conf.Setup.KillTimeout) * time.Second
+	if conf.Setup.KillTimeout == 0 {
+		conf.Setup.KillTimeout = 30 * time.Second
+	}
+	if conf.Setup.OutputTail == 0 {
+		conf.Setup.OutputTail = 4096
+	}
+	for i := range conf.Jobs {
+		conf.Jobs[i].Timeout = time.Duration(conf.Jobs[i].Timeout) * time.Second
+	}
+
+	// default log level
+	// log levels: disabled | error | warning | info | debug
+	if conf.Setup.LogLevel == "" {
+		conf.Setup.LogLevel = "info"
+	}
+	if settings.Setup.LogLevel != "" {
+		conf.Setup.LogLevel = settings.Setup.LogLevel
+	}
+	_, err = clog.LevelFromString(conf.Setup.LogLevel)
+	if err != nil {
+		return conf, fmt.Errorf("invalid log level: %v", err)
+	}
+	// disable logging if no log file was specified
+	if conf.Setup.LogFile == "" {
+		conf.Setup.LogLevel = "disabled"
+	}
+
+	if err := conf.Validate(); err != nil {
+		return conf, err
+	}
+	for i := range conf.Jobs {
+		conf.Jobs[i].sched, _ = conf.Jobs[i].mkSchedule() // validated
+	}
+
+	return conf, nil
+}
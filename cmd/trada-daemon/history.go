@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/profioss/trada/pkg/osutil"
+)
+
+// Status of job run.
+type Status string
+
+// Status values.
+const (
+	StatusOK        Status = "ok"
+	StatusFailed    Status = "failed"
+	StatusTimeout   Status = "timeout"
+	StatusCancelled Status = "cancelled" // daemon was stopped
+	StatusSkipped   Status = "skipped"   // previous run still holds the lock
+)
+
+// record is job history record.
+type record struct {
+	Job      string        `json:"job"`
+	Start    time.Time     `json:"start"`
+	End      time.Time     `json:"end"`
+	Duration time.Duration `json:"duration"`
+	Status   Status        `json:"status"`
+	ExitCode int           `json:"exit-code"`
+	Error    string        `json:"error,omitempty"`
+	Output   string        `json:"output,omitempty"` // tail of output of unsuccessful run
+}
+
+// history is append only job history stored as JSON lines.
+type history struct {
+	path string
+	mu   sync.Mutex
+}
+
+func newHistory(dir string) *history {
+	return &history{path: filepath.Join(dir, "history.jsonl")}
+}
+
+// add appends record to history.
+func (h *history) add(r record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(h.path), osutil.DirPerms); err != nil {
+		return err
+	}
+	fd, err := os.OpenFile(h.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, osutil.FilePerms)
+	if err != nil {
+		return err
+	}
+	_, err = fd.Write(append(data, '\n'))
+	if errClose := fd.Close(); err == nil {
+		err = errClose
+	}
+
+	return err
+}
+
+// last provides last n records (all if n < 1) of job (all jobs if empty).
+func (h *history) last(n int, job string) ([]record, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	output := []record{}
+	fd, err := os.Open(h.path)
+	if os.IsNotExist(err) {
+		return output, nil
+	}
+	if err != nil {
+		return output, err
+	}
+	defer fd.Close()
+
+	sc := bufio.NewScanner(fd)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		r := record{}
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			return output, fmt.Errorf("%s:%d: %v", h.path, line, err)
+		}
+		if job != "" && r.Job != job {
+			continue
+		}
+		output = append(output, r)
+	}
+	if err := sc.Err(); err != nil {
+		return output, err
+	}
+
+	if n > 0 && len(output) > n {
+		output = output[len(output)-n:]
+	}
+
+	return output, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// runJob runs job if its lock is free and provides history record.
+func runJob(ctx context.Context, app App, j Job) record {
+	rec := record{Job: j.Name, Start: time.Now().UTC(), ExitCode: -1}
+	finish := func(st Status, err error) record {
+		rec.End = time.Now().UTC()
+		rec.Duration = rec.End.Sub(rec.Start)
+		rec.Status = st
+		if err != nil {
+			rec.Error = err.Error()
+		}
+		return rec
+	}
+
+	lock, err := acquireLock(app.Setup.StateDir, j.lockName())
+	if err != nil {
+		return finish(StatusSkipped, err)
+	}
+	defer lock.release()
+
+	var runCtx context.Context
+	var cancel context.CancelFunc
+	if j.Timeout > 0 {
+		runCtx, cancel = context.WithTimeout(ctx, j.Timeout)
+	} else {
+		runCtx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	app.log.Infof("%s: starting %s", j.Name, j.Command)
+	out := &tailBuffer{max: app.Setup.OutputTail}
+	rec.ExitCode, err = runCmd(runCtx, j, out, app.Setup.KillTimeout)
+
+	st := StatusOK
+	switch {
+	case err == nil:
+	case ctx.Err() != nil:
+		st = StatusCancelled
+	case runCtx.Err() == context.DeadlineExceeded:
+		st = StatusTimeout
+		err = fmt.Errorf("timeout %s exceeded: %v", j.Timeout, err)
+	default:
+		st = StatusFailed
+	}
+	if st != StatusOK {
+		rec.Output = out.String()
+	}
+
+	return finish(st, err)
+}
+
+// runCmd runs job command writing its output to out.
+// Cancelled command gets SIGTERM and it is killed
+// if it doesn't finish within killTimeout.
+func runCmd(ctx context.Context, j Job, out io.Writer, killTimeout time.Duration) (int, error) {
+	cmd := exec.Command(j.Command, j.Args...)
+	cmd.Dir = j.Dir
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	if err := cmd.Start(); err != nil {
+		return -1, err
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		return exitCode(cmd, err), err
+
+	case <-ctx.Done():
+		cmd.Process.Signal(syscall.SIGTERM)
+	}
+
+	timer := time.NewTimer(killTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		if err == nil {
+			err = ctx.Err()
+		}
+		return exitCode(cmd, err), err
+
+	case <-timer.C:
+		cmd.Process.Kill()
+		<-done
+		return -1, fmt.Errorf("killed after %s: %v", killTimeout, ctx.Err())
+	}
+}
+
+func exitCode(cmd *exec.Cmd, err error) int {
+	var exitErr *exec.ExitError
+	if err == nil || errors.As(err, &exitErr) {
+		return cmd.ProcessState.ExitCode()
+	}
+	return -1
+}
+
+// tailBuffer keeps last max bytes written.
+type tailBuffer struct {
+	max int
+	buf []byte
+}
+
+// Write implements io.Writer.
+func (tb *tailBuffer) Write(p []byte) (int, error) {
+	tb.buf = append(tb.buf, p...)
+	if over := len(tb.buf) - tb.max; over > 0 {
+		tb.buf = append(tb.buf[:0], tb.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+func (tb *tailBuffer) String() string {
+	return string(tb.buf)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/profioss/clog"
+)
+
+func mkTestApp(t *testing.T, dir string) App {
+	logger, err := clog.New(ioutil.Discard, "disabled", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setup := Setup{StateDir: dir, KillTimeout: time.Second, OutputTail: 16}
+	return App{Config: Config{Setup: setup}, history: newHistory(dir), log: logger}
+}
+
+func TestRunJob(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trada-daemon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	app := mkTestApp(t, dir)
+
+	tests := []struct {
+		label    string
+		job      Job
+		status   Status
+		exitCode int
+		output   string
+	}{
+		{
+			label:  "ok",
+			job:    Job{Name: "ok", Command: "sh", Args: []string{"-c", "echo hello"}},
+			status: StatusOK,
+		},
+		{
+			label:    "failed",
+			job:      Job{Name: "failed", Command: "sh", Args: []string{"-c", "echo 0123456789abcdefXYZ; exit 3"}},
+			status:   StatusFailed,
+			exitCode: 3,
+			output:   "456789abcdefXYZ\n",
+		},
+		{
+			label:    "timeout",
+			job:      Job{Name: "timeout", Command: "sleep", Args: []string{"10"}, Timeout: 50 * time.Millisecond},
+			status:   StatusTimeout,
+			exitCode: -1,
+		},
+		{
+			label:    "missing command",
+			job:      Job{Name: "missing", Command: "./no-such-command"},
+			status:   StatusFailed,
+			exitCode: -1,
+		},
+	}
+
+	for _, tc := range tests {
+		rec := runAndRecord(context.Background(), app, tc.job)
+		switch {
+		case rec.Status != tc.status:
+			t.Errorf("%s - status should be %s, got %s (%s)", tc.label, tc.status, rec.Status, rec.Error)
+		case rec.ExitCode != tc.exitCode:
+			t.Errorf("%s - exit code should be %d, got %d", tc.label, tc.exitCode, rec.ExitCode)
+		case tc.output != "" && rec.Output != tc.output:
+			t.Errorf("%s - output should be %q, got %q", tc.label, tc.output, rec.Output)
+		}
+	}
+
+	// overlapping run is skipped
+	lock, err := acquireLock(dir, "shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := runAndRecord(context.Background(), app, Job{Name: "locked", Command: "true", Lock: "shared"})
+	if rec.Status != StatusSkipped {
+		t.Errorf("locked - status should be %s, got %s", StatusSkipped, rec.Status)
+	}
+	lock.release()
+
+	// stale lock of dead process is taken over
+	err = ioutil.WriteFile(lock.path, []byte("999999999\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec = runAndRecord(context.Background(), app, Job{Name: "stale", Command: "true", Lock: "shared"})
+	if rec.Status != StatusOK {
+		t.Errorf("stale lock - status should be %s, got %s (%s)", StatusOK, rec.Status, rec.Error)
+	}
+
+	recs, err := app.history.last(0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != len(tests)+2 {
+		t.Fatalf("history should have %d records, got %d", len(tests)+2, len(recs))
+	}
+	recs, err = app.history.last(1, "failed")
+	switch {
+	case err != nil:
+		t.Errorf("history - unexpected error: %v", err)
+	case len(recs) != 1 || recs[0].Job != "failed" || !strings.Contains(recs[0].Output, "XYZ"):
+		t.Errorf("history - unexpected records: %+v", recs)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/profioss/trada/pkg/osutil"
+)
+
+// lockFile prevents overlapping runs of jobs sharing the lock,
+// also across processes (e.g. daemon and manual run).
+// The file contains PID of the owner; lock of dead process is stale
+// and it is taken over. Lock without valid PID is held for lockGrace.
+type lockFile struct {
+	path string
+}
+
+// acquireLock creates lock name in directory dir.
+func acquireLock(dir, name string) (lockFile, error) {
+	lf := lockFile{path: filepath.Join(dir, name+".lock")}
+	if err := os.MkdirAll(dir, osutil.DirPerms); err != nil {
+		return lf, err
+	}
+
+	for i := 0; i < 2; i++ {
+		fd, err := os.OpenFile(lf.path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, osutil.FilePerms)
+		if err == nil {
+			_, err = fmt.Fprintf(fd, "%d\n", os.Getpid())
+			fd.Close()
+			if err != nil {
+				os.Remove(lf.path)
+			}
+			return lf, err
+		}
+		if !os.IsExist(err) {
+			return lf, err
+		}
+
+		pid, err := lf.owner()
+		switch {
+		case os.IsNotExist(err): // released meanwhile
+			continue
+		case err != nil && !lf.expired():
+			// owner may not have written its PID yet
+			return lf, fmt.Errorf("lock %s is held: %v", name, err)
+		case err == nil && processAlive(pid):
+			return lf, fmt.Errorf("lock %s is held by process %d", name, pid)
+		}
+		// stale lock
+		if err := os.Remove(lf.path); err != nil && !os.IsNotExist(err) {
+			return lf, err
+		}
+	}
+
+	return lf, fmt.Errorf("lock %s can not be acquired", name)
+}
+
+// lockGrace is time given to the owner to write its PID to the lock.
+const lockGrace = 10 * time.Second
+
+// expired checks if lock without valid PID is older than lockGrace.
+func (lf lockFile) expired() bool {
+	fi, err := os.Stat(lf.path)
+	if err != nil {
+		return false
+	}
+	return time.Since(fi.ModTime()) > lockGrace
+}
+
+// release removes the lock.
+func (lf lockFile) release() error {
+	return os.Remove(lf.path)
+}
+
+// owner provides PID of lock owner.
+func (lf lockFile) owner() (int, error) {
+	data, err := ioutil.ReadFile(lf.path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// processAlive checks if process pid exists.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
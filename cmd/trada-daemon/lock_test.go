@@ -0,0 +1,57 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquireLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trada-daemon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	old := time.Now().Add(-2 * lockGrace)
+	tests := []struct {
+		label  string
+		data   string
+		mtime  time.Time // zero means now
+		hasErr bool
+	}{
+		{label: "live process", data: "1\n", hasErr: true},
+		{label: "dead process", data: "999999999\n"},
+		{label: "PID not written yet", data: "", hasErr: true},
+		{label: "unparsable PID", data: "xyz", hasErr: true},
+		{label: "empty lock expired", data: "", mtime: old},
+		{label: "unparsable PID expired", data: "xyz", mtime: old},
+	}
+
+	for _, tc := range tests {
+		fpath := filepath.Join(dir, "test.lock")
+		if err := ioutil.WriteFile(fpath, []byte(tc.data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if !tc.mtime.IsZero() {
+			if err := os.Chtimes(fpath, tc.mtime, tc.mtime); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		lf, err := acquireLock(dir, "test")
+		switch {
+		case tc.hasErr && err == nil:
+			t.Errorf("%s - should have an error", tc.label)
+		case !tc.hasErr && err != nil:
+			t.Errorf("%s - unexpected error: %v", tc.label, err)
+		case !tc.hasErr:
+			if pid, err := lf.owner(); err != nil || pid != os.Getpid() {
+				t.Errorf("%s - lock owner should be %d, got %d (%v)", tc.label, os.Getpid(), pid, err)
+			}
+		}
+		os.Remove(fpath)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+func main() {
+	exitCode := 0
+	wg := sync.WaitGroup{}
+
+	app, err := newApp()
+	if err != nil {
+		log.Fatal("App init error: ", err)
+	}
+	defer app.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGTERM, os.Interrupt)
+
+	// common cleanup
+	defer func() {
+		signal.Stop(sigChan)
+		cancel()
+		cleanup(app)
+		wg.Wait()
+		os.Exit(exitCode)
+	}()
+
+	wg.Add(1)
+	go func() {
+		select {
+		case s := <-sigChan:
+			app.log.Warnf("Got %s signal - exitting", s)
+			cancel()
+			app.log.Info("Stopped")
+		case <-ctx.Done():
+			app.log.Info("DONE")
+		}
+		wg.Done()
+	}()
+
+	app.log.Info("Starting")
+	err = do(ctx, app)
+	if err != nil {
+		exitCode = 1
+		app.log.Errorf("Daemon error: %s", err)
+		return
+	}
+}
+
+func do(ctx context.Context, app App) error {
+	switch {
+	case app.last > 0:
+		recs, err := app.history.last(app.last, app.run)
+		if err != nil {
+			return err
+		}
+		printHistory(os.Stdout, recs)
+		return nil
+
+	case app.list:
+		printJobs(os.Stdout, app.Jobs, time.Now())
+		return nil
+
+	case app.run != "":
+		j, _ := app.job(app.run) // validated
+		rec := runAndRecord(ctx, app, j)
+		printHistory(os.Stdout, []record{rec})
+		if rec.Status != StatusOK {
+			return fmt.Errorf("job %s %s", j.Name, rec.Status)
+		}
+		return nil
+	}
+
+	wg := sync.WaitGroup{}
+	for _, j := range app.Jobs {
+		if j.Disabled {
+			app.log.Infof("%s: disabled", j.Name)
+			continue
+		}
+		wg.Add(1)
+		go func(j Job) {
+			defer wg.Done()
+			loop(ctx, app, j)
+		}(j)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// loop runs job according to its schedule until ctx is cancelled.
+// Runs missed while the job was running are skipped.
+func loop(ctx context.Context, app App, j Job) {
+	for {
+		next := j.sched.next(time.Now())
+		if next.IsZero() {
+			app.log.Errorf("%s: no next run of schedule %s", j.Name, j.sched)
+			return
+		}
+		app.log.Infof("%s: next run at %s", j.Name, next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		runAndRecord(ctx, app, j)
+	}
+}
+
+// runAndRecord runs job and stores the run to history.
+func runAndRecord(ctx context.Context, app App, j Job) record {
+	rec := runJob(ctx, app, j)
+	switch rec.Status {
+	case StatusOK:
+		app.log.Infof("%s: %s in %s", j.Name, rec.Status, rec.Duration)
+	case StatusSkipped:
+		app.log.Warnf("%s: %s: %s", j.Name, rec.Status, rec.Error)
+	default:
+		app.log.Errorf("%s: %s in %s (exit code %d): %s", j.Name, rec.Status, rec.Duration, rec.ExitCode, rec.Error)
+	}
+
+	if err := app.history.add(rec); err != nil {
+		app.log.Errorf("%s: history error: %s", j.Name, err)
+	}
+
+	return rec
+}
+
+func cleanup(app App) {
+	app.log.Info("Cleaning up...")
+}
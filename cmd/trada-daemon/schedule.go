@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schedule provides run times of a job.
+type schedule interface {
+	// next provides the first run time after given time.
+	// Zero time means there is no next run.
+	next(after time.Time) time.Time
+	String() string
+}
+
+// maxScheduleDays limits search for the next run of clock schedules.
+const maxScheduleDays = 366
+
+// parseSchedule parses schedule definition:
+//
+//	every <duration>       e.g. "every 1h", "every 15m"
+//	daily <hh:mm>          every day
+//	trading-days <hh:mm>   trading days of calendar cal
+//	weekly <day> <hh:mm>   e.g. "weekly sat 06:00"
+//
+// Clock times are in location loc.
+// Intervals are aligned to multiples of the duration since zero time
+// so "every 1h" runs at the beginning of each (UTC) hour.
+func parseSchedule(spec string, loc *time.Location, cal calendar) (schedule, error) {
+	fields := strings.Fields(strings.ToLower(spec))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty schedule")
+	}
+
+	switch {
+	case fields[0] == "every" && len(fields) == 2:
+		d, err := time.ParseDuration(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %v", spec, err)
+		}
+		if d < time.Minute {
+			return nil, fmt.Errorf("schedule %q: interval is < 1m", spec)
+		}
+		return interval{d: d}, nil
+
+	case fields[0] == "daily" && len(fields) == 2:
+		return mkClock(spec, fields[1], loc, allDays{}.isTradingDay)
+
+	case fields[0] == "trading-days" && len(fields) == 2:
+		return mkClock(spec, fields[1], loc, cal.isTradingDay)
+
+	case fields[0] == "weekly" && len(fields) == 3:
+		wd, err := weekdayFromString(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %v", spec, err)
+		}
+		return mkClock(spec, fields[2], loc, func(t time.Time) bool { return t.Weekday() == wd })
+	}
+
+	return nil, fmt.Errorf("schedule %q is not valid; use: every <duration> | daily <hh:mm> | "+
+		"trading-days <hh:mm> | weekly <day> <hh:mm>", spec)
+}
+
+// interval schedule runs in regular intervals.
+type interval struct {
+	d time.Duration
+}
+
+func (i interval) next(after time.Time) time.Time {
+	return after.Truncate(i.d).Add(i.d)
+}
+
+func (i interval) String() string {
+	return "every " + i.d.String()
+}
+
+// clock schedule runs at given time of matching days.
+type clock struct {
+	spec   string
+	hour   int
+	minute int
+	loc    *time.Location
+	match  func(time.Time) bool
+}
+
+func mkClock(spec, hhmm string, loc *time.Location, match func(time.Time) bool) (clock, error) {
+	c := clock{spec: spec, loc: loc, match: match}
+
+	parts := strings.Split(hhmm, ":")
+	if len(parts) != 2 {
+		return c, fmt.Errorf("schedule %q: invalid time %q; use hh:mm", spec, hhmm)
+	}
+	h, errH := strconv.Atoi(parts[0])
+	m, errM := strconv.Atoi(parts[1])
+	if errH != nil || errM != nil || h < 0 || h > 23 || m < 0 || m > 59 {
+		return c, fmt.Errorf("schedule %q: invalid time %q; use hh:mm", spec, hhmm)
+	}
+	c.hour, c.minute = h, m
+
+	return c, nil
+}
+
+func (c clock) next(after time.Time) time.Time {
+	y, m, d := after.In(c.loc).Date()
+	for i := 0; i <= maxScheduleDays; i++ {
+		t := time.Date(y, m, d+i, c.hour, c.minute, 0, 0, c.loc)
+		if t.After(after) && c.match(t) {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (c clock) String() string {
+	return c.spec + " " + c.loc.String()
+}
+
+// weekdayFromString parses day name like mon, monday.
+func weekdayFromString(s string) (time.Weekday, error) {
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		name := strings.ToLower(wd.String())
+		if s == name || s == name[:3] {
+			return wd, nil
+		}
+	}
+
+	return time.Sunday, fmt.Errorf("invalid day %q", s)
+}
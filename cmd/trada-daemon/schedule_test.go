@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNYSECalendar(t *testing.T) {
+	tests := []struct {
+		date    string
+		trading bool
+	}{
+		{date: "2019-12-24", trading: true},
+		{date: "2019-12-25"},                // Christmas
+		{date: "2019-12-28"},                // Saturday
+		{date: "2020-01-20"},                // MLK day
+		{date: "2020-04-10"},                // Good Friday
+		{date: "2020-07-03"},                // Independence Day observed
+		{date: "2021-12-31", trading: true}, // New Year's Day on Saturday is not observed
+		{date: "2022-06-20"},                // Juneteenth observed
+		{date: "2021-06-18", trading: true}, // before Juneteenth
+		{date: "2023-11-23"},                // Thanksgiving
+		{date: "2024-05-27"},                // Memorial Day
+		{date: "2024-09-02"},                // Labor Day
+		{date: "2024-03-29"},                // Good Friday
+	}
+
+	for _, tc := range tests {
+		d, err := time.Parse("2006-01-02", tc.date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := (nyse{}).isTradingDay(d); got != tc.trading {
+			t.Errorf("%s - trading day should be %v, got %v", tc.date, tc.trading, got)
+		}
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	ny := time.FixedZone("EST", -5*3600)
+	now := time.Date(2019, 12, 24, 20, 0, 0, 0, ny) // Tuesday
+
+	tests := []struct {
+		label  string
+		spec   string
+		cal    calendar
+		next   time.Time
+		hasErr bool
+	}{
+		{label: "interval", spec: "every 1h", next: time.Date(2019, 12, 25, 2, 0, 0, 0, time.UTC)},
+		{label: "daily", spec: "daily 06:30", next: time.Date(2019, 12, 25, 6, 30, 0, 0, ny)},
+		{label: "later today", spec: "daily 21:00", next: time.Date(2019, 12, 24, 21, 0, 0, 0, ny)},
+		{label: "trading days", spec: "trading-days 16:30", cal: nyse{},
+			next: time.Date(2019, 12, 26, 16, 30, 0, 0, ny)},
+		{label: "weekdays", spec: "Trading-Days 16:30", cal: weekdays{},
+			next: time.Date(2019, 12, 25, 16, 30, 0, 0, ny)},
+		{label: "weekly", spec: "weekly sat 06:00", next: time.Date(2019, 12, 28, 6, 0, 0, 0, ny)},
+		{label: "weekly long name", spec: "weekly Tuesday 20:00", next: time.Date(2019, 12, 31, 20, 0, 0, 0, ny)},
+		{label: "empty", spec: "", hasErr: true},
+		{label: "short interval", spec: "every 10s", hasErr: true},
+		{label: "invalid time", spec: "daily 24:00", hasErr: true},
+		{label: "invalid day", spec: "weekly xyz 06:00", hasErr: true},
+		{label: "unknown", spec: "monthly 1 06:00", hasErr: true},
+	}
+
+	for _, tc := range tests {
+		cal := tc.cal
+		if cal == nil {
+			cal = weekdays{}
+		}
+		s, err := parseSchedule(tc.spec, ny, cal)
+		switch {
+		case tc.hasErr && err == nil:
+			t.Errorf("%s - should have an error", tc.label)
+		case !tc.hasErr && err != nil:
+			t.Errorf("%s - unexpected error: %v", tc.label, err)
+		case tc.hasErr:
+		case !s.next(now).Equal(tc.next):
+			t.Errorf("%s - next run should be %s, got %s", tc.label, tc.next, s.next(now))
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// printJobs writes table of jobs with their next run after now.
+func printJobs(w io.Writer, jobs []Job, now time.Time) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "JOB\tSCHEDULE\tLOCK\tNEXT RUN")
+	for _, j := range jobs {
+		next := "disabled"
+		if !j.Disabled {
+			next = j.sched.next(now).Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", j.Name, j.sched, j.lockName(), next)
+	}
+	tw.Flush()
+}
+
+// printHistory writes table of job history records.
+func printHistory(w io.Writer, recs []record) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "JOB\tSTART\tDURATION\tSTATUS\tEXIT\tERROR")
+	for _, r := range recs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n", r.Job, r.Start.Format(time.RFC3339),
+			r.Duration.Round(time.Millisecond), r.Status, r.ExitCode, r.Error)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/profioss/clog"
+)
+
+// App defines application.
+type App struct {
+	Config
+	log     clog.Logger
+	logFile *os.File
+}
+
+// Close finishes App by closing open resources.
+func (a *App) Close() {
+	if a.logFile != nil {
+		a.logFile.Close()
+	}
+}
+
+// Validate checks if App is valid.
+func (a App) Validate() error {
+	switch {
+	case a.Config.Validate() != nil:
+		return fmt.Errorf("config validation error: %s", a.Config.Validate())
+
+	case a.log == nil:
+		return fmt.Errorf("log is not initialized")
+	}
+
+	return nil
+}
+
+// newApp creates new App.
+// Errors and warnings are written to stderr even without log file.
+func newApp() (App, error) {
+	app := App{}
+
+	conf, err := initConfig()
+	if err != nil {
+		return app, err
+	}
+	app.Config = conf
+
+	var logw io.Writer // nil means no log file
+	if conf.Setup.LogFile != "" {
+		logf, err := clog.OpenFile(conf.Setup.LogFile)
+		if err != nil {
+			return app, err
+		}
+		app.logFile = logf
+		logw = logf
+	}
+	logger, err := clog.New(logw, conf.Setup.LogLevel, conf.verbose)
+	if err != nil {
+		return app, err
+	}
+	app.log = logger
+
+	return app, app.Validate()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/ohlc/ohlcio"
+)
+
+// Config is main configuration set by command line flags.
+type Config struct {
+	Setup Setup
+
+	files   []string // input CSV files
+	verbose bool
+}
+
+// Validate checks if Config is valid.
+func (c Config) Validate() error {
+	switch {
+	case c.Setup.Validate() != nil:
+		return fmt.Errorf("Config: %s", c.Setup.Validate())
+
+	case len(c.files) == 0:
+		return errors.New("no input files specified")
+
+	case c.Setup.Symbol != "" && len(c.files) > 1:
+		return errors.New("-s flag can be used with single input file only")
+	}
+
+	return nil
+}
+
+// Setup defines command setup.
+type Setup struct {
+	Format    ohlcio.Format
+	Adjusted  bool
+	Symbol    string
+	Security  instrument.Security
+	Watchlist string
+	LogFile   string
+	LogLevel  string
+	OutputDir string
+}
+
+// Validate checks if Setup is valid.
+func (s Setup) Validate() error {
+	switch {
+	case s.OutputDir == "":
+		return errors.New("Setup: Output Directory is not specified")
+
+	case s.Security.Validate() != nil:
+		return fmt.Errorf("Setup: %s", s.Security.Validate())
+	}
+
+	return nil
+}
+
+func initConfig() (Config, error) {
+	optDirOut := flag.String("o", "", "output data directory")
+	optFormat := flag.String("format", "auto", "input format: auto | trada | stooq | stooq-ascii | yahoo")
+	optAdjusted := flag.Bool("adjusted", false, "adjust prices by adjusted close (yahoo format)")
+	optSymbol := flag.String("s", "", "symbol of single input file; derived from file name by default")
+	optSecurity := flag.String("security", "equity", "security type of imported data (price precision)")
+	optWatchlist := flag.String("w", "", "watchlist CSV with instrument specs (security type, precision)")
+	optLogFile := flag.String("log-file", "", "log file")
+	optLogLevel := flag.String("log-level", "info", "log levels: disabled | error | warning | info | debug")
+	optVerb := flag.Bool("v", false, "verbose mode")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] file.csv ...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	conf := Config{}
+	conf.verbose = *optVerb
+	conf.files = flag.Args()
+
+	format, err := ohlcio.FormatFromString(*optFormat)
+	if err != nil {
+		return conf, err
+	}
+	sec, err := instrument.SecurityFromString(*optSecurity)
+	if err != nil {
+		return conf, err
+	}
+
+	conf.Setup = Setup{
+		Format:    format,
+		Adjusted:  *optAdjusted,
+		Symbol:    *optSymbol,
+		Security:  sec,
+		Watchlist: *optWatchlist,
+		LogFile:   *optLogFile,
+		LogLevel:  *optLogLevel,
+		OutputDir: *optDirOut,
+	}
+
+	return conf, conf.Validate()
+}
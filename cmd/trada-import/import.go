@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/model/ohlc/ohlcio"
+	"github.com/profioss/trada/pkg/osutil"
+)
+
+// result is outcome of importing single file.
+type result struct {
+	file   string
+	format ohlcio.Format
+	symbol string
+	bars   []ohlc.OHLC
+	err    error
+}
+
+// importFiles imports all input files, returns results in input order.
+func importFiles(ctx context.Context, app App) ([]result, error) {
+	specs, err := loadSpecs(app)
+	if err != nil {
+		return nil, fmt.Errorf("loadSpecs failed %s", err)
+	}
+
+	results := make([]result, 0, len(app.Config.files))
+	for _, f := range app.Config.files {
+		select {
+		case <-ctx.Done():
+			return results, fmt.Errorf("operation cancelled")
+		default:
+		}
+
+		r := importFile(app, specs, f)
+		if r.err != nil {
+			app.log.Errorf("%s: %s", f, r.err)
+		} else {
+			app.log.Infof("%s: %d bars imported as %s", f, len(r.bars), r.symbol)
+		}
+		results = append(results, r)
+	}
+
+	return results, nil
+}
+
+func importFile(app App, specs map[string]instrument.Spec, fpath string) result {
+	r := result{file: fpath, format: app.Config.Setup.Format}
+
+	r.symbol = app.Config.Setup.Symbol
+	if r.symbol == "" {
+		r.symbol = symbolFromPath(fpath)
+	}
+	spec, ok := specs[r.symbol]
+	if !ok {
+		spec = instrument.Spec{Symbol: r.symbol, SecurityType: app.Config.Setup.Security}
+	}
+
+	fd, err := os.Open(fpath)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	defer fd.Close()
+
+	opts := ohlcio.ImportOpts{Format: app.Config.Setup.Format, Adjusted: app.Config.Setup.Adjusted}
+	r.bars, r.format, err = ohlcio.Import(fd, opts)
+	if err != nil {
+		r.err = fmt.Errorf("import error: %s", err)
+		return r
+	}
+	if len(r.bars) == 0 {
+		r.err = fmt.Errorf("no data")
+		return r
+	}
+
+	fname := filepath.Join(app.Config.Setup.OutputDir, r.symbol+".csv")
+	err = saveData(fname, ohlcio.ToCSV(r.bars, spec))
+	if err != nil {
+		r.err = fmt.Errorf("saveData error: %s", err)
+	}
+
+	return r
+}
+
+// symbolFromPath derives symbol from file name e.g. AAPL from aapl.us.txt.
+func symbolFromPath(fpath string) string {
+	name := filepath.Base(fpath)
+	if i := strings.IndexByte(name, '.'); i > 0 {
+		name = name[:i]
+	}
+	return strings.ToUpper(name)
+}
+
+// loadSpecs loads instrument specs from optional watchlist.
+func loadSpecs(app App) (map[string]instrument.Spec, error) {
+	output := map[string]instrument.Spec{}
+	if app.Config.Setup.Watchlist == "" {
+		return output, nil
+	}
+
+	fd, err := os.Open(app.Config.Setup.Watchlist)
+	if err != nil {
+		return output, err
+	}
+	defer fd.Close()
+
+	specLst, err := instrument.SpecLstFromCSV(fd)
+	if err != nil {
+		return output, fmt.Errorf("load from %s error: %s", app.Config.Setup.Watchlist, err)
+	}
+	for _, s := range specLst {
+		output[s.Symbol] = s
+	}
+
+	return output, nil
+}
+
+func saveData(fpath string, data [][]string) error {
+	output := data
+	err := osutil.FileExists(fpath)
+	if err == nil {
+		dataMerged, err := mergeData(fpath, data)
+		if err != nil {
+			return fmt.Errorf("mergeData %s failed: %v", fpath, err)
+		}
+		output = dataMerged
+	}
+
+	err = writeData(fpath, output)
+	if err != nil {
+		return fmt.Errorf("writeData %s failed: %v", fpath, err)
+	}
+
+	return nil
+}
+
+func mergeData(fpath string, dataNew [][]string) ([][]string, error) {
+	header := dataNew[0]
+	output := [][]string{header}
+	data := map[string][]string{}
+
+	file, err := os.Open(fpath)
+	if err != nil {
+		return output, fmt.Errorf("open data file error: %v", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comma = ';'
+	_, _ = reader.Read() // read CSV header
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return output, fmt.Errorf("read data file error: %v", err)
+		}
+		data[record[0]] = record
+	}
+
+	for _, record := range dataNew[1:] { // skip CSV header
+		data[record[0]] = record
+	}
+
+	toSort := [][]string{}
+	for _, record := range data {
+		toSort = append(toSort, record)
+	}
+	sort.Slice(toSort,
+		func(i, j int) bool { return toSort[i][0] < toSort[j][0] })
+
+	output = append(output, toSort...)
+	return output, nil
+}
+
+func writeData(fpath string, data [][]string) error {
+	dirname := filepath.Dir(fpath)
+	err := os.MkdirAll(dirname, osutil.DirPerms)
+	if err != nil {
+		return err
+	}
+
+	fdTmp, err := os.Create(fpath + ".swp")
+	if err != nil {
+		return fmt.Errorf("creating temp output file failed: %s", err)
+	}
+	defer os.Remove(fdTmp.Name())
+
+	w := csv.NewWriter(fdTmp)
+	w.Comma = ';'
+	w.WriteAll(data)
+	if w.Error() != nil {
+		return fmt.Errorf("CSV temp file error: %s", w.Error())
+	}
+
+	err = os.Chmod(fdTmp.Name(), osutil.FilePerms)
+	if err != nil {
+		return fmt.Errorf("chmod %s %s: %s", osutil.FilePerms.String(), fdTmp.Name(), err)
+	}
+	err = os.Rename(fdTmp.Name(), fpath)
+	if err != nil {
+		return fmt.Errorf("rename %s -> %s: %s", fdTmp.Name(), fpath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/profioss/clog"
+	"github.com/profioss/trada/model/instrument"
+)
+
+func TestSymbolFromPath(t *testing.T) {
+	tests := map[string]string{
+		"data/aapl.us.txt": "AAPL",
+		"MSFT.csv":         "MSFT",
+		"/tmp/BRK-B.csv":   "BRK-B",
+	}
+	for input, expected := range tests {
+		if s := symbolFromPath(input); s != expected {
+			t.Errorf("%s - expected %s, got %s", input, expected, s)
+		}
+	}
+}
+
+func TestImportFileMerge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trada-import")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	logger, _ := clog.New(ioutil.Discard, "disabled", false)
+	app := App{log: logger}
+	app.Config.Setup = Setup{OutputDir: filepath.Join(dir, "out"), Security: instrument.Equity}
+
+	existing := "Date;Open;High;Low;Close;Volume\n" +
+		"2019-10-28;1.00;1.00;1.00;1.00;1\n" +
+		"2019-10-29;2.00;2.00;2.00;2.00;2\n"
+	os.MkdirAll(app.Config.Setup.OutputDir, 0755)
+	ioutil.WriteFile(filepath.Join(app.Config.Setup.OutputDir, "MSFT.csv"), []byte(existing), 0644)
+
+	input := filepath.Join(dir, "msft.us.txt")
+	ioutil.WriteFile(input, []byte("Date,Open,High,Low,Close,Volume\n"+
+		"2019-10-29,3,3,3,3,3\n2019-10-30,4,4,4,4,4\n"), 0644)
+
+	r := importFile(app, map[string]instrument.Spec{}, input)
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(app.Config.Setup.OutputDir, "MSFT.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Date;Open;High;Low;Close;Volume\n" +
+		"2019-10-28;1.00;1.00;1.00;1.00;1\n" +
+		"2019-10-29;3.00;3.00;3.00;3.00;3\n" +
+		"2019-10-30;4.00;4.00;4.00;4.00;4\n"
+	if string(data) != expected {
+		t.Errorf("unexpected merged data:\n%s", data)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/profioss/trada/pkg/osutil"
+)
+
+func main() {
+	exitCode := 0
+	wg := sync.WaitGroup{}
+
+	app, err := newApp()
+	if err != nil {
+		log.Fatal("App init error: ", err)
+	}
+	defer app.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGTERM, os.Interrupt)
+
+	// common cleanup
+	defer func() {
+		signal.Stop(sigChan)
+		cancel()
+		cleanup(app)
+		wg.Wait()
+		os.Exit(exitCode)
+	}()
+
+	wg.Add(1)
+	go func() {
+		select {
+		case s := <-sigChan:
+			app.log.Warnf("Got %s signal - exitting", s)
+			cancel()
+			app.log.Info("Stopped")
+		case <-ctx.Done():
+			app.log.Info("DONE")
+		}
+		wg.Done()
+	}()
+
+	err = os.MkdirAll(app.Setup.OutputDir, osutil.DirPerms)
+	if err != nil {
+		exitCode = 1
+		app.log.Errorf("Prepare OutputDir error: %s", err)
+		return
+	}
+
+	app.log.Info("Starting")
+	err = do(ctx, app)
+	if err != nil {
+		exitCode = 1
+		app.log.Errorf("Import error: %s", err)
+		return
+	}
+}
+
+func do(ctx context.Context, app App) error {
+	results, err := importFiles(ctx, app)
+	printSummary(os.Stdout, results)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed", failed, len(results))
+	}
+
+	return nil
+}
+
+func cleanup(app App) {
+	app.log.Info("Cleaning up...")
+}
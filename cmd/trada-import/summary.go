@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+func (r result) status() string {
+	if r.err != nil {
+		return r.err.Error()
+	}
+	return "OK"
+}
+
+// printSummary writes table of imported files.
+func printSummary(w io.Writer, results []result) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tFORMAT\tSYMBOL\tBARS\tFIRST\tLAST\tSTATUS")
+	for _, r := range results {
+		first, last := "-", "-"
+		if len(r.bars) > 0 {
+			first, last = r.bars[0].Date.String(), r.bars[len(r.bars)-1].Date.String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+			r.file, r.format, r.symbol, len(r.bars), first, last, r.status())
+	}
+	tw.Flush()
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/profioss/trada/pkg/typedef"
+)
+
+// Config is main configuration set by command line flags.
+type Config struct {
+	DataDir   string
+	Watchlist string
+	Expr      string
+	Output    string       // output watchlist CSV; optional
+	Date      typedef.Date // evaluate at the last bar up to Date; the latest bar if zero
+	All       bool         // print also not matching symbols
+
+	filter node
+}
+
+// Validate checks if Config is valid.
+func (c Config) Validate() error {
+	switch {
+	case c.DataDir == "":
+		return errors.New("data directory is not specified")
+
+	case c.Watchlist == "":
+		return errors.New("watchlist is not specified")
+
+	case c.Expr == "":
+		return errors.New("filter expression is not specified")
+	}
+
+	if _, err := parse(c.Expr); err != nil {
+		return fmt.Errorf("filter expression: %v", err)
+	}
+
+	return nil
+}
+
+func initConfig() (Config, error) {
+	optDir := flag.String("d", "var/data/stocks", "data directory with <symbol>.csv files")
+	optWatchlist := flag.String("w", "", "watchlist CSV with symbols to screen (ex: var/data/index/SPX-components.csv)")
+	optExpr := flag.String("e", "", "filter expression (ex: \"close > sma(200) and rsi(14) < 30\")")
+	optOutput := flag.String("o", "", "output watchlist CSV of matching symbols")
+	optDate := flag.String("date", "", "evaluate at the last bar up to date YYYY-MM-DD")
+	optAll := flag.Bool("all", false, "print values of all symbols, not only matching")
+	flag.Usage = func() {
+		out := flag.CommandLine.Output()
+		fmt.Fprintf(out, "Usage: %s -w watchlist.csv -e expression [flags]\n", os.Args[0])
+		flag.PrintDefaults()
+		fmt.Fprintf(out, "\nExpression operators: and or not < <= > >= == != + - * / ( )\n")
+		fmt.Fprintf(out, "Variables (the latest bar): %s\n", variableNames())
+		fmt.Fprintf(out, "Functions:\n")
+		names := []string{}
+		for name := range functions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(out, "  %-12s %s\n", name+"(n)", functions[name].help)
+		}
+	}
+	flag.Parse()
+
+	conf := Config{
+		DataDir:   *optDir,
+		Watchlist: *optWatchlist,
+		Expr:      strings.TrimSpace(*optExpr),
+		Output:    *optOutput,
+		All:       *optAll,
+	}
+	if *optDate != "" {
+		d, err := typedef.DateFromStr(*optDate)
+		if err != nil {
+			return conf, fmt.Errorf("invalid -date: %v", err)
+		}
+		conf.Date = d
+	}
+
+	if err := conf.Validate(); err != nil {
+		return conf, err
+	}
+	conf.filter, _ = parse(conf.Expr) // validated
+
+	return conf, nil
+}
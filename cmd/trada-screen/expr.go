@@ -0,0 +1,442 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Filter expression language.
+//
+//   expr    := or
+//   or      := and ("or" and)*
+//   and     := not ("and" not)*
+//   not     := "not" not | cmp
+//   cmp     := sum (("<" | "<=" | ">" | ">=" | "==" | "!=") sum)?
+//   sum     := term (("+" | "-") term)*
+//   term    := unary (("*" | "/") unary)*
+//   unary   := "-" unary | primary
+//   primary := number | variable | function "(" args ")" | "(" expr ")"
+//
+// Values are float64, comparisons and logical operators give 1 (true)
+// or 0 (false); non zero value is true.
+// Variables are fields of the latest bar, functions are indicators,
+// see variables and functions.
+
+// node is node of expression tree.
+type node interface {
+	eval(e *env) (float64, error)
+	String() string
+}
+
+type number float64
+
+func (n number) eval(*env) (float64, error) { return float64(n), nil }
+func (n number) String() string             { return strconv.FormatFloat(float64(n), 'g', -1, 64) }
+
+type variable string
+
+func (v variable) eval(e *env) (float64, error) {
+	return variables[string(v)](e), nil
+}
+func (v variable) String() string { return string(v) }
+
+// maxArg limits function arguments (number of bars) so that they fit int.
+const maxArg = 1e6
+
+type call struct {
+	name string
+	args []node
+}
+
+func (c call) eval(e *env) (float64, error) {
+	fn := functions[c.name]
+	args := make([]int, len(c.args))
+	for i, a := range c.args {
+		v, err := a.eval(e)
+		if err != nil {
+			return 0, err
+		}
+		if v < 1 || v != math.Trunc(v) {
+			return 0, fmt.Errorf("%s: argument %g is not positive integer", c, v)
+		}
+		if v > maxArg {
+			return 0, fmt.Errorf("%s: argument %g is greater than %g", c, v, maxArg)
+		}
+		args[i] = int(v)
+	}
+
+	v, err := fn.eval(e, args)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", c, err)
+	}
+	return v, nil
+}
+
+func (c call) String() string {
+	args := make([]string, len(c.args))
+	for i, a := range c.args {
+		args[i] = a.String()
+	}
+	return c.name + "(" + strings.Join(args, ", ") + ")"
+}
+
+type unary struct {
+	op string
+	x  node
+}
+
+func (u unary) eval(e *env) (float64, error) {
+	x, err := u.x.eval(e)
+	if err != nil {
+		return 0, err
+	}
+	if u.op == "not" {
+		return boolean(x == 0), nil
+	}
+	return -x, nil
+}
+
+func (u unary) String() string {
+	if u.op == "not" {
+		return "not " + u.x.String()
+	}
+	return u.op + u.x.String()
+}
+
+type binary struct {
+	op   string
+	l, r node
+}
+
+func (b binary) eval(e *env) (float64, error) {
+	l, err := b.l.eval(e)
+	if err != nil {
+		return 0, err
+	}
+	// short circuit
+	switch {
+	case b.op == "and" && l == 0:
+		return 0, nil
+	case b.op == "or" && l != 0:
+		return 1, nil
+	}
+	r, err := b.r.eval(e)
+	if err != nil {
+		return 0, err
+	}
+
+	switch b.op {
+	case "and", "or":
+		return boolean(r != 0), nil
+	case "<":
+		return boolean(l < r), nil
+	case "<=":
+		return boolean(l <= r), nil
+	case ">":
+		return boolean(l > r), nil
+	case ">=":
+		return boolean(l >= r), nil
+	case "==":
+		return boolean(l == r), nil
+	case "!=":
+		return boolean(l != r), nil
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return 0, fmt.Errorf("%s: division by zero", b)
+		}
+		return l / r, nil
+	}
+
+	return 0, fmt.Errorf("unknown operator %q", b.op)
+}
+
+func (b binary) String() string {
+	return "(" + b.l.String() + " " + b.op + " " + b.r.String() + ")"
+}
+
+func boolean(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// terms provides variables and function calls of expression
+// in order of appearance without duplicates.
+func terms(n node) []node {
+	output := []node{}
+	seen := map[string]bool{}
+
+	var walk func(n node)
+	walk = func(n node) {
+		switch x := n.(type) {
+		case variable, call:
+			if !seen[x.String()] {
+				seen[x.String()] = true
+				output = append(output, x)
+			}
+		case unary:
+			walk(x.x)
+		case binary:
+			walk(x.l)
+			walk(x.r)
+		}
+	}
+	walk(n)
+
+	return output
+}
+
+//
+// parser
+//
+
+type token struct {
+	kind string // num, ident, op, eof
+	text string
+	pos  int
+}
+
+func tokenize(s string) ([]token, error) {
+	output := []token{}
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+
+		case unicode.IsDigit(c) || c == '.':
+			j := i
+			for j < len(s) && (unicode.IsDigit(rune(s[j])) || s[j] == '.' ||
+				s[j] == 'e' || s[j] == 'E' ||
+				(j > i && (s[j] == '-' || s[j] == '+') && (s[j-1] == 'e' || s[j-1] == 'E'))) {
+				j++
+			}
+			output = append(output, token{kind: "num", text: s[i:j], pos: i})
+			i = j
+
+		case unicode.IsLetter(c) || c == '_':
+			j := i
+			for j < len(s) && (unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j])) || s[j] == '_') {
+				j++
+			}
+			output = append(output, token{kind: "ident", text: strings.ToLower(s[i:j]), pos: i})
+			i = j
+
+		case strings.ContainsRune("<>=!", c):
+			j := i + 1
+			if j < len(s) && s[j] == '=' {
+				j++
+			}
+			op := s[i:j]
+			if op == "=" || op == "!" {
+				return output, fmt.Errorf("invalid operator %q at %d", op, i)
+			}
+			output = append(output, token{kind: "op", text: op, pos: i})
+			i = j
+
+		case strings.ContainsRune("+-*/(),", c):
+			output = append(output, token{kind: "op", text: string(c), pos: i})
+			i++
+
+		default:
+			return output, fmt.Errorf("unexpected character %q at %d", c, i)
+		}
+	}
+
+	return append(output, token{kind: "eof", pos: len(s)}), nil
+}
+
+type parser struct {
+	tokens []token
+	i      int
+}
+
+// parse parses filter expression.
+func parse(s string) (node, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	n, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != "eof" {
+		return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+	}
+
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.i]
+	if t.kind != "eof" {
+		p.i++
+	}
+	return t
+}
+
+// accept consumes the next token if it is one of ops (operators or keywords).
+func (p *parser) accept(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != "op" && t.kind != "ident" {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.i++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) or() (node, error) {
+	return p.binaryLevel(p.and, "or")
+}
+
+func (p *parser) and() (node, error) {
+	return p.binaryLevel(p.not, "and")
+}
+
+func (p *parser) not() (node, error) {
+	if _, ok := p.accept("not"); ok {
+		x, err := p.not()
+		return unary{op: "not", x: x}, err
+	}
+	return p.cmp()
+}
+
+func (p *parser) cmp() (node, error) {
+	l, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	if op, ok := p.accept("<", "<=", ">", ">=", "==", "!="); ok {
+		r, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		return binary{op: op, l: l, r: r}, nil
+	}
+	return l, nil
+}
+
+func (p *parser) sum() (node, error) {
+	return p.binaryLevel(p.term, "+", "-")
+}
+
+func (p *parser) term() (node, error) {
+	return p.binaryLevel(p.unary, "*", "/")
+}
+
+// binaryLevel parses left associative operators ops of operands parsed by sub.
+func (p *parser) binaryLevel(sub func() (node, error), ops ...string) (node, error) {
+	l, err := sub()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(ops...)
+		if !ok {
+			return l, nil
+		}
+		r, err := sub()
+		if err != nil {
+			return nil, err
+		}
+		l = binary{op: op, l: l, r: r}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	if _, ok := p.accept("-"); ok {
+		x, err := p.unary()
+		return unary{op: "-", x: x}, err
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch {
+	case t.kind == "num":
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at %d", t.text, t.pos)
+		}
+		return number(v), nil
+
+	case t.kind == "op" && t.text == "(":
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.accept(")"); !ok {
+			return nil, fmt.Errorf("missing ) at %d", p.peek().pos)
+		}
+		return n, nil
+
+	case t.kind == "ident" && p.peek().text == "(":
+		return p.call(t)
+
+	case t.kind == "ident":
+		if _, ok := variables[t.text]; !ok {
+			return nil, fmt.Errorf("unknown variable %q at %d; use one of: %s", t.text, t.pos, variableNames())
+		}
+		return variable(t.text), nil
+
+	case t.kind == "eof":
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	return nil, fmt.Errorf("unexpected %q at %d", t.text, t.pos)
+}
+
+func (p *parser) call(name token) (node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at %d; use one of: %s", name.text, name.pos, functionNames())
+	}
+	p.next() // (
+
+	c := call{name: name.text, args: []node{}}
+	if _, ok := p.accept(")"); !ok {
+		for {
+			a, err := p.or()
+			if err != nil {
+				return nil, err
+			}
+			c.args = append(c.args, a)
+			if _, ok := p.accept(","); ok {
+				continue
+			}
+			if _, ok := p.accept(")"); !ok {
+				return nil, fmt.Errorf("missing ) at %d", p.peek().pos)
+			}
+			break
+		}
+	}
+
+	if len(c.args) != fn.args {
+		return nil, fmt.Errorf("%s: expected %d argument(s), got %d", name.text, fn.args, len(c.args))
+	}
+
+	return c, nil
+}
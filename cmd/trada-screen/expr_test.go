@@ -0,0 +1,148 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/pkg/typedef"
+	"github.com/shopspring/decimal"
+)
+
+// mkEnv creates env of daily bars with given close prices; H = C+1, L = C-1, V = 1000*C.
+func mkEnv(t *testing.T, closes ...float64) *env {
+	output := []ohlc.OHLC{}
+	d := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, c := range closes {
+		output = append(output, ohlc.OHLC{
+			Date:   typedef.Date(d.AddDate(0, 0, i)),
+			Open:   decimal.NewFromFloat(c),
+			High:   decimal.NewFromFloat(c + 1),
+			Low:    decimal.NewFromFloat(c - 1),
+			Close:  decimal.NewFromFloat(c),
+			Volume: decimal.NewFromFloat(c * 1000),
+		})
+	}
+	v, err := ohlc.NewVec(output, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newEnv(v.Floats())
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		label  string
+		input  string
+		output string
+		hasErr bool
+	}{
+		{"simple", "close > 10", "(close > 10)", false},
+		{"precedence", "1 + 2 * 3 > 6 and not volume < 5 or high == low",
+			"((((1 + (2 * 3)) > 6) and not (volume < 5)) or (high == low))", false},
+		{"case insensitive", "CLOSE > SMA(20)", "(close > sma(20))", false},
+		{"exponent", "avgvol(20) > 1e6", "(avgvol(20) > 1e+06)", false},
+		{"unary minus", "roc(5) < -3.5", "(roc(5) < -3.5)", false},
+		{"empty", "", "", true},
+		{"unknown variable", "price > 10", "", true},
+		{"unknown function", "foo(10) > 1", "", true},
+		{"missing argument", "sma() > 1", "", true},
+		{"too many arguments", "sma(1, 2) > 1", "", true},
+		{"unbalanced", "(close > 1", "", true},
+		{"trailing", "close > 1 2", "", true},
+		{"invalid char", "close > $1", "", true},
+	}
+
+	for _, tt := range tests {
+		n, err := parse(tt.input)
+		if tt.hasErr {
+			if err == nil {
+				t.Errorf("%s - should have an error", tt.label)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s - unexpected error: %v", tt.label, err)
+			continue
+		}
+		if n.String() != tt.output {
+			t.Errorf("%s - expected %s, got %s", tt.label, tt.output, n)
+		}
+	}
+}
+
+func TestEval(t *testing.T) {
+	e := mkEnv(t, 10, 11, 12, 11, 13, 14, 13, 15)
+
+	tests := []struct {
+		label  string
+		input  string
+		output float64
+		hasErr bool
+	}{
+		{"close", "close", 15, false},
+		{"arithmetic", "(close - 5) / 2 * 3", 15, false},
+		{"comparison true", "close > open - 1", 1, false},
+		{"comparison false", "close != 15", 0, false},
+		{"and", "close > 1 and volume == 15000", 1, false},
+		{"or", "close < 1 or high == 16", 1, false},
+		{"not", "not close < 1", 1, false},
+		{"sma", "sma(4)", 13.75, false},
+		{"ema", "ema(8)", 12.375, false},
+		{"avgvol", "avgvol(2)", 14000, false},
+		{"roc", "roc(7)", 50, false},
+		{"highest", "highest(3)", 16, false},
+		{"lowest", "lowest(3)", 12, false},
+		{"atr", "atr(2)", 2.5, false},
+		{"rsi", "rsi(7)", 100 - 100/(1+7.0/2), false},
+		{"not enough data", "sma(20) > 1", 0, true},
+		{"invalid argument", "sma(1.5)", 0, true},
+		{"too big argument", "sma(1e19) > 0", 0, true},
+		{"too big argument of roc", "roc(1e19)", 0, true},
+		{"too big computed argument", "ema(1e6 * 1e6)", 0, true},
+		{"division by zero", "close / (open - open)", 0, true},
+	}
+
+	for _, tt := range tests {
+		n, err := parse(tt.input)
+		if err != nil {
+			t.Errorf("%s - unexpected parse error: %v", tt.label, err)
+			continue
+		}
+		v, err := n.eval(e)
+		if tt.hasErr {
+			if err == nil {
+				t.Errorf("%s - should have an error", tt.label)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s - unexpected error: %v", tt.label, err)
+			continue
+		}
+		if math.Abs(v-tt.output) > 1e-9 {
+			t.Errorf("%s - expected %v, got %v", tt.label, tt.output, v)
+		}
+	}
+}
+
+func TestTerms(t *testing.T) {
+	n, err := parse("close > sma(3) and close < sma(3) * 2 or rsi(2) > 50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, t := range terms(n) {
+		got = append(got, t.String())
+	}
+	want := []string{"close", "sma(3)", "rsi(2)"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, got)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/profioss/trada/model/ohlc"
+)
+
+// env is data of a symbol; the last element is the latest bar.
+type env struct {
+	open   []float64
+	high   []float64
+	low    []float64
+	close  []float64
+	volume []float64
+}
+
+func newEnv(f ohlc.Floats) *env {
+	return &env{open: f.Open, high: f.High, low: f.Low, close: f.Close, volume: f.Volume}
+}
+
+// last provides the last n values of lst.
+func last(lst []float64, n int) ([]float64, error) {
+	if n > len(lst) {
+		return nil, fmt.Errorf("not enough data: %d bars, %d required", len(lst), n)
+	}
+	return lst[len(lst)-n:], nil
+}
+
+// variables are fields of the latest bar.
+var variables = map[string]func(e *env) float64{
+	"open":   func(e *env) float64 { return e.open[len(e.open)-1] },
+	"high":   func(e *env) float64 { return e.high[len(e.high)-1] },
+	"low":    func(e *env) float64 { return e.low[len(e.low)-1] },
+	"close":  func(e *env) float64 { return e.close[len(e.close)-1] },
+	"volume": func(e *env) float64 { return e.volume[len(e.volume)-1] },
+}
+
+// function is indicator with integer arguments.
+type function struct {
+	args int
+	help string
+	eval func(e *env, args []int) (float64, error)
+}
+
+var functions = map[string]function{
+	"sma": {args: 1, help: "simple moving average of close", eval: func(e *env, a []int) (float64, error) {
+		lst, err := last(e.close, a[0])
+		if err != nil {
+			return 0, err
+		}
+		return mean(lst), nil
+	}},
+	"ema": {args: 1, help: "exponential moving average of close", eval: func(e *env, a []int) (float64, error) {
+		return ema(e.close, a[0])
+	}},
+	"rsi": {args: 1, help: "relative strength index (Wilder's smoothing)", eval: func(e *env, a []int) (float64, error) {
+		return rsi(e.close, a[0])
+	}},
+	"avgvol": {args: 1, help: "average volume", eval: func(e *env, a []int) (float64, error) {
+		lst, err := last(e.volume, a[0])
+		if err != nil {
+			return 0, err
+		}
+		return mean(lst), nil
+	}},
+	"roc": {args: 1, help: "rate of change of close in percent over n bars", eval: func(e *env, a []int) (float64, error) {
+		lst, err := last(e.close, a[0]+1)
+		if err != nil {
+			return 0, err
+		}
+		if lst[0] == 0 {
+			return 0, fmt.Errorf("zero close")
+		}
+		return (lst[a[0]]/lst[0] - 1) * 100, nil
+	}},
+	"highest": {args: 1, help: "highest high", eval: func(e *env, a []int) (float64, error) {
+		lst, err := last(e.high, a[0])
+		if err != nil {
+			return 0, err
+		}
+		max := lst[0]
+		for _, v := range lst {
+			max = math.Max(max, v)
+		}
+		return max, nil
+	}},
+	"lowest": {args: 1, help: "lowest low", eval: func(e *env, a []int) (float64, error) {
+		lst, err := last(e.low, a[0])
+		if err != nil {
+			return 0, err
+		}
+		min := lst[0]
+		for _, v := range lst {
+			min = math.Min(min, v)
+		}
+		return min, nil
+	}},
+	"atr": {args: 1, help: "average true range (simple average)", eval: func(e *env, a []int) (float64, error) {
+		n := a[0]
+		if n+1 > len(e.close) {
+			return 0, fmt.Errorf("not enough data: %d bars, %d required", len(e.close), n+1)
+		}
+		sum := 0.0
+		for i := len(e.close) - n; i < len(e.close); i++ {
+			tr := math.Max(e.high[i]-e.low[i],
+				math.Max(math.Abs(e.high[i]-e.close[i-1]), math.Abs(e.low[i]-e.close[i-1])))
+			sum += tr
+		}
+		return sum / float64(n), nil
+	}},
+}
+
+func mean(lst []float64) float64 {
+	sum := 0.0
+	for _, v := range lst {
+		sum += v
+	}
+	return sum / float64(len(lst))
+}
+
+// ema is seeded by SMA of the first n values.
+func ema(lst []float64, n int) (float64, error) {
+	if n > len(lst) {
+		return 0, fmt.Errorf("not enough data: %d bars, %d required", len(lst), n)
+	}
+	k := 2 / float64(n+1)
+	v := mean(lst[:n])
+	for _, x := range lst[n:] {
+		v = x*k + v*(1-k)
+	}
+	return v, nil
+}
+
+// rsi uses Wilder's smoothing seeded by average of the first n changes.
+func rsi(lst []float64, n int) (float64, error) {
+	if n+1 > len(lst) {
+		return 0, fmt.Errorf("not enough data: %d bars, %d required", len(lst), n+1)
+	}
+
+	gain, loss := 0.0, 0.0
+	for i := 1; i < len(lst); i++ {
+		ch := lst[i] - lst[i-1]
+		g, l := math.Max(ch, 0), math.Max(-ch, 0)
+		if i <= n {
+			gain += g / float64(n)
+			loss += l / float64(n)
+			continue
+		}
+		gain = (gain*float64(n-1) + g) / float64(n)
+		loss = (loss*float64(n-1) + l) / float64(n)
+	}
+
+	if loss == 0 {
+		return 100, nil
+	}
+	return 100 - 100/(1+gain/loss), nil
+}
+
+func variableNames() string {
+	names := []string{}
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func functionNames() string {
+	names := []string{}
+	for name := range functions {
+		names = append(names, name+"(n)")
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/profioss/trada/model/panel"
+)
+
+func main() {
+	conf, err := initConfig()
+	if err != nil {
+		log.Fatal("Config error: ", err)
+	}
+
+	if err := do(conf); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func do(conf Config) error {
+	p, skipped, err := panel.LoadWatchlist(conf.DataDir, conf.Watchlist, panel.Opts{Calendar: panel.Union})
+	if err != nil {
+		return err
+	}
+	if len(skipped) > 0 {
+		log.Printf("no data for %d symbol(s): %s", len(skipped), strings.Join(skipped, ", "))
+	}
+
+	results := screen(conf, p)
+	printResults(os.Stdout, conf, results)
+
+	failed := 0
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+		}
+	}
+	specs := matching(results)
+	fmt.Printf("\n%d of %d symbols match (%d failed, %d without data)\n",
+		len(specs), len(results), failed, len(skipped))
+
+	if conf.Output != "" {
+		if err := writeWatchlist(conf.Output, specs); err != nil {
+			return err
+		}
+		fmt.Printf("Watchlist saved to %s\n", conf.Output)
+	}
+
+	return nil
+}
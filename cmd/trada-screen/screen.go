@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/panel"
+	"github.com/profioss/trada/pkg/osutil"
+	"github.com/profioss/trada/pkg/typedef"
+)
+
+// result is screening result of a symbol.
+type result struct {
+	spec   instrument.Spec
+	date   typedef.Date // of evaluated bar
+	match  bool
+	values []float64 // of filter terms
+	err    error
+}
+
+// screen evaluates filter of conf for all instruments of panel p.
+func screen(conf Config, p panel.Panel) []result {
+	if !conf.Date.Time().IsZero() {
+		p = p.Slice(typedef.Date{}, conf.Date)
+	}
+	tt := terms(conf.filter)
+
+	output := []result{}
+	for _, s := range p.Specs() {
+		res := result{spec: s}
+		v, err := p.Vec(s.Symbol, 24*time.Hour)
+		if err != nil {
+			res.err = err
+			output = append(output, res)
+			continue
+		}
+		if v.Len() == 0 {
+			res.err = fmt.Errorf("no data")
+			output = append(output, res)
+			continue
+		}
+		f := v.Floats()
+		res.date = f.Dates[f.Len()-1]
+
+		e := newEnv(f)
+		for _, t := range tt {
+			val, err := t.eval(e)
+			if err != nil {
+				res.err = err
+				break
+			}
+			res.values = append(res.values, val)
+		}
+		if res.err == nil {
+			val, err := conf.filter.eval(e)
+			res.match, res.err = val != 0, err
+		}
+		output = append(output, res)
+	}
+
+	return output
+}
+
+// matching provides instruments of matching results.
+func matching(results []result) []instrument.Spec {
+	output := []instrument.Spec{}
+	for _, r := range results {
+		if r.match {
+			output = append(output, r.spec)
+		}
+	}
+	return output
+}
+
+// printResults writes table of filter terms values.
+func printResults(w io.Writer, conf Config, results []result) {
+	tt := terms(conf.filter)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprint(tw, "SYMBOL\tDATE")
+	for _, t := range tt {
+		fmt.Fprintf(tw, "\t%s", t)
+	}
+	fmt.Fprintln(tw, "\tMATCH")
+
+	for _, r := range results {
+		if !r.match && !conf.All {
+			continue
+		}
+		date := "-"
+		if !r.date.Time().IsZero() {
+			date = r.date.String()
+		}
+		fmt.Fprintf(tw, "%s\t%s", r.spec.Symbol, date)
+		for i := range tt {
+			v := "-"
+			if i < len(r.values) {
+				v = strconv.FormatFloat(r.values[i], 'f', 2, 64)
+			}
+			fmt.Fprintf(tw, "\t%s", v)
+		}
+		switch {
+		case r.err != nil:
+			fmt.Fprintf(tw, "\t%v\n", r.err)
+		case r.match:
+			fmt.Fprintln(tw, "\tyes")
+		default:
+			fmt.Fprintln(tw, "\tno")
+		}
+	}
+	tw.Flush()
+}
+
+// writeWatchlist atomically writes watchlist CSV.
+func writeWatchlist(fpath string, specs []instrument.Spec) error {
+	if err := os.MkdirAll(filepath.Dir(fpath), osutil.DirPerms); err != nil {
+		return err
+	}
+
+	fdTmp, err := os.Create(fpath + ".swp")
+	if err != nil {
+		return fmt.Errorf("creating temp output file failed: %s", err)
+	}
+	defer os.Remove(fdTmp.Name())
+
+	err = instrument.SpecLstToCSV(fdTmp, specs)
+	if errClose := fdTmp.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return fmt.Errorf("instrument.SpecLstToCSV: %v", err)
+	}
+
+	err = os.Chmod(fdTmp.Name(), osutil.FilePerms)
+	if err != nil {
+		return fmt.Errorf("chmod %s %s: %s", osutil.FilePerms.String(), fdTmp.Name(), err)
+	}
+	err = os.Rename(fdTmp.Name(), fpath)
+	if err != nil {
+		return fmt.Errorf("rename %s -> %s: %s", fdTmp.Name(), fpath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/panel"
+	"github.com/profioss/trada/pkg/typedef"
+)
+
+// writeCSV writes trada CSV of Close prices starting 2020-01-01.
+func writeCSV(t *testing.T, dir, symbol string, closes ...float64) {
+	buf := &bytes.Buffer{}
+	fmt.Fprintln(buf, "Date;Open;High;Low;Close;Volume")
+	d := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, c := range closes {
+		fmt.Fprintf(buf, "%s;%.2f;%.2f;%.2f;%.2f;1000\n", d.AddDate(0, 0, i).Format("2006-01-02"), c, c, c, c)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, symbol+".csv"), buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScreen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trada-screen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeCSV(t, dir, "UP", 10, 11, 12, 13, 14)
+	writeCSV(t, dir, "DOWN", 14, 13, 12, 11, 10)
+	writeCSV(t, dir, "SHORT", 10, 11)
+	specs := []instrument.Spec{
+		{Symbol: "UP", SecurityType: instrument.Equity},
+		{Symbol: "DOWN", SecurityType: instrument.Equity},
+		{Symbol: "SHORT", SecurityType: instrument.Equity},
+		{Symbol: "MISSING", SecurityType: instrument.Equity},
+	}
+	watchlist := filepath.Join(dir, "watchlist.csv")
+	if err := writeWatchlist(watchlist, specs); err != nil {
+		t.Fatal(err)
+	}
+
+	p, skipped, err := panel.LoadWatchlist(dir, watchlist, panel.Opts{Calendar: panel.Union})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 1 || skipped[0] != "MISSING" {
+		t.Errorf("MISSING should be skipped, got %v", skipped)
+	}
+
+	filter, err := parse("close > sma(3)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := Config{filter: filter, All: true}
+	results := screen(conf, p)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	for _, r := range results {
+		switch r.spec.Symbol {
+		case "UP":
+			if !r.match || r.err != nil || len(r.values) != 2 || r.values[1] != 13 {
+				t.Errorf("UP should match: %+v", r)
+			}
+		case "DOWN":
+			if r.match || r.err != nil {
+				t.Errorf("DOWN should not match: %+v", r)
+			}
+		case "SHORT":
+			if r.match || r.err == nil {
+				t.Errorf("SHORT should fail: %+v", r)
+			}
+		}
+	}
+
+	// evaluated at earlier date DOWN has not enough data
+	conf.Date, _ = typedef.DateFromStr("2020-01-02")
+	for _, r := range screen(conf, p) {
+		if r.match || r.err == nil {
+			t.Errorf("%s should fail at %s: %+v", r.spec.Symbol, conf.Date, r)
+		}
+	}
+	conf.Date = typedef.Date{}
+
+	out := &bytes.Buffer{}
+	printResults(out, conf, results)
+	if !strings.Contains(out.String(), "sma(3)") || !strings.Contains(out.String(), "not enough data") {
+		t.Errorf("unexpected table:\n%s", out)
+	}
+
+	output := filepath.Join(dir, "out", "matching.csv")
+	if err := writeWatchlist(output, matching(results)); err != nil {
+		t.Fatal(err)
+	}
+	fd, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	got, err := instrument.SpecLstFromCSV(fd)
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case len(got) != 1 || got[0].Symbol != "UP" || got[0].SecurityType != instrument.Equity:
+		t.Errorf("unexpected watchlist: %+v", got)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/pkg/stats"
+	"github.com/profioss/trada/pkg/typedef"
+)
+
+// Config is main configuration set by command line flags.
+type Config struct {
+	DataDir    string
+	Benchmark  string
+	RiskFree   float64 // annual rate
+	Confidence float64 // of VaR
+	Periods    int     // per year
+	From       typedef.Date
+	To         typedef.Date
+	Corr       bool
+	Window     int // of rolling correlation
+
+	symbols []string
+}
+
+// Validate checks if Config is valid.
+func (c Config) Validate() error {
+	switch {
+	case c.DataDir == "":
+		return errors.New("data directory is not specified")
+
+	case len(c.symbols) == 0:
+		return errors.New("no symbols specified")
+
+	case c.Confidence <= 0 || c.Confidence >= 1:
+		return fmt.Errorf("confidence %g is not in range (0, 1)", c.Confidence)
+
+	case c.Periods < 1:
+		return errors.New("periods per year is < 1")
+
+	case c.Window == 1 || c.Window < 0:
+		return errors.New("window is < 2")
+
+	case !c.From.Time().IsZero() && !c.To.Time().IsZero() && c.To.Time().Before(c.From.Time()):
+		return errors.New("-to date is before -from date")
+	}
+
+	return nil
+}
+
+func initConfig() (Config, error) {
+	optDir := flag.String("d", "var/data/stocks", "data directory with <symbol>.csv files")
+	optBench := flag.String("b", "", "benchmark symbol (ex: SPY) for beta and alpha")
+	optRF := flag.Float64("rf", 0, "annual risk free rate (ex: 0.02)")
+	optConf := flag.Float64("confidence", 0.95, "confidence level of Value-at-Risk")
+	optPeriods := flag.Int("periods", stats.TradingDays, "periods (bars) per year")
+	optFrom := flag.String("from", "", "first date YYYY-MM-DD")
+	optTo := flag.String("to", "", "last date YYYY-MM-DD")
+	optCorr := flag.Bool("corr", false, "print correlation matrix of returns")
+	optWindow := flag.Int("window", 0, "correlation of the last window of returns (with -corr)")
+	optWatchlist := flag.String("w", "", "watchlist CSV with symbols")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] symbol ...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	conf := Config{
+		DataDir:    *optDir,
+		Benchmark:  *optBench,
+		RiskFree:   *optRF,
+		Confidence: *optConf,
+		Periods:    *optPeriods,
+		Corr:       *optCorr,
+		Window:     *optWindow,
+		symbols:    flag.Args(),
+	}
+
+	var err error
+	if *optFrom != "" {
+		if conf.From, err = typedef.DateFromStr(*optFrom); err != nil {
+			return conf, fmt.Errorf("invalid -from date: %v", err)
+		}
+	}
+	if *optTo != "" {
+		if conf.To, err = typedef.DateFromStr(*optTo); err != nil {
+			return conf, fmt.Errorf("invalid -to date: %v", err)
+		}
+	}
+
+	if *optWatchlist != "" {
+		fd, err := os.Open(*optWatchlist)
+		if err != nil {
+			return conf, err
+		}
+		defer fd.Close()
+		specs, err := instrument.SpecLstFromCSV(fd)
+		if err != nil {
+			return conf, fmt.Errorf("watchlist %s: %v", *optWatchlist, err)
+		}
+		for _, s := range specs {
+			conf.symbols = append(conf.symbols, s.Symbol)
+		}
+	}
+	for i := range conf.symbols {
+		conf.symbols[i] = strings.TrimSpace(conf.symbols[i])
+	}
+
+	return conf, conf.Validate()
+}
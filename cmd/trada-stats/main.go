@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/profioss/trada/pkg/stats"
+)
+
+func main() {
+	conf, err := initConfig()
+	if err != nil {
+		log.Fatal("Config error: ", err)
+	}
+
+	if err := do(conf); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func do(conf Config) error {
+	benchmark := stats.Series{}
+	if conf.Benchmark != "" {
+		prices, err := loadSeries(conf, conf.Benchmark)
+		if err != nil {
+			return fmt.Errorf("benchmark: %v", err)
+		}
+		if benchmark, err = prices.Returns(); err != nil {
+			return fmt.Errorf("benchmark %s: %v", conf.Benchmark, err)
+		}
+	}
+
+	reports := []report{}
+	returns := []stats.Series{}
+	symbols := []string{}
+	failed := 0
+	for _, sym := range conf.symbols {
+		prices, err := loadSeries(conf, sym)
+		if err != nil {
+			log.Print(err)
+			failed++
+			continue
+		}
+		r, err := mkReport(conf, sym, prices, benchmark)
+		if err != nil {
+			log.Print(err)
+			failed++
+			continue
+		}
+		reports = append(reports, r)
+
+		ret, _ := prices.Returns() // checked by mkReport
+		returns = append(returns, ret)
+		symbols = append(symbols, sym)
+	}
+
+	printReports(os.Stdout, conf, reports)
+
+	if conf.Corr && len(returns) > 1 {
+		m := stats.CorrelationMatrix(returns...)
+		if conf.Window > 0 {
+			rolling, err := stats.RollingCorrelation(conf.Window, returns...)
+			if err != nil {
+				return err
+			}
+			if len(rolling) == 0 {
+				return fmt.Errorf("less than %d common returns", conf.Window)
+			}
+			m = rolling[len(rolling)-1]
+		}
+		fmt.Println()
+		printMatrix(os.Stdout, symbols, m)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d symbols failed", failed, len(conf.symbols))
+	}
+
+	return nil
+}
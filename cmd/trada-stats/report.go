@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/model/ohlc/ohlcio"
+	"github.com/profioss/trada/pkg/stats"
+)
+
+// report is statistics of a symbol.
+type report struct {
+	symbol     string
+	bars       int
+	first      string
+	last       string
+	cagr       float64
+	volatility float64
+	sharpe     float64
+	sortino    float64
+	drawdown   stats.Drawdown
+	calmar     float64
+	beta       float64
+	alpha      float64
+	varHist    float64
+	varParam   float64
+}
+
+// loadSeries loads Close series of symbol from data directory.
+func loadSeries(conf Config, symbol string) (stats.Series, error) {
+	fpath := filepath.Join(conf.DataDir, symbol+".csv")
+	fd, err := os.Open(fpath)
+	if err != nil {
+		return stats.Series{}, err
+	}
+	defer fd.Close()
+
+	data, err := ohlcio.FromCSV(fd)
+	if err != nil {
+		return stats.Series{}, fmt.Errorf("%s: %v", fpath, err)
+	}
+	v, err := ohlc.NewVec(data, 24*time.Hour)
+	if err != nil {
+		return stats.Series{}, fmt.Errorf("%s: %v", fpath, err)
+	}
+
+	return stats.FromVec(v).Range(conf.From, conf.To), nil
+}
+
+// mkReport computes statistics of prices; benchmark returns are optional.
+func mkReport(conf Config, symbol string, prices, benchmark stats.Series) (report, error) {
+	r := report{symbol: symbol, bars: prices.Len(), beta: math.NaN(), alpha: math.NaN()}
+
+	returns, err := prices.Returns()
+	if err != nil {
+		return r, fmt.Errorf("%s: %v", symbol, err)
+	}
+	r.first = prices.Dates[0].String()
+	r.last = prices.Dates[prices.Len()-1].String()
+
+	r.cagr, err = stats.CAGR(prices)
+	if err != nil {
+		return r, fmt.Errorf("%s: %v", symbol, err)
+	}
+	r.volatility = stats.Volatility(returns, conf.Periods)
+	r.sharpe = stats.Sharpe(returns, conf.RiskFree, conf.Periods)
+	r.sortino = stats.Sortino(returns, conf.RiskFree, conf.Periods)
+	r.drawdown = stats.MaxDrawdown(prices)
+	r.calmar, _ = stats.Calmar(prices)
+
+	if benchmark.Len() > 0 {
+		r.beta, r.alpha, err = stats.Beta(returns, benchmark, conf.RiskFree, conf.Periods)
+		if err != nil {
+			return r, fmt.Errorf("%s: beta: %v", symbol, err)
+		}
+	}
+
+	r.varHist, err = stats.HistoricalVaR(returns, conf.Confidence)
+	if err != nil {
+		return r, fmt.Errorf("%s: %v", symbol, err)
+	}
+	r.varParam, err = stats.ParametricVaR(returns, conf.Confidence)
+	if err != nil {
+		return r, fmt.Errorf("%s: %v", symbol, err)
+	}
+
+	return r, nil
+}
+
+// printReports writes table of reports.
+func printReports(w io.Writer, conf Config, reports []report) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	varHdr := fmt.Sprintf("VAR%g", conf.Confidence*100)
+	fmt.Fprintf(tw, "SYMBOL\tFIRST\tLAST\tBARS\tCAGR\tVOL\tSHARPE\tSORTINO\tMAX-DD\tDD-DAYS\tCALMAR\tBETA\tALPHA\t%s-HIST\t%s-PARAM\t\n",
+		varHdr, varHdr)
+	for _, r := range reports {
+		ddDays := fmt.Sprintf("%.0f", r.drawdown.Duration.Hours()/24)
+		if r.drawdown.Max > 0 && !r.drawdown.Recovered() {
+			ddDays += "+"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			r.symbol, r.first, r.last, r.bars,
+			pct(r.cagr), pct(r.volatility), num(r.sharpe), num(r.sortino),
+			pct(r.drawdown.Max), ddDays, num(r.calmar),
+			num(r.beta), pct(r.alpha), pct(r.varHist), pct(r.varParam))
+	}
+	tw.Flush()
+}
+
+// printMatrix writes correlation matrix.
+func printMatrix(w io.Writer, symbols []string, m stats.Matrix) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "%s\t%s\t\n", m.Date, strings.Join(symbols, "\t"))
+	for i, row := range m.Values {
+		cells := make([]string, len(row))
+		for j, v := range row {
+			cells[j] = num(v)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t\n", symbols[i], strings.Join(cells, "\t"))
+	}
+	tw.Flush()
+}
+
+func pct(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", v*100)
+}
+
+func num(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", v)
+}
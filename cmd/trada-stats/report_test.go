@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeCSV writes trada CSV of Close prices starting 2020-01-01.
+func writeCSV(t *testing.T, dir, symbol string, closes ...float64) {
+	buf := &bytes.Buffer{}
+	fmt.Fprintln(buf, "Date;Open;High;Low;Close;Volume")
+	d := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, c := range closes {
+		fmt.Fprintf(buf, "%s;%.2f;%.2f;%.2f;%.2f;1000\n", d.AddDate(0, 0, i).Format("2006-01-02"), c, c, c, c)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, symbol+".csv"), buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trada-stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeCSV(t, dir, "SPY", 100, 101, 99, 102, 103, 101, 104)
+	writeCSV(t, dir, "XYZ", 50, 51, 49, 52, 53, 51, 54)
+	writeCSV(t, dir, "ONE", 50)
+
+	conf := Config{DataDir: dir, Confidence: 0.95, Periods: 252, symbols: []string{"XYZ"}}
+	bench, err := loadSeries(conf, "SPY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	benchReturns, err := bench.Returns()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prices, err := loadSeries(conf, "XYZ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := mkReport(conf, "XYZ", prices, benchReturns)
+	switch {
+	case err != nil:
+		t.Fatalf("unexpected error: %v", err)
+	case r.bars != 7 || r.first != "2020-01-01" || r.last != "2020-01-07":
+		t.Errorf("unexpected range: %+v", r)
+	case math.Abs(r.beta-2) > 0.1:
+		t.Errorf("beta should be ~2, got %v", r.beta)
+	case math.Abs(r.drawdown.Max-2.0/51) > 1e-9:
+		t.Errorf("max drawdown should be %v, got %v", 2.0/51, r.drawdown.Max)
+	}
+
+	buf := &bytes.Buffer{}
+	printReports(buf, conf, []report{r})
+	if !strings.Contains(buf.String(), "VAR95-HIST") || !strings.Contains(buf.String(), "XYZ") {
+		t.Errorf("unexpected report:\n%s", buf)
+	}
+
+	one, err := loadSeries(conf, "ONE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mkReport(conf, "ONE", one, benchReturns); err == nil {
+		t.Errorf("single bar - should have an error")
+	}
+	if _, err := loadSeries(conf, "MISSING"); err == nil {
+		t.Errorf("missing file - should have an error")
+	}
+}
@@ -0,0 +1,151 @@
+package forex
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+// Fill defines handling of dates missing in FX series.
+type Fill int
+
+const (
+	// FillNone - missing FX rate is an error.
+	FillNone Fill = iota
+
+	// FillDrop - bars without FX rate are dropped.
+	FillDrop
+
+	// FillForward - the last known FX rate is used (see Converter.MaxFillDays).
+	// Bars before the first FX rate are an error.
+	FillForward
+)
+
+// Converter converts OHLC series from one currency into another.
+// All prices of a bar are multiplied by FX close of the same date
+// (or the last known one, see Fill). Volume is kept as it is in units.
+type Converter struct {
+	Pair instrument.ForexSpec // Base is source, Quote is target currency e.g. USDEUR
+	FX   ohlc.Vec             // daily rates of Pair
+	Fill Fill
+
+	// MaxFillDays limits FillForward to given number of calendar days;
+	// 0 means no limit.
+	MaxFillDays int
+}
+
+// Validate checks if Converter has valid content.
+func (c Converter) Validate() error {
+	switch {
+	case c.Pair.Validate() != nil:
+		return fmt.Errorf("invalid Pair: %v", c.Pair.Validate())
+
+	case c.Fill < FillNone || c.Fill > FillForward:
+		return fmt.Errorf("unknown Fill: %d", c.Fill)
+
+	case c.MaxFillDays < 0:
+		return fmt.Errorf("MaxFillDays: %d is less than zero", c.MaxFillDays)
+	}
+
+	return nil
+}
+
+// Converter creates Converter from currency from to currency to
+// using Pair to get (direct, inverse or cross) rates.
+func (r Rates) Converter(from, to string, via []string, fill Fill) (Converter, error) {
+	output := Converter{Pair: instrument.ForexSpec{Base: from, Quote: to}, Fill: fill}
+
+	bars, err := r.Pair(output.Pair, via)
+	if err != nil {
+		return output, err
+	}
+	fx, err := ohlc.NewVec(bars, 24*time.Hour)
+	if err != nil {
+		return output, fmt.Errorf("%s: invalid rates: %v", output.Pair.Symbol(), err)
+	}
+	output.FX = fx
+
+	return output, output.Validate()
+}
+
+// Rate returns FX rate used for date d.
+func (c Converter) Rate(d typedef.Date) (decimal.Decimal, error) {
+	return c.rate(c.FX.Dates(), d)
+}
+
+// rate returns FX rate used for date d; dates are FX dates.
+func (c Converter) rate(dates []typedef.Date, d typedef.Date) (decimal.Decimal, error) {
+	i := sort.Search(len(dates), func(i int) bool { return !dates[i].Time().Before(d.Time()) })
+	if i < len(dates) && dates[i] == d {
+		bar, err := c.FX.AtIdx(i)
+		return bar.Close, err
+	}
+	if c.Fill != FillForward || i == 0 {
+		return decimal.Zero, fmt.Errorf("%s: no rate for %s", c.Pair.Symbol(), d)
+	}
+
+	last := dates[i-1]
+	if c.MaxFillDays > 0 && last.Time().AddDate(0, 0, c.MaxFillDays).Before(d.Time()) {
+		return decimal.Zero, fmt.Errorf("%s: no rate for %s, the last one %s is too old",
+			c.Pair.Symbol(), d, last)
+	}
+	bar, err := c.FX.AtIdx(i - 1)
+	return bar.Close, err
+}
+
+// Vec converts prices of v into target currency.
+func (c Converter) Vec(v ohlc.Vec) (ohlc.Vec, error) {
+	if c.Validate() != nil {
+		return ohlc.Vec{}, fmt.Errorf("invalid Converter: %v", c.Validate())
+	}
+
+	dates := c.FX.Dates()
+	bars := v.Data()
+	output := make([]ohlc.OHLC, 0, len(bars))
+	for _, b := range bars {
+		rate, err := c.rate(dates, b.Date)
+		if err != nil {
+			if c.Fill == FillDrop {
+				continue
+			}
+			return ohlc.Vec{}, err
+		}
+		output = append(output, ohlc.OHLC{
+			Date:   b.Date,
+			Open:   b.Open.Mul(rate),
+			High:   b.High.Mul(rate),
+			Low:    b.Low.Mul(rate),
+			Close:  b.Close.Mul(rate),
+			Volume: b.Volume,
+		})
+	}
+
+	return ohlc.NewVec(output, v.Timeframe())
+}
+
+// Spec returns copy of s with prices in target currency.
+// Currency of s has to be source currency of Converter if set.
+// TickSize does not apply to converted prices so it is dropped,
+// price precision is kept.
+func (c Converter) Spec(s instrument.Spec) (instrument.Spec, error) {
+	if s.Forex != nil {
+		return s, fmt.Errorf("%s: currency pair can not be converted", s.Symbol)
+	}
+	if cur := s.PriceCurrency(); cur != "" && cur != c.Pair.Base {
+		return s, fmt.Errorf("%s: currency %s does not match %s", s.Symbol, cur, c.Pair.Symbol())
+	}
+
+	if s.PriceDecimals == 0 {
+		s.PriceDecimals = s.PricePrecision()
+	}
+	s.TickSize = decimal.Zero
+	s.Currency = c.Pair.Quote
+
+	return s, nil
+}
@@ -0,0 +1,87 @@
+package forex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/ohlc"
+)
+
+func TestConverter(t *testing.T) {
+	rates := Rates{
+		// EUR based rates with missing 2019-10-30
+		"EURUSD": mkBars([]string{"2019-10-28", "2019-10-29", "2019-10-31"}, []string{"1.25", "1.25", "1.6"}),
+	}
+	spy, err := ohlc.NewVec(mkBars(
+		[]string{"2019-10-25", "2019-10-29", "2019-10-30", "2019-10-31"},
+		[]string{"300", "300", "310", "320"}), 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		label       string
+		fill        Fill
+		maxFillDays int
+		closes      []string
+		hasErr      bool
+	}{
+		{label: "fill none", fill: FillNone, hasErr: true},
+		{label: "fill drop", fill: FillDrop, closes: []string{"240", "200"}},
+		{label: "fill forward before first rate", fill: FillForward, hasErr: true},
+	}
+
+	for _, tc := range tests {
+		c, err := rates.Converter("USD", "EUR", nil, tc.fill)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.MaxFillDays = tc.maxFillDays
+
+		v, err := c.Vec(spy)
+		switch {
+		case tc.hasErr && err == nil:
+			t.Errorf("%s - should have an error", tc.label)
+		case !tc.hasErr && err != nil:
+			t.Errorf("%s - unexpected error: %v", tc.label, err)
+		case tc.hasErr:
+		default:
+			bars := v.Data()
+			if len(bars) != len(tc.closes) {
+				t.Fatalf("%s - expected %d bars, got %d", tc.label, len(tc.closes), len(bars))
+			}
+			for i, b := range bars {
+				if b.Close.String() != tc.closes[i] {
+					t.Errorf("%s - close %s should be %s, got %s", tc.label, b.Date, tc.closes[i], b.Close)
+				}
+			}
+		}
+	}
+
+	// forward fill of dates after the first rate
+	c, _ := rates.Converter("USD", "EUR", nil, FillForward)
+	d := spy.Dates()[2] // 2019-10-30
+	r, err := c.Rate(d)
+	if err != nil || r.String() != "0.8" {
+		t.Errorf("forward fill - expected rate 0.8, got %s (%v)", r, err)
+	}
+	c.FX, _ = ohlc.NewVec(rates["EURUSD"][:1], 24*time.Hour)
+	c.MaxFillDays = 1
+	if _, err := c.Rate(d); err == nil {
+		t.Errorf("max fill days - should have an error")
+	}
+
+	spec := instrument.Spec{Symbol: "SPY", SecurityType: instrument.Equity, Currency: "USD"}
+	eur, err := c.Spec(spec)
+	switch {
+	case err != nil:
+		t.Errorf("spec - unexpected error: %v", err)
+	case eur.Currency != "EUR" || eur.PricePrecision() != 2 || eur.Validate() != nil:
+		t.Errorf("spec - unexpected result: %#v", eur)
+	}
+	spec.Currency = "GBP"
+	if _, err := c.Spec(spec); err == nil {
+		t.Errorf("spec currency mismatch - should have an error")
+	}
+}
@@ -0,0 +1,139 @@
+// Package forex derives currency pair rates from available pairs
+// (direct, inverse and cross rates) and converts OHLC series
+// into other currencies.
+package forex
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+// Rates is collection of daily bars of currency pairs
+// keyed by pair symbol e.g. EURUSD.
+type Rates map[string][]ohlc.OHLC
+
+// Currencies returns sorted list of currencies available in Rates.
+func (r Rates) Currencies() []string {
+	set := map[string]bool{}
+	for sym := range r {
+		p, err := instrument.ParseForexSymbol(sym)
+		if err != nil {
+			continue
+		}
+		set[p.Base], set[p.Quote] = true, true
+	}
+
+	output := make([]string, 0, len(set))
+	for c := range set {
+		output = append(output, c)
+	}
+	sort.Strings(output)
+
+	return output
+}
+
+// Pair returns bars of currency pair p. Direct pair is used if available,
+// otherwise inverse pair is inverted, otherwise cross rate is derived
+// via the first currency of via list which has both legs available
+// e.g. EURJPY from EURUSD and USDJPY. All currencies of Rates are tried
+// if via list is empty.
+func (r Rates) Pair(p instrument.ForexSpec, via []string) ([]ohlc.OHLC, error) {
+	if p.Validate() != nil {
+		return nil, fmt.Errorf("invalid pair: %v", p.Validate())
+	}
+
+	if bars, ok := r.leg(p); ok {
+		return bars, nil
+	}
+
+	if len(via) == 0 {
+		via = r.Currencies()
+	}
+	for _, c := range via {
+		if c == p.Base || c == p.Quote {
+			continue
+		}
+		a, ok := r.leg(instrument.ForexSpec{Base: p.Base, Quote: c})
+		if !ok {
+			continue
+		}
+		b, ok := r.leg(instrument.ForexSpec{Base: c, Quote: p.Quote})
+		if !ok {
+			continue
+		}
+		return Cross(a, b), nil
+	}
+
+	return nil, fmt.Errorf("%s: no direct, inverse nor cross rate available", p.Symbol())
+}
+
+// leg returns direct or inverted bars of pair p.
+func (r Rates) leg(p instrument.ForexSpec) ([]ohlc.OHLC, bool) {
+	if bars, ok := r[p.Symbol()]; ok && len(bars) > 0 {
+		return bars, true
+	}
+	if bars, ok := r[p.Inverse().Symbol()]; ok && len(bars) > 0 {
+		return Invert(bars), true
+	}
+	return nil, false
+}
+
+// Invert converts bars of pair BASE/QUOTE into bars of QUOTE/BASE.
+// High and Low swap their roles, volume is dropped.
+// Bars with non-positive prices are skipped.
+func Invert(bars []ohlc.OHLC) []ohlc.OHLC {
+	one := decimal.New(1, 0)
+	output := make([]ohlc.OHLC, 0, len(bars))
+	for _, b := range bars {
+		if !b.Open.IsPositive() || !b.High.IsPositive() ||
+			!b.Low.IsPositive() || !b.Close.IsPositive() {
+			continue
+		}
+		output = append(output, ohlc.OHLC{
+			Date:  b.Date,
+			Open:  one.Div(b.Open),
+			High:  one.Div(b.Low),
+			Low:   one.Div(b.High),
+			Close: one.Div(b.Close),
+		})
+	}
+	return output
+}
+
+// Cross derives bars of BASE/QUOTE from bars a of BASE/X and b of X/QUOTE
+// at dates present in both inputs. Open and Close are exact products.
+// High and Low are products of highs and lows, which are the bounds
+// of the true range as intraday extremes of the legs need not coincide.
+// Volume is dropped.
+func Cross(a, b []ohlc.OHLC) []ohlc.OHLC {
+	bmap := make(map[typedef.Date]ohlc.OHLC, len(b))
+	for _, bar := range b {
+		bmap[bar.Date] = bar
+	}
+
+	output := make([]ohlc.OHLC, 0, len(a))
+	for _, ab := range a {
+		bb, ok := bmap[ab.Date]
+		if !ok {
+			continue
+		}
+		output = append(output, ohlc.OHLC{
+			Date:  ab.Date,
+			Open:  ab.Open.Mul(bb.Open),
+			High:  ab.High.Mul(bb.High),
+			Low:   ab.Low.Mul(bb.Low),
+			Close: ab.Close.Mul(bb.Close),
+		})
+	}
+	sort.Slice(output, func(i, j int) bool {
+		return output[i].Date.Time().Before(output[j].Date.Time())
+	})
+
+	return output
+}
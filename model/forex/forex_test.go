@@ -0,0 +1,93 @@
+package forex
+
+import (
+	"testing"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+// mkBars creates reference rate bars (O=H=L=C) at given dates.
+func mkBars(dates []string, rates []string) []ohlc.OHLC {
+	output := make([]ohlc.OHLC, 0, len(dates))
+	for i := range dates {
+		d, err := typedef.DateFromStr(dates[i])
+		if err != nil {
+			panic(err)
+		}
+		r := decimal.RequireFromString(rates[i])
+		output = append(output, ohlc.OHLC{Date: d, Open: r, High: r, Low: r, Close: r})
+	}
+	return output
+}
+
+func TestPair(t *testing.T) {
+	rates := Rates{
+		"EURUSD": mkBars([]string{"2019-10-28", "2019-10-29", "2019-10-30"}, []string{"1.1", "1.2", "1.25"}),
+		"USDJPY": mkBars([]string{"2019-10-29", "2019-10-30"}, []string{"100", "108"}),
+		"GBPUSD": mkBars([]string{"2019-10-29"}, []string{"1.25"}),
+	}
+
+	tests := []struct {
+		pair   string
+		via    []string
+		closes []string
+		hasErr bool
+	}{
+		{pair: "EURUSD", closes: []string{"1.1", "1.2", "1.25"}},
+		{pair: "USDEUR", closes: []string{"0.9091", "0.8333", "0.8"}},
+		{pair: "EURJPY", closes: []string{"120", "135"}},
+		{pair: "JPYEUR", closes: []string{"0.0083", "0.0074"}},
+		{pair: "EURGBP", via: []string{"USD"}, closes: []string{"0.96"}},
+		{pair: "EURGBP", via: []string{"JPY"}, hasErr: true},
+		{pair: "EURCHF", hasErr: true},
+	}
+
+	for _, tc := range tests {
+		p, err := instrument.ParseForexSymbol(tc.pair)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bars, err := rates.Pair(p, tc.via)
+		switch {
+		case tc.hasErr && err == nil:
+			t.Errorf("%s - should have an error", tc.pair)
+		case !tc.hasErr && err != nil:
+			t.Errorf("%s - unexpected error: %v", tc.pair, err)
+		case tc.hasErr:
+		case len(bars) != len(tc.closes):
+			t.Errorf("%s - expected %d bars, got %d", tc.pair, len(tc.closes), len(bars))
+		default:
+			for i, b := range bars {
+				if b.Close.Round(4).String() != tc.closes[i] {
+					t.Errorf("%s - close %s should be %s, got %s", tc.pair, b.Date, tc.closes[i], b.Close.Round(4))
+				}
+				if b.Validate() != nil {
+					t.Errorf("%s - invalid bar: %v", tc.pair, b.Validate())
+				}
+			}
+		}
+	}
+}
+
+func TestInvertHighLow(t *testing.T) {
+	d, _ := typedef.DateFromStr("2019-10-29")
+	bar := ohlc.OHLC{
+		Date:  d,
+		Open:  decimal.RequireFromString("1.2"),
+		High:  decimal.RequireFromString("1.25"),
+		Low:   decimal.RequireFromString("1.0"),
+		Close: decimal.RequireFromString("1.1"),
+	}
+
+	inv := Invert([]ohlc.OHLC{bar})
+	if len(inv) != 1 {
+		t.Fatalf("expected 1 bar, got %d", len(inv))
+	}
+	if inv[0].High.String() != "1" || inv[0].Low.String() != "0.8" {
+		t.Errorf("unexpected high/low: %s/%s", inv[0].High, inv[0].Low)
+	}
+}
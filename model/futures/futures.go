@@ -0,0 +1,241 @@
+// Package futures builds continuous futures series from individual contracts.
+package futures
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+// Contract is futures contract with its price history.
+type Contract struct {
+	Spec instrument.Spec // SecurityType Future with Future details
+	Vec  ohlc.Vec
+
+	// OpenInterest by date; optional, required by OpenInterestCrossover.
+	OpenInterest map[typedef.Date]decimal.Decimal
+}
+
+// Validate checks if Contract has valid content.
+func (c Contract) Validate() error {
+	switch {
+	case c.Spec.Validate() != nil:
+		return fmt.Errorf("invalid Spec: %v", c.Spec.Validate())
+
+	case c.Spec.Future == nil:
+		return fmt.Errorf("Spec %s has no Future details", c.Spec.Symbol)
+	}
+
+	return nil
+}
+
+// expiry returns contract expiry. Last bar date is used
+// if expiry is not specified.
+func (c Contract) expiry() typedef.Date {
+	if !c.Spec.Future.Expiry.Time().IsZero() {
+		return c.Spec.Future.Expiry
+	}
+	dates := c.Vec.Dates()
+	if len(dates) == 0 {
+		return typedef.Date{}
+	}
+	return dates[len(dates)-1]
+}
+
+// Adjustment is method of back adjustment of continuous series.
+type Adjustment int
+
+const (
+	// AdjustNone - no adjustment, price gaps at rolls are kept.
+	AdjustNone Adjustment = iota
+
+	// AdjustDifference - prices before roll are shifted by price difference
+	// of new and old contract at roll date. Build fails if it produces
+	// negative prices e.g. of backwardated curve; use AdjustRatio then.
+	AdjustDifference
+
+	// AdjustRatio - prices before roll are multiplied by price ratio
+	// of new and old contract at roll date. Keeps percentage returns.
+	AdjustRatio
+)
+
+// Roll records switch from one contract to next one.
+type Roll struct {
+	Date typedef.Date // first bar of To contract
+	From string
+	To   string
+
+	// Adjustment applied to bars before Date by this roll;
+	// price difference or ratio depending on Adjustment method.
+	// It is computed from closes of both contracts at roll date.
+	Adjustment decimal.Decimal
+
+	// Forced roll - From contract ended before roll rule was met.
+	// Adjustment is computed from the last close of From contract
+	// and the close of the first following bar of To contract.
+	Forced bool
+}
+
+// Series is continuous futures series.
+type Series struct {
+	Vec   ohlc.Vec
+	Rolls []Roll
+}
+
+// Build stitches contracts into continuous series.
+// Contracts are sorted by delivery date; bars of front contract are used
+// until roll rule is met, bars of next contract are used from the roll date.
+// Prices before each roll are adjusted by adj method, volume is kept.
+func Build(contracts []Contract, rule RollRule, adj Adjustment) (Series, error) {
+	output := Series{}
+
+	if len(contracts) == 0 {
+		return output, fmt.Errorf("no contracts")
+	}
+	for _, c := range contracts {
+		if c.Validate() != nil {
+			return output, fmt.Errorf("invalid contract: %v", c.Validate())
+		}
+	}
+
+	cs := make([]Contract, len(contracts))
+	copy(cs, contracts)
+	sort.SliceStable(cs, func(i, j int) bool {
+		fi, fj := cs[i].Spec.Future, cs[j].Spec.Future
+		if fi.Year != fj.Year {
+			return fi.Year < fj.Year
+		}
+		return fi.Month < fj.Month
+	})
+
+	bars := []ohlc.OHLC{}
+	rolls := []Roll{}
+	var prev ohlc.OHLC // last bar added to bars
+	for i, front := range cs {
+		var next *Contract
+		if i+1 < len(cs) {
+			next = &cs[i+1]
+		}
+
+		rolled := false
+		for _, bar := range front.Vec.Data() {
+			if len(bars) > 0 && !bar.Date.Time().After(prev.Date.Time()) {
+				continue // already covered by previous contract
+			}
+
+			if next != nil {
+				nbar, err := next.Vec.At(bar.Date)
+				if err == nil && rule.Roll(bar.Date, front, *next) {
+					roll, err := mkRoll(bar, nbar, adj)
+					if err != nil {
+						return output, fmt.Errorf("roll %s -> %s at %s: %v",
+							front.Spec.Symbol, next.Spec.Symbol, bar.Date, err)
+					}
+					roll.From, roll.To = front.Spec.Symbol, next.Spec.Symbol
+					rolls = append(rolls, roll)
+					rolled = true
+					break
+				}
+			}
+
+			bars = append(bars, bar)
+			prev = bar
+		}
+
+		if rolled || next == nil || len(bars) == 0 {
+			continue
+		}
+
+		// front contract ended before rule was met
+		for _, nbar := range next.Vec.Data() {
+			if !nbar.Date.Time().After(prev.Date.Time()) {
+				continue
+			}
+			roll, err := mkRoll(prev, nbar, adj)
+			if err != nil {
+				return output, fmt.Errorf("forced roll %s -> %s at %s: %v",
+					front.Spec.Symbol, next.Spec.Symbol, nbar.Date, err)
+			}
+			roll.From, roll.To = front.Spec.Symbol, next.Spec.Symbol
+			roll.Forced = true
+			rolls = append(rolls, roll)
+			break
+		}
+	}
+
+	if err := adjust(bars, rolls, adj); err != nil {
+		return output, err
+	}
+
+	vec, err := ohlc.NewVec(bars, cs[0].Vec.Timeframe())
+	if err != nil {
+		return output, fmt.Errorf("invalid continuous series: %v", err)
+	}
+	output.Vec = vec
+	output.Rolls = rolls
+
+	return output, nil
+}
+
+// mkRoll computes adjustment of roll from old bar to new bar.
+func mkRoll(oldBar, newBar ohlc.OHLC, adj Adjustment) (Roll, error) {
+	roll := Roll{Date: newBar.Date}
+
+	switch adj {
+	case AdjustNone:
+	case AdjustDifference:
+		roll.Adjustment = newBar.Close.Sub(oldBar.Close)
+	case AdjustRatio:
+		if !oldBar.Close.IsPositive() {
+			return roll, fmt.Errorf("ratio adjustment needs positive close, got %s", oldBar.Close)
+		}
+		roll.Adjustment = newBar.Close.Div(oldBar.Close)
+	default:
+		return roll, fmt.Errorf("unknown adjustment: %d", adj)
+	}
+
+	return roll, nil
+}
+
+// adjust applies cumulative roll adjustments to bars before each roll.
+func adjust(bars []ohlc.OHLC, rolls []Roll, adj Adjustment) error {
+	if adj == AdjustNone || len(rolls) == 0 {
+		return nil
+	}
+
+	diff := decimal.Zero
+	ratio := decimal.New(1, 0)
+	r := len(rolls) - 1
+	for i := len(bars) - 1; i >= 0; i-- {
+		for r >= 0 && bars[i].Date.Time().Before(rolls[r].Date.Time()) {
+			switch adj {
+			case AdjustDifference:
+				diff = diff.Add(rolls[r].Adjustment)
+			case AdjustRatio:
+				ratio = ratio.Mul(rolls[r].Adjustment)
+			}
+			r--
+		}
+
+		b := &bars[i]
+		switch adj {
+		case AdjustDifference:
+			b.Open, b.High, b.Low, b.Close =
+				b.Open.Add(diff), b.High.Add(diff), b.Low.Add(diff), b.Close.Add(diff)
+			if b.Open.IsNegative() || b.High.IsNegative() || b.Low.IsNegative() || b.Close.IsNegative() {
+				return fmt.Errorf("difference adjustment %s gives negative price at %s (low %s); use ratio adjustment",
+					diff, b.Date, b.Low)
+			}
+		case AdjustRatio:
+			b.Open, b.High, b.Low, b.Close =
+				b.Open.Mul(ratio), b.High.Mul(ratio), b.Low.Mul(ratio), b.Close.Mul(ratio)
+		}
+	}
+
+	return nil
+}
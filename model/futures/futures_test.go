@@ -0,0 +1,155 @@
+package futures
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+func mkDate(s string) typedef.Date {
+	d, err := typedef.DateFromStr(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// mkContract creates contract with bars at dates starting with start
+// (closes and volumes given), each bar at next calendar day.
+func mkContract(t *testing.T, sym, start string, closes []int64, volumes []int64) Contract {
+	t.Helper()
+
+	spec, err := instrument.ParseSpec(sym, instrument.Future)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := mkDate(start).Time()
+	bars := make([]ohlc.OHLC, 0, len(closes))
+	for i, c := range closes {
+		p := decimal.New(c, 0)
+		bars = append(bars, ohlc.OHLC{
+			Date: typedef.Date(d.AddDate(0, 0, i)),
+			Open: p, High: p, Low: p, Close: p,
+			Volume: decimal.New(volumes[i], 0),
+		})
+	}
+	vec, err := ohlc.NewVec(bars, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return Contract{Spec: spec, Vec: vec}
+}
+
+func TestBuild(t *testing.T) {
+	// ESH19 has bars 03-01 .. 03-05, ESM19 03-03 .. 03-07
+	h := mkContract(t, "ESH19", "2019-03-01", []int64{100, 101, 102, 103, 104}, []int64{50, 50, 40, 10, 5})
+	m := mkContract(t, "ESM19", "2019-03-03", []int64{112, 113, 114, 115, 116}, []int64{10, 30, 60, 60, 60})
+
+	tests := []struct {
+		label    string
+		rule     RollRule
+		adj      Adjustment
+		rollDate string
+		closes   []string
+	}{
+		{
+			label:    "volume crossover, no adjustment",
+			rule:     VolumeCrossover{},
+			adj:      AdjustNone,
+			rollDate: "2019-03-04",
+			closes:   []string{"100", "101", "102", "113", "114", "115", "116"},
+		},
+		{
+			label:    "volume crossover, difference",
+			rule:     VolumeCrossover{},
+			adj:      AdjustDifference,
+			rollDate: "2019-03-04",
+			closes:   []string{"110", "111", "112", "113", "114", "115", "116"},
+		},
+		{
+			label:    "days before expiry, ratio",
+			rule:     DaysBeforeExpiry(2),
+			adj:      AdjustRatio,
+			rollDate: "2019-03-03",
+			closes:   []string{"109.8", "110.9", "112", "113", "114", "115", "116"},
+		},
+	}
+
+	for _, tc := range tests {
+		// contract order must not matter
+		s, err := Build([]Contract{m, h}, tc.rule, tc.adj)
+		if err != nil {
+			t.Errorf("%s - unexpected error: %v", tc.label, err)
+			continue
+		}
+
+		if len(s.Rolls) != 1 {
+			t.Errorf("%s - expected 1 roll, got %d", tc.label, len(s.Rolls))
+			continue
+		}
+		roll := s.Rolls[0]
+		if roll.Date.String() != tc.rollDate || roll.From != "ESH19" || roll.To != "ESM19" || roll.Forced {
+			t.Errorf("%s - unexpected roll: %+v", tc.label, roll)
+		}
+
+		data := s.Vec.Data()
+		if len(data) != len(tc.closes) {
+			t.Errorf("%s - expected %d bars, got %d", tc.label, len(tc.closes), len(data))
+			continue
+		}
+		for i, bar := range data {
+			if !bar.Close.Round(2).Equal(decimal.RequireFromString(tc.closes[i])) {
+				t.Errorf("%s - %s close should be %s, got %s", tc.label, bar.Date, tc.closes[i], bar.Close)
+			}
+		}
+	}
+}
+
+func TestBuildForcedRoll(t *testing.T) {
+	// no overlapping dates, rule can not be met
+	h := mkContract(t, "ESH19", "2019-03-01", []int64{100, 101}, []int64{50, 50})
+	m := mkContract(t, "ESM19", "2019-03-05", []int64{110, 111}, []int64{50, 50})
+
+	s, err := Build([]Contract{h, m}, VolumeCrossover{}, AdjustDifference)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Rolls) != 1 || !s.Rolls[0].Forced || s.Rolls[0].Date.String() != "2019-03-05" {
+		t.Fatalf("expected forced roll at 2019-03-05, got %+v", s.Rolls)
+	}
+	first, _ := s.Vec.AtIdx(0)
+	if !first.Close.Equal(decimal.New(109, 0)) {
+		t.Errorf("first close should be adjusted by 9 to 109, got %s", first.Close)
+	}
+}
+
+func TestBuildBackwardation(t *testing.T) {
+	// difference adjustment shifts older bars below zero
+	f := mkContract(t, "CLF20", "2020-01-01", []int64{10, 20}, []int64{50, 50})
+	g := mkContract(t, "CLG20", "2020-01-02", []int64{5}, []int64{50})
+
+	_, err := Build([]Contract{f, g}, DaysBeforeExpiry(1), AdjustDifference)
+	switch {
+	case err == nil:
+		t.Errorf("difference adjustment to negative prices - should have an error")
+	case !strings.Contains(err.Error(), "ratio"):
+		t.Errorf("error should suggest ratio adjustment, got: %v", err)
+	}
+
+	s, err := Build([]Contract{f, g}, DaysBeforeExpiry(1), AdjustRatio)
+	if err != nil {
+		t.Fatalf("ratio adjustment - unexpected error: %v", err)
+	}
+	first, _ := s.Vec.AtIdx(0)
+	if !first.Close.IsPositive() {
+		t.Errorf("ratio adjusted close should be positive, got %s", first.Close)
+	}
+}
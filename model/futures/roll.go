@@ -0,0 +1,58 @@
+package futures
+
+import (
+	"github.com/profioss/trada/pkg/typedef"
+)
+
+// RollRule decides when to roll from front contract to next one.
+type RollRule interface {
+	// Roll reports whether to roll from front to next contract at date d.
+	// Both contracts have bar at date d.
+	Roll(d typedef.Date, front, next Contract) bool
+}
+
+// DaysBeforeExpiry rolls given number of calendar days before expiry
+// of front contract. Last bar date of front contract is used
+// if its expiry is not specified.
+type DaysBeforeExpiry int
+
+// Roll implements RollRule.
+func (days DaysBeforeExpiry) Roll(d typedef.Date, front, next Contract) bool {
+	expiry := front.expiry().Time()
+	return !d.Time().AddDate(0, 0, int(days)).Before(expiry)
+}
+
+// VolumeCrossover rolls when volume of next contract exceeds volume
+// of front contract.
+type VolumeCrossover struct{}
+
+// Roll implements RollRule.
+func (VolumeCrossover) Roll(d typedef.Date, front, next Contract) bool {
+	fbar, err := front.Vec.At(d)
+	if err != nil {
+		return false
+	}
+	nbar, err := next.Vec.At(d)
+	if err != nil {
+		return false
+	}
+	return nbar.Volume.GreaterThan(fbar.Volume)
+}
+
+// OpenInterestCrossover rolls when open interest of next contract exceeds
+// open interest of front contract. Contracts without open interest data
+// at date d are not rolled.
+type OpenInterestCrossover struct{}
+
+// Roll implements RollRule.
+func (OpenInterestCrossover) Roll(d typedef.Date, front, next Contract) bool {
+	foi, ok := front.OpenInterest[d]
+	if !ok {
+		return false
+	}
+	noi, ok := next.OpenInterest[d]
+	if !ok {
+		return false
+	}
+	return noi.GreaterThan(foi)
+}
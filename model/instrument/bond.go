@@ -0,0 +1,34 @@
+package instrument
+
+import (
+	"fmt"
+
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+// BondSpec is bond specification.
+// Bond symbols (CUSIP, ISIN, etc.) carry no details so all of them
+// have to be specified explicitly.
+type BondSpec struct {
+	Coupon    decimal.Decimal // annual coupon rate in percent
+	Maturity  typedef.Date
+	FaceValue decimal.Decimal
+}
+
+// Validate checks if BondSpec has valid content.
+func (b BondSpec) Validate() error {
+	switch {
+	case b.Coupon.IsNegative():
+		return fmt.Errorf("Coupon: %s is less than zero", b.Coupon)
+
+	case b.Maturity.Time().IsZero():
+		return fmt.Errorf("Maturity not defined")
+
+	case !b.FaceValue.IsPositive():
+		return fmt.Errorf("FaceValue: %s is not positive", b.FaceValue)
+	}
+
+	return nil
+}
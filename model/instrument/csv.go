@@ -4,17 +4,25 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
+	"sort"
+	"strconv"
 	"strings"
+
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
 )
 
 // SpecLstToCSV exports []Spec to CSV.
+// Security type specific details are exported to attrs column
+// as space delimited key=value pairs e.g. "multiplier=50 tick-size=0.25".
 func SpecLstToCSV(w io.Writer, ss []Spec) error {
 	data := make([][]string, 0, len(ss))
 	// CSV output header
-	data = append(data, []string{"sym", "name", "security"})
+	data = append(data, []string{"sym", "name", "security", "exchange", "attrs"})
 	for _, s := range ss {
 		data = append(data,
-			[]string{s.Symbol, s.Description, s.SecurityType.String()})
+			[]string{s.Symbol, s.Description, s.SecurityType.String(), s.Exchange, specAttrs(s)})
 	}
 
 	wcsv := csv.NewWriter(w)
@@ -29,34 +37,213 @@ func SpecLstToCSV(w io.Writer, ss []Spec) error {
 
 // SpecLstFromCSV imports []Spec from CSV.
 // NOTE this function expects data exported by complementary
-// SpecLstToCSV function.
+// SpecLstToCSV function. Columns exchange and attrs are optional.
+// Symbols are parsed according to security type (see ParseSpec);
+// symbols which do not parse e.g. future without two digit year
+// are kept without security type details (Forex, Future, Option).
 func SpecLstFromCSV(r io.Reader) ([]Spec, error) {
+	return specLstFromCSV(r, false)
+}
+
+// SpecLstFromCSVStrict is like SpecLstFromCSV but it fails on symbols
+// which do not parse according to their security type.
+func SpecLstFromCSVStrict(r io.Reader) ([]Spec, error) {
+	return specLstFromCSV(r, true)
+}
+
+func specLstFromCSV(r io.Reader, strict bool) ([]Spec, error) {
 	output := []Spec{}
 
 	rcsv := csv.NewReader(r)
 	rcsv.Comma = ';'
+	rcsv.FieldsPerRecord = -1 // optional columns
 	data, err := rcsv.ReadAll()
 	if err != nil {
 		return output, fmt.Errorf("CSV read error: %v", err)
 	}
+	if len(data) == 0 {
+		return output, nil
+	}
 
 	for _, row := range data[1:] { // skip CSV header
 		if len(row) < 3 {
 			return output, fmt.Errorf("expected 3 columns (sym;name;security), got %d: %#v", len(row), row)
 		}
-		spec := Spec{
-			Symbol:      strings.TrimSpace(row[0]),
-			Description: strings.TrimSpace(row[1]),
-		}
 
 		sec, err := SecurityFromString(row[2])
 		if err != nil {
 			return output, err
 		}
-		spec.SecurityType = sec
+		sym := strings.TrimSpace(row[0])
+		spec, err := ParseSpec(sym, sec)
+		if err != nil && strict {
+			return output, err
+		}
+		if err != nil {
+			spec = Spec{Symbol: sym, SecurityType: sec}
+		}
+		spec.Description = strings.TrimSpace(row[1])
+
+		if len(row) > 3 {
+			spec.Exchange = strings.TrimSpace(row[3])
+		}
+		if len(row) > 4 {
+			err = setSpecAttrs(&spec, row[4])
+			if err != nil {
+				return output, fmt.Errorf("%s: %v", spec.Symbol, err)
+			}
+		}
+		if spec.Validate() != nil {
+			return output, fmt.Errorf("%s: %v", spec.Symbol, spec.Validate())
+		}
 
 		output = append(output, spec)
 	}
 
 	return output, nil
 }
+
+// spec attribute keys
+const (
+	attrCurrency      = "currency"
+	attrTickSize      = "tick-size"
+	attrPriceDecimals = "price-decimals"
+	attrQtyDecimals   = "qty-decimals"
+	attrLotSize       = "lot-size"
+	attrExpiry        = "expiry"
+	attrMultiplier    = "multiplier"
+	attrStyle         = "style"
+	attrCoupon        = "coupon"
+	attrMaturity      = "maturity"
+	attrFaceValue     = "face-value"
+)
+
+// specAttrs formats details of Spec which are not part of symbol.
+func specAttrs(s Spec) string {
+	attrs := map[string]string{}
+
+	if s.Currency != "" {
+		attrs[attrCurrency] = s.Currency
+	}
+	if !s.TickSize.IsZero() {
+		attrs[attrTickSize] = s.TickSize.String()
+	}
+	if s.PriceDecimals > 0 {
+		attrs[attrPriceDecimals] = strconv.Itoa(s.PriceDecimals)
+	}
+	if s.QtyDecimals > 0 {
+		attrs[attrQtyDecimals] = strconv.Itoa(s.QtyDecimals)
+	}
+	if !s.LotSize.IsZero() {
+		attrs[attrLotSize] = s.LotSize.String()
+	}
+
+	if f := s.Future; f != nil {
+		if !f.Expiry.Time().IsZero() {
+			attrs[attrExpiry] = f.Expiry.String()
+		}
+		if !f.Multiplier.IsZero() {
+			attrs[attrMultiplier] = f.Multiplier.String()
+		}
+	}
+
+	if o := s.Option; o != nil {
+		attrs[attrStyle] = o.Style.String()
+		if !o.Multiplier.IsZero() {
+			attrs[attrMultiplier] = o.Multiplier.String()
+		}
+	}
+
+	if b := s.Bond; b != nil {
+		attrs[attrCoupon] = b.Coupon.String()
+		attrs[attrMaturity] = b.Maturity.String()
+		attrs[attrFaceValue] = b.FaceValue.String()
+	}
+
+	return formatAttrs(attrs)
+}
+
+// setSpecAttrs sets details of Spec parsed from attrs string.
+func setSpecAttrs(s *Spec, str string) error {
+	attrs, err := parseAttrs(str)
+	if err != nil {
+		return err
+	}
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	if s.SecurityType == Bond && s.Bond == nil {
+		s.Bond = &BondSpec{}
+	}
+
+	for k, v := range attrs {
+		switch {
+		case k == attrCurrency:
+			s.Currency = strings.ToUpper(v)
+		case k == attrTickSize:
+			s.TickSize, err = decimal.NewFromString(v)
+		case k == attrPriceDecimals:
+			s.PriceDecimals, err = strconv.Atoi(v)
+			if err == nil && s.PriceDecimals == 0 {
+				// zero means default precision, whole prices are set by tick size
+				err = fmt.Errorf("use %s=1 for whole prices", attrTickSize)
+			}
+		case k == attrQtyDecimals:
+			s.QtyDecimals, err = strconv.Atoi(v)
+			if err == nil && s.QtyDecimals == 0 {
+				err = fmt.Errorf("use %s=1 for whole quantities", attrLotSize)
+			}
+		case k == attrLotSize:
+			s.LotSize, err = decimal.NewFromString(v)
+
+		case s.Future != nil && k == attrExpiry:
+			s.Future.Expiry, err = typedef.DateFromStr(v)
+		case s.Future != nil && k == attrMultiplier:
+			s.Future.Multiplier, err = decimal.NewFromString(v)
+
+		case s.Option != nil && k == attrStyle:
+			s.Option.Style, err = OptionStyleFromString(v)
+		case s.Option != nil && k == attrMultiplier:
+			s.Option.Multiplier, err = decimal.NewFromString(v)
+
+		case s.Bond != nil && k == attrCoupon:
+			s.Bond.Coupon, err = decimal.NewFromString(v)
+		case s.Bond != nil && k == attrMaturity:
+			s.Bond.Maturity, err = typedef.DateFromStr(v)
+		case s.Bond != nil && k == attrFaceValue:
+			s.Bond.FaceValue, err = decimal.NewFromString(v)
+
+		default:
+			return fmt.Errorf("unknown attribute %q for %s", k, s.SecurityType)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid attribute %s=%s: %v", k, v, err)
+		}
+	}
+
+	return nil
+}
+
+// formatAttrs formats attributes as sorted space delimited key=value pairs.
+func formatAttrs(attrs map[string]string) string {
+	lst := make([]string, 0, len(attrs))
+	for k, v := range attrs {
+		lst = append(lst, k+"="+v)
+	}
+	sort.Strings(lst)
+	return strings.Join(lst, " ")
+}
+
+// parseAttrs parses space delimited key=value pairs.
+func parseAttrs(str string) (map[string]string, error) {
+	attrs := map[string]string{}
+	for _, kv := range strings.Fields(str) {
+		i := strings.IndexByte(kv, '=')
+		if i < 1 {
+			return attrs, fmt.Errorf("invalid attribute %q: expected key=value", kv)
+		}
+		attrs[kv[:i]] = kv[i+1:]
+	}
+	return attrs, nil
+}
@@ -0,0 +1,71 @@
+package instrument
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ForexSpec is currency pair specification.
+// Rate of the pair is price of 1 unit of Base currency in Quote currency
+// e.g. EURUSD 1.1134 means 1 EUR = 1.1134 USD.
+type ForexSpec struct {
+	Base  string // ISO 4217 currency code e.g. EUR
+	Quote string // ISO 4217 currency code e.g. USD
+}
+
+// Validate checks if ForexSpec has valid content.
+func (f ForexSpec) Validate() error {
+	switch {
+	case ValidateCurrency(f.Base) != nil:
+		return fmt.Errorf("Base: %v", ValidateCurrency(f.Base))
+
+	case ValidateCurrency(f.Quote) != nil:
+		return fmt.Errorf("Quote: %v", ValidateCurrency(f.Quote))
+
+	case f.Base == f.Quote:
+		return fmt.Errorf("Base and Quote are the same currency: %s", f.Base)
+	}
+
+	return nil
+}
+
+// Symbol formats pair symbol e.g. EURUSD.
+func (f ForexSpec) Symbol() string {
+	return f.Base + f.Quote
+}
+
+// Inverse returns inverse pair e.g. USDEUR for EURUSD.
+func (f ForexSpec) Inverse() ForexSpec {
+	return ForexSpec{Base: f.Quote, Quote: f.Base}
+}
+
+// ParseForexSymbol parses currency pair symbol e.g. EURUSD, EUR/USD or eur.usd.
+func ParseForexSymbol(sym string) (ForexSpec, error) {
+	output := ForexSpec{}
+
+	s := strings.ToUpper(strings.TrimSpace(sym))
+	s = strings.NewReplacer("/", "", ".", "", "-", "", "_", "").Replace(s)
+	if len(s) != 6 {
+		return output, fmt.Errorf("invalid forex symbol %q: expected BASE + QUOTE currency codes", sym)
+	}
+
+	output.Base, output.Quote = s[:3], s[3:]
+	if output.Validate() != nil {
+		return output, fmt.Errorf("invalid forex symbol %q: %v", sym, output.Validate())
+	}
+
+	return output, nil
+}
+
+// ValidateCurrency checks if c looks like ISO 4217 currency code e.g. USD.
+func ValidateCurrency(c string) error {
+	if len(c) != 3 {
+		return fmt.Errorf("invalid currency code %q: expected 3 letters", c)
+	}
+	for _, r := range c {
+		if r < 'A' || r > 'Z' {
+			return fmt.Errorf("invalid currency code %q: expected upper case letters", c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package instrument
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+// FutureSpec is futures contract specification.
+// Tick size is defined by Spec.TickSize.
+type FutureSpec struct {
+	Root       string          // e.g. ES, CL
+	Year       int             // contract (delivery) year
+	Month      time.Month      // contract (delivery) month
+	Expiry     typedef.Date    // last trading day; optional
+	Multiplier decimal.Decimal // contract value = price * Multiplier
+}
+
+// CME month codes of futures contracts.
+// https://www.cmegroup.com/month-codes.html
+var futureMonthCodes = [...]byte{'F', 'G', 'H', 'J', 'K', 'M', 'N', 'Q', 'U', 'V', 'X', 'Z'}
+
+// FutureMonthCode returns CME month code e.g. Z for December.
+func FutureMonthCode(m time.Month) (byte, error) {
+	if m < time.January || m > time.December {
+		return 0, fmt.Errorf("invalid month: %d", m)
+	}
+	return futureMonthCodes[m-1], nil
+}
+
+// FutureMonthFromCode parses CME month code e.g. Z is December.
+func FutureMonthFromCode(c byte) (time.Month, error) {
+	for i, code := range futureMonthCodes {
+		if code == c {
+			return time.Month(i + 1), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid month code: %q", c)
+}
+
+// Validate checks if FutureSpec has valid content.
+func (f FutureSpec) Validate() error {
+	switch {
+	case f.Root == "":
+		return fmt.Errorf("Root not defined")
+
+	case f.Year < 1900:
+		return fmt.Errorf("invalid Year: %d", f.Year)
+
+	case f.Month < time.January || f.Month > time.December:
+		return fmt.Errorf("invalid Month: %d", f.Month)
+
+	case f.Multiplier.IsNegative():
+		return fmt.Errorf("Multiplier: %s is less than zero", f.Multiplier)
+	}
+
+	return nil
+}
+
+// Symbol formats CME style symbol: root + month code + 2 digit year
+// e.g. ESZ19 is E-mini S&P 500 December 2019 contract.
+func (f FutureSpec) Symbol() string {
+	code, err := FutureMonthCode(f.Month)
+	if err != nil {
+		return f.Root
+	}
+	return fmt.Sprintf("%s%c%02d", f.Root, code, f.Year%100)
+}
+
+// ParseFutureSymbol parses CME style symbol e.g. ESZ19 or ESZ9.
+// Single digit year is resolved to the nearest year not older than
+// one year before ref.
+func ParseFutureSymbol(sym string, ref time.Time) (FutureSpec, error) {
+	output := FutureSpec{}
+	s := strings.ToUpper(strings.TrimSpace(sym))
+
+	// year digits at the end
+	i := len(s)
+	for i > 0 && s[i-1] >= '0' && s[i-1] <= '9' {
+		i--
+	}
+	digits := s[i:]
+	if len(digits) < 1 || len(digits) > 2 || i < 2 {
+		return output, fmt.Errorf("invalid future symbol %q: expected ROOT + month code + year", sym)
+	}
+
+	month, err := FutureMonthFromCode(s[i-1])
+	if err != nil {
+		return output, fmt.Errorf("invalid future symbol %q: %v", sym, err)
+	}
+
+	y, err := strconv.Atoi(digits)
+	if err != nil {
+		return output, fmt.Errorf("invalid future symbol %q: %v", sym, err)
+	}
+	switch len(digits) {
+	case 1:
+		base := ref.Year() - 1
+		year := base - base%10 + y
+		if year < base {
+			year += 10
+		}
+		output.Year = year
+	case 2:
+		output.Year = 2000 + y
+	}
+
+	output.Root = s[:i-1]
+	output.Month = month
+
+	return output, output.Validate()
+}
@@ -2,6 +2,10 @@ package instrument
 
 import (
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 // Spec is Instrument specification.
@@ -10,6 +14,61 @@ type Spec struct {
 	Description  string
 	SecurityType Security
 	Exchange     string
+	Currency     string // ISO 4217 code of price currency e.g. USD; optional
+
+	// trading rules, optional; zero value means security type default
+	// see PricePrecision and QtyPrecision
+	TickSize      decimal.Decimal // minimal price increment e.g. 0.25, 0.005
+	PriceDecimals int             // decimal places of price e.g. 3 for JPY pairs
+	QtyDecimals   int             // decimal places of quantity e.g. 8 for BTC
+	LotSize       decimal.Decimal // minimal quantity increment e.g. 100, 0.001
+
+	// security type specific details, optional
+	Forex  *ForexSpec
+	Future *FutureSpec
+	Option *OptionSpec
+	Bond   *BondSpec
+}
+
+// ParseSpec creates Spec of given security type.
+// Details of currency pairs, futures and options are parsed from symbols
+// e.g. EURUSD, CME style ESZ19 or OCC style SPY200117P00287500.
+// Futures require two digit year so the result does not depend on current
+// date; use ParseFutureSymbol with reference date for single digit year.
+func ParseSpec(sym string, sec Security) (Spec, error) {
+	spec := Spec{Symbol: sym, SecurityType: sec}
+
+	switch sec {
+	case Forex:
+		f, err := ParseForexSymbol(sym)
+		if err != nil {
+			return spec, err
+		}
+		spec.Forex = &f
+
+	case Future:
+		if s := strings.TrimSpace(sym); len(s) < 2 || !isDigit(s[len(s)-1]) || !isDigit(s[len(s)-2]) {
+			return spec, fmt.Errorf("invalid future symbol %q: expected two digit year e.g. ESZ19", sym)
+		}
+		f, err := ParseFutureSymbol(sym, time.Time{}) // ref is not used for two digit year
+		if err != nil {
+			return spec, err
+		}
+		spec.Future = &f
+
+	case Option:
+		o, err := ParseOptionSymbol(sym)
+		if err != nil {
+			return spec, err
+		}
+		spec.Option = &o
+	}
+
+	return spec, spec.Validate()
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
 }
 
 // Validate checks if Spec has valid content.
@@ -20,7 +79,55 @@ func (s Spec) Validate() error {
 
 	case s.SecurityType.Validate() != nil:
 		return fmt.Errorf("invalid SecurityType: %v", s.SecurityType.Validate())
+
+	case s.Currency != "" && ValidateCurrency(s.Currency) != nil:
+		return fmt.Errorf("Currency: %v", ValidateCurrency(s.Currency))
+
+	case s.Forex != nil && s.Currency != "" && s.Currency != s.Forex.Quote:
+		return fmt.Errorf("Currency %s does not match quote currency of %s", s.Currency, s.Forex.Symbol())
+
+	case s.TickSize.IsNegative():
+		return fmt.Errorf("TickSize: %s is less than zero", s.TickSize)
+
+	case s.LotSize.IsNegative():
+		return fmt.Errorf("LotSize: %s is less than zero", s.LotSize)
+
+	case s.PriceDecimals < 0 || s.PriceDecimals > maxDecimals:
+		return fmt.Errorf("PriceDecimals: %d out of range 0-%d", s.PriceDecimals, maxDecimals)
+
+	case s.QtyDecimals < 0 || s.QtyDecimals > maxDecimals:
+		return fmt.Errorf("QtyDecimals: %d out of range 0-%d", s.QtyDecimals, maxDecimals)
+
+	case s.Forex != nil && s.SecurityType != Forex,
+		s.Future != nil && s.SecurityType != Future,
+		s.Option != nil && s.SecurityType != Option,
+		s.Bond != nil && s.SecurityType != Bond:
+		return fmt.Errorf("details do not match SecurityType %s", s.SecurityType)
+
+	case s.Forex != nil && s.Forex.Validate() != nil:
+		return fmt.Errorf("invalid Forex: %v", s.Forex.Validate())
+
+	case s.Future != nil && s.Future.Validate() != nil:
+		return fmt.Errorf("invalid Future: %v", s.Future.Validate())
+
+	case s.Option != nil && s.Option.Validate() != nil:
+		return fmt.Errorf("invalid Option: %v", s.Option.Validate())
+
+	case s.Bond != nil && s.Bond.Validate() != nil:
+		return fmt.Errorf("invalid Bond: %v", s.Bond.Validate())
 	}
 
 	return nil
 }
+
+// PriceCurrency returns currency of prices: Currency if set,
+// quote currency of currency pair or empty string if unknown.
+func (s Spec) PriceCurrency() string {
+	switch {
+	case s.Currency != "":
+		return s.Currency
+	case s.Forex != nil:
+		return s.Forex.Quote
+	}
+	return ""
+}
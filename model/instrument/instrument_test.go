@@ -0,0 +1,374 @@
+package instrument
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestParseForexSymbol(t *testing.T) {
+	tests := []struct {
+		sym    string
+		base   string
+		quote  string
+		hasErr bool
+	}{
+		{sym: "EURUSD", base: "EUR", quote: "USD"},
+		{sym: "eur/jpy", base: "EUR", quote: "JPY"},
+		{sym: " GBP.CHF ", base: "GBP", quote: "CHF"},
+		{sym: "EURUS", hasErr: true},
+		{sym: "EUREUR", hasErr: true},
+		{sym: "EUR1SD", hasErr: true},
+	}
+
+	for _, tc := range tests {
+		f, err := ParseForexSymbol(tc.sym)
+		switch {
+		case tc.hasErr && err == nil:
+			t.Errorf("%s - should have an error", tc.sym)
+		case !tc.hasErr && err != nil:
+			t.Errorf("%s - unexpected error: %v", tc.sym, err)
+		case tc.hasErr:
+		case f.Base != tc.base || f.Quote != tc.quote:
+			t.Errorf("%s - expected %s/%s, got %s/%s", tc.sym, tc.base, tc.quote, f.Base, f.Quote)
+		case f.Inverse().Symbol() != tc.quote+tc.base:
+			t.Errorf("%s - Inverse() should be %s%s, got %s", tc.sym, tc.quote, tc.base, f.Inverse().Symbol())
+		}
+	}
+}
+
+func TestParseFutureSymbol(t *testing.T) {
+	ref := time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		sym    string
+		root   string
+		year   int
+		month  time.Month
+		out    string
+		hasErr bool
+	}{
+		{sym: "ESZ19", root: "ES", year: 2019, month: time.December, out: "ESZ19"},
+		{sym: "ESZ9", root: "ES", year: 2019, month: time.December, out: "ESZ19"},
+		{sym: "CLF0", root: "CL", year: 2020, month: time.January, out: "CLF20"},
+		{sym: "ZNH8", root: "ZN", year: 2018, month: time.March, out: "ZNH18"},
+		{sym: "6EM21", root: "6E", year: 2021, month: time.June, out: "6EM21"},
+		{sym: "ESA19", hasErr: true},
+		{sym: "Z19", hasErr: true},
+		{sym: "ES", hasErr: true},
+		{sym: "ESZ2019", hasErr: true},
+	}
+
+	for _, tc := range tests {
+		f, err := ParseFutureSymbol(tc.sym, ref)
+		switch {
+		case tc.hasErr && err == nil:
+			t.Errorf("%s - should have an error", tc.sym)
+		case !tc.hasErr && err != nil:
+			t.Errorf("%s - unexpected error: %v", tc.sym, err)
+		case tc.hasErr:
+		case f.Root != tc.root || f.Year != tc.year || f.Month != tc.month:
+			t.Errorf("%s - expected %s %d %s, got %s %d %s",
+				tc.sym, tc.root, tc.year, tc.month, f.Root, f.Year, f.Month)
+		case f.Symbol() != tc.out:
+			t.Errorf("%s - Symbol() should be %s, got %s", tc.sym, tc.out, f.Symbol())
+		}
+	}
+}
+
+func TestParseOptionSymbol(t *testing.T) {
+	tests := []struct {
+		sym        string
+		underlying string
+		expiry     string
+		right      OptionRight
+		strike     string
+		out        string
+		hasErr     bool
+	}{
+		{
+			sym: "AAPL  191220C00150000", underlying: "AAPL", expiry: "2019-12-20",
+			right: Call, strike: "150", out: "AAPL  191220C00150000",
+		},
+		{
+			sym: "SPY200117P00287500", underlying: "SPY", expiry: "2020-01-17",
+			right: Put, strike: "287.5", out: "SPY   200117P00287500",
+		},
+		{sym: "AAPL191220X00150000", hasErr: true},
+		{sym: "AAPL191320C00150000", hasErr: true},
+		{sym: "191220C00150000", hasErr: true},
+		{sym: "AAPL", hasErr: true},
+	}
+
+	for _, tc := range tests {
+		o, err := ParseOptionSymbol(tc.sym)
+		switch {
+		case tc.hasErr && err == nil:
+			t.Errorf("%s - should have an error", tc.sym)
+		case !tc.hasErr && err != nil:
+			t.Errorf("%s - unexpected error: %v", tc.sym, err)
+		case tc.hasErr:
+		case o.Underlying != tc.underlying || o.Expiry.String() != tc.expiry ||
+			o.Right != tc.right || o.Strike.String() != tc.strike:
+			t.Errorf("%s - unexpected result: %s %s %s %s",
+				tc.sym, o.Underlying, o.Expiry, o.Right, o.Strike)
+		case o.Symbol() != tc.out:
+			t.Errorf("%s - Symbol() should be %q, got %q", tc.sym, tc.out, o.Symbol())
+		}
+	}
+}
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		sym    string
+		sec    Security
+		hasErr bool
+	}{
+		{sym: "SPY", sec: Equity},
+		{sym: "EURUSD", sec: Forex},
+		{sym: "ESZ19", sec: Future},
+		{sym: "ESZ9", sec: Future, hasErr: true}, // would depend on current date
+		{sym: "SPY", sec: Future, hasErr: true},
+		{sym: "EUR", sec: Forex, hasErr: true},
+		{sym: "SPX191220C03000000", sec: Option},
+		{sym: "SPX", sec: Option, hasErr: true},
+	}
+
+	for _, tc := range tests {
+		_, err := ParseSpec(tc.sym, tc.sec)
+		switch {
+		case tc.hasErr && err == nil:
+			t.Errorf("%s %s - should have an error", tc.sym, tc.sec)
+		case !tc.hasErr && err != nil:
+			t.Errorf("%s %s - unexpected error: %v", tc.sym, tc.sec, err)
+		}
+	}
+
+}
+
+func TestSpecLstFromCSVMixed(t *testing.T) {
+	// symbols not parsing for their security type are kept as plain specs
+	data := "sym;name;security\n" +
+		"SPY;SPDR S&P 500;equity\n" +
+		"ESZ9;E-mini;future\n" +
+		"ESZ19;E-mini;future\n" +
+		"EUR;Euro;forex\n" +
+		"EURUSD;Euro / US Dollar;forex\n"
+
+	specs, err := SpecLstFromCSV(bytes.NewBufferString(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(specs) != 5 {
+		t.Fatalf("expected 5 specs, got %d", len(specs))
+	}
+	tests := []struct {
+		symbol  string
+		details bool
+	}{
+		{"SPY", false},
+		{"ESZ9", false},
+		{"ESZ19", true},
+		{"EUR", false},
+		{"EURUSD", true},
+	}
+	for i, tc := range tests {
+		s := specs[i]
+		details := s.Future != nil || s.Forex != nil
+		switch {
+		case s.Symbol != tc.symbol:
+			t.Errorf("%s - unexpected symbol %s", tc.symbol, s.Symbol)
+		case details != tc.details:
+			t.Errorf("%s - security type details expected: %v, got %+v", tc.symbol, tc.details, s)
+		}
+	}
+
+	if _, err := SpecLstFromCSVStrict(bytes.NewBufferString(data)); err == nil {
+		t.Errorf("strict - invalid future symbol should have an error")
+	}
+}
+
+func TestSpecAttrsZeroDecimals(t *testing.T) {
+	tests := []struct {
+		label   string
+		attrs   string
+		priceDP int
+		qtyDP   int
+		hasErr  bool
+	}{
+		{"price decimals", "price-decimals=3", 3, 8, false},
+		{"zero price decimals", "price-decimals=0", 0, 0, true},
+		{"zero qty decimals", "qty-decimals=0", 0, 0, true},
+		{"whole units by tick and lot size", "tick-size=1 lot-size=1", 0, 0, false},
+	}
+
+	for _, tc := range tests {
+		data := "sym;name;security;exchange;attrs\nbtcusd;Bitcoin;crypto;;" + tc.attrs + "\n"
+		specs, err := SpecLstFromCSV(bytes.NewBufferString(data))
+		if tc.hasErr {
+			if err == nil {
+				t.Errorf("%s - should have an error", tc.label)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s - unexpected error: %v", tc.label, err)
+			continue
+		}
+		if dp := specs[0].PricePrecision(); dp != tc.priceDP {
+			t.Errorf("%s - PricePrecision should be %d, got %d", tc.label, tc.priceDP, dp)
+		}
+		if dp := specs[0].QtyPrecision(); dp != tc.qtyDP {
+			t.Errorf("%s - QtyPrecision should be %d, got %d", tc.label, tc.qtyDP, dp)
+		}
+	}
+}
+
+func TestSpecLstCSVRoundTrip(t *testing.T) {
+	fut, err := ParseSpec("ESZ19", Future)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fut.Description = "E-mini S&P 500 Dec 2019"
+	fut.Exchange = "CME"
+	fut.Future.Multiplier = decimal.New(50, 0)
+	fut.TickSize = decimal.New(25, -2)
+	fut.Future.Expiry, _ = typedef.DateFromStr("2019-12-20")
+
+	opt, err := ParseSpec("SPX191220C03000000", Option)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt.Option.Style = European
+	opt.Option.Multiplier = decimal.New(100, 0)
+
+	maturity, _ := typedef.DateFromStr("2029-05-15")
+	bond := Spec{
+		Symbol:       "US9128286T26",
+		Description:  "UST 2 3/8 05/15/29",
+		SecurityType: Bond,
+		Bond: &BondSpec{
+			Coupon:    decimal.New(2375, -3),
+			Maturity:  maturity,
+			FaceValue: decimal.New(1000, 0),
+		},
+	}
+
+	eq := Spec{Symbol: "SPY", Description: "SPDR S&P 500", SecurityType: Equity, Exchange: "NYSE", Currency: "USD"}
+
+	input := []Spec{eq, fut, opt, bond}
+	buf := &bytes.Buffer{}
+	if err := SpecLstToCSV(buf, input); err != nil {
+		t.Fatal(err)
+	}
+	output, err := SpecLstFromCSV(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(output) != len(input) {
+		t.Fatalf("expected %d specs, got %d", len(input), len(output))
+	}
+
+	for i := range input {
+		in, out := input[i], output[i]
+		if in.Symbol != out.Symbol || in.Description != out.Description ||
+			in.SecurityType != out.SecurityType || in.Exchange != out.Exchange ||
+			specAttrs(in) != specAttrs(out) {
+			t.Errorf("%s - round trip mismatch:\n%#v\n%#v", in.Symbol, in, out)
+		}
+	}
+
+	// legacy 3 column format
+	legacy := "sym;name;security\nSPY;SPDR S&P 500;equity\nESZ19;E-mini;future\n"
+	output, err = SpecLstFromCSV(bytes.NewBufferString(legacy))
+	switch {
+	case err != nil:
+		t.Errorf("legacy format - unexpected error: %v", err)
+	case len(output) != 2 || output[1].Future == nil:
+		t.Errorf("legacy format - unexpected result: %#v", output)
+	}
+}
+
+func TestPrecision(t *testing.T) {
+	tests := []struct {
+		label    string
+		spec     Spec
+		priceDP  int
+		qtyDP    int
+		price    string
+		rounded  string
+		qty      string
+		roundQty string
+	}{
+		{
+			label:   "equity default",
+			spec:    Spec{Symbol: "SPY", SecurityType: Equity},
+			priceDP: 2, qtyDP: 0,
+			price: "312.456", rounded: "312.46", qty: "10.7", roundQty: "10",
+		},
+		{
+			label:   "JPY pair",
+			spec:    Spec{Symbol: "USDJPY", SecurityType: Forex, PriceDecimals: 3},
+			priceDP: 3, qtyDP: 0,
+			price: "108.6544", rounded: "108.654", qty: "1000", roundQty: "1000",
+		},
+		{
+			label:   "sub-penny stock",
+			spec:    Spec{Symbol: "XYZ", SecurityType: Equity, TickSize: decimal.New(1, -4)},
+			priceDP: 4, qtyDP: 0,
+			price: "0.01234", rounded: "0.0123", qty: "150", roundQty: "150",
+		},
+		{
+			label:   "BTC pair in USD",
+			spec:    Spec{Symbol: "btcusd", SecurityType: Crypto, PriceDecimals: 2},
+			priceDP: 2, qtyDP: 8,
+			price: "7321.129", rounded: "7321.13", qty: "0.123456789", roundQty: "0.12345678",
+		},
+		{
+			label:   "tick and lot size",
+			spec:    Spec{Symbol: "ES", SecurityType: Equity, TickSize: decimal.New(25, -2), LotSize: decimal.New(100, 0)},
+			priceDP: 2, qtyDP: 0,
+			price: "3001.13", rounded: "3001.25", qty: "250", roundQty: "200",
+		},
+	}
+
+	for _, tc := range tests {
+		if tc.spec.PricePrecision() != tc.priceDP {
+			t.Errorf("%s - PricePrecision should be %d, got %d", tc.label, tc.priceDP, tc.spec.PricePrecision())
+		}
+		if tc.spec.QtyPrecision() != tc.qtyDP {
+			t.Errorf("%s - QtyPrecision should be %d, got %d", tc.label, tc.qtyDP, tc.spec.QtyPrecision())
+		}
+
+		price := tc.spec.RoundPrice(decimal.RequireFromString(tc.price))
+		if price.String() != tc.rounded {
+			t.Errorf("%s - RoundPrice(%s) should be %s, got %s", tc.label, tc.price, tc.rounded, price)
+		}
+		if err := tc.spec.ValidatePrice(price); err != nil {
+			t.Errorf("%s - unexpected error: %v", tc.label, err)
+		}
+
+		qty := tc.spec.RoundQty(decimal.RequireFromString(tc.qty))
+		if qty.String() != tc.roundQty {
+			t.Errorf("%s - RoundQty(%s) should be %s, got %s", tc.label, tc.qty, tc.roundQty, qty)
+		}
+		if err := tc.spec.ValidateQty(qty); err != nil {
+			t.Errorf("%s - unexpected error: %v", tc.label, err)
+		}
+	}
+
+	es := Spec{Symbol: "ES", SecurityType: Equity, TickSize: decimal.New(25, -2)}
+	if es.ValidatePrice(decimal.RequireFromString("3001.10")) == nil {
+		t.Errorf("off tick price - should have an error")
+	}
+	if es.ValidateQty(decimal.RequireFromString("1.5")) == nil {
+		t.Errorf("fractional qty - should have an error")
+	}
+	if (Spec{Symbol: "X", SecurityType: Equity, TickSize: decimal.New(-1, 0)}).Validate() == nil {
+		t.Errorf("negative tick size - should have an error")
+	}
+}
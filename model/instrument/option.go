@@ -0,0 +1,149 @@
+package instrument
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+// OptionRight is right of option holder - put or call.
+type OptionRight byte
+
+// Option rights, values are used in OCC symbol.
+const (
+	Call OptionRight = 'C'
+	Put  OptionRight = 'P'
+)
+
+func (r OptionRight) String() string {
+	switch r {
+	case Call:
+		return "call"
+	case Put:
+		return "put"
+	}
+	return ""
+}
+
+// OptionStyle defines when option can be exercised.
+type OptionStyle int
+
+// Option styles.
+const (
+	American OptionStyle = iota // exercise any time before expiry
+	European                    // exercise at expiry only
+)
+
+var optStyleStrMap = map[OptionStyle]string{
+	American: "american",
+	European: "european",
+}
+
+func (s OptionStyle) String() string {
+	return optStyleStrMap[s]
+}
+
+// OptionStyleFromString parses input string and returns OptionStyle.
+func OptionStyleFromString(s string) (OptionStyle, error) {
+	sx := strings.ToLower(strings.TrimSpace(s))
+	for style, str := range optStyleStrMap {
+		if str == sx {
+			return style, nil
+		}
+	}
+	return American, fmt.Errorf("invalid option style: %q", s)
+}
+
+// OptionSpec is options contract specification.
+type OptionSpec struct {
+	Underlying string
+	Strike     decimal.Decimal
+	Expiry     typedef.Date
+	Right      OptionRight
+	Style      OptionStyle
+	Multiplier decimal.Decimal // e.g. 100 for US equity options
+}
+
+// Validate checks if OptionSpec has valid content.
+func (o OptionSpec) Validate() error {
+	switch {
+	case o.Underlying == "":
+		return fmt.Errorf("Underlying not defined")
+
+	case !o.Strike.IsPositive():
+		return fmt.Errorf("Strike: %s is not positive", o.Strike)
+
+	case o.Expiry.Time().IsZero():
+		return fmt.Errorf("Expiry not defined")
+
+	case o.Right != Call && o.Right != Put:
+		return fmt.Errorf("invalid Right: %q", o.Right)
+
+	case optStyleStrMap[o.Style] == "":
+		return fmt.Errorf("invalid Style: %d", o.Style)
+
+	case o.Multiplier.IsNegative():
+		return fmt.Errorf("Multiplier: %s is less than zero", o.Multiplier)
+	}
+
+	return nil
+}
+
+// occ symbol layout
+const (
+	occRootLen   = 6
+	occDateFmt   = "060102"
+	occStrikeLen = 8
+	occSymbolLen = occRootLen + len(occDateFmt) + 1 + occStrikeLen
+)
+
+var occStrikeScale = decimal.New(1000, 0)
+
+// Symbol formats OCC option symbol: root padded to 6 characters,
+// expiry YYMMDD, C or P and strike price * 1000 padded to 8 digits
+// e.g. "AAPL  191220C00150000" is AAPL Dec 20 2019 150 call.
+func (o OptionSpec) Symbol() string {
+	strike := o.Strike.Mul(occStrikeScale).Round(0).IntPart()
+	return fmt.Sprintf("%-*s%s%c%0*d",
+		occRootLen, o.Underlying, o.Expiry.Time().Format(occDateFmt),
+		o.Right, occStrikeLen, strike)
+}
+
+// ParseOptionSymbol parses OCC option symbol.
+// Both padded ("AAPL  191220C00150000") and compact ("AAPL191220C00150000")
+// forms are accepted. Style is not part of the symbol, American is used.
+func ParseOptionSymbol(sym string) (OptionSpec, error) {
+	output := OptionSpec{}
+	s := strings.ToUpper(strings.TrimSpace(sym))
+
+	tail := len(occDateFmt) + 1 + occStrikeLen
+	if len(s) <= tail || len(s) > occSymbolLen {
+		return output, fmt.Errorf("invalid option symbol %q: unexpected length", sym)
+	}
+	root := strings.TrimSpace(s[:len(s)-tail])
+	s = s[len(s)-tail:]
+
+	expiry, err := time.Parse(occDateFmt, s[:len(occDateFmt)])
+	if err != nil {
+		return output, fmt.Errorf("invalid option symbol %q: expiry: %v", sym, err)
+	}
+	s = s[len(occDateFmt):]
+
+	right := OptionRight(s[0])
+	strike, err := decimal.NewFromString(s[1:])
+	if err != nil {
+		return output, fmt.Errorf("invalid option symbol %q: strike: %v", sym, err)
+	}
+
+	output.Underlying = root
+	output.Expiry = typedef.Date(expiry)
+	output.Right = right
+	output.Strike = strike.Div(occStrikeScale)
+	output.Style = American
+
+	return output, output.Validate()
+}
@@ -0,0 +1,124 @@
+package instrument
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// maxDecimals is the maximal supported number of decimal places.
+const maxDecimals = 18
+
+// PricePrecision returns decimal places of price.
+// Precedence: PriceDecimals, decimal places of TickSize,
+// SecurityDecimalPlaces of SecurityType.
+// Zero PriceDecimals is not set, whole prices require TickSize 1.
+func (s Spec) PricePrecision() int {
+	switch {
+	case s.PriceDecimals > 0:
+		return s.PriceDecimals
+	case s.TickSize.IsPositive():
+		return decimalPlaces(s.TickSize)
+	}
+	return SecurityDecimalPlaces(s.SecurityType)
+}
+
+// QtyPrecision returns decimal places of quantity (volume).
+// Precedence: QtyDecimals, decimal places of LotSize, security type default:
+// cryptocurrencies are fractional (8 decimal places), other securities
+// are traded in whole units.
+func (s Spec) QtyPrecision() int {
+	switch {
+	case s.QtyDecimals > 0:
+		return s.QtyDecimals
+	case s.LotSize.IsPositive():
+		return decimalPlaces(s.LotSize)
+	case s.SecurityType == Crypto:
+		return SecurityDecimalPlaces(Crypto)
+	}
+	return 0
+}
+
+// Tick returns minimal price increment: TickSize if set,
+// otherwise one unit of the last decimal place of PricePrecision.
+func (s Spec) Tick() decimal.Decimal {
+	if s.TickSize.IsPositive() {
+		return s.TickSize
+	}
+	return decimal.New(1, -int32(s.PricePrecision()))
+}
+
+// Lot returns minimal quantity increment: LotSize if set,
+// otherwise one unit of the last decimal place of QtyPrecision.
+func (s Spec) Lot() decimal.Decimal {
+	if s.LotSize.IsPositive() {
+		return s.LotSize
+	}
+	return decimal.New(1, -int32(s.QtyPrecision()))
+}
+
+// RoundPrice rounds price to the nearest tick.
+func (s Spec) RoundPrice(price decimal.Decimal) decimal.Decimal {
+	return roundTo(price, s.Tick()).Round(int32(s.PricePrecision()))
+}
+
+// RoundQty rounds quantity down to whole lots, so that rounded
+// quantity never exceeds requested one (e.g. available cash).
+func (s Spec) RoundQty(qty decimal.Decimal) decimal.Decimal {
+	lot := s.Lot()
+	lots := qty.Div(lot).Truncate(0)
+	return lots.Mul(lot).Round(int32(s.QtyPrecision()))
+}
+
+// ValidatePrice checks if price is positive multiple of tick.
+func (s Spec) ValidatePrice(price decimal.Decimal) error {
+	switch {
+	case !price.IsPositive():
+		return fmt.Errorf("%s: price %s is not positive", s.Symbol, price)
+
+	case !isMultiple(price, s.Tick()):
+		return fmt.Errorf("%s: price %s is not multiple of tick %s", s.Symbol, price, s.Tick())
+	}
+
+	return nil
+}
+
+// ValidateQty checks if quantity is positive multiple of lot.
+func (s Spec) ValidateQty(qty decimal.Decimal) error {
+	switch {
+	case !qty.IsPositive():
+		return fmt.Errorf("%s: quantity %s is not positive", s.Symbol, qty)
+
+	case !isMultiple(qty, s.Lot()):
+		return fmt.Errorf("%s: quantity %s is not multiple of lot %s", s.Symbol, qty, s.Lot())
+	}
+
+	return nil
+}
+
+// decimalPlaces returns number of significant decimal places of d
+// e.g. 2 for 0.25, 0 for 100.
+func decimalPlaces(d decimal.Decimal) int {
+	for n := 0; n < maxDecimals; n++ {
+		if d.Equal(d.Truncate(int32(n))) {
+			return n
+		}
+	}
+	return maxDecimals
+}
+
+// roundTo rounds d to the nearest multiple of step.
+func roundTo(d, step decimal.Decimal) decimal.Decimal {
+	if !step.IsPositive() {
+		return d
+	}
+	return d.Div(step).Round(0).Mul(step)
+}
+
+// isMultiple reports whether d is whole multiple of step.
+func isMultiple(d, step decimal.Decimal) bool {
+	if !step.IsPositive() {
+		return true
+	}
+	return d.Mod(step).IsZero()
+}
@@ -23,13 +23,13 @@ const (
 	Crypto
 
 	// Bond - debt securities.
-	// Bond
+	Bond
 
 	// Future - futures contracts.
-	// Future
+	Future
 
 	// Option - options contracts.
-	// Option
+	Option
 )
 
 // see SecurityDecimalPlaces function.
@@ -37,6 +37,9 @@ var secDecimalPlacesMap = map[Security]int{
 	Equity: 2,
 	Forex:  4,
 	Crypto: 8,
+	Bond:   3,
+	Future: 2,
+	Option: 2,
 }
 
 var secStrMap = map[Security]string{
@@ -44,6 +47,9 @@ var secStrMap = map[Security]string{
 	Equity:  "equity",
 	Forex:   "forex",
 	Crypto:  "crypto",
+	Bond:    "bond",
+	Future:  "future",
+	Option:  "option",
 }
 
 // Validate checks if Security is valid.
@@ -70,7 +76,8 @@ func (s Security) String() string {
 
 // SecurityDecimalPlaces returns decimal places of given security.
 // For example equities are quoted in cents (2 decimal places),
-// cryptocurrencies are quoted in satoshis (8 decimal places).
+// cryptocurrencies are quoted in satoshis (8 decimal places),
+// bonds are quoted in percent of face value with fractions (3 decimal places).
 func SecurityDecimalPlaces(s Security) int {
 	d, ok := secDecimalPlacesMap[s]
 	if !ok {
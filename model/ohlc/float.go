@@ -0,0 +1,129 @@
+package ohlc
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+// Floats is float64 columnar view of Vec for fast computation
+// (indicators, statistics). Use Vec and decimal.Decimal for storage.
+//
+// Conversion from decimal.Decimal is the nearest float64 value,
+// so it is exact up to 15 significant digits. Arithmetic on float64
+// introduces binary rounding errors e.g. 0.1 + 0.2 = 0.30000000000000004;
+// such values are rounded to Precision by conversion back to Vec.
+type Floats struct {
+	Dates     []typedef.Date // shared with Vec, must not be modified
+	Open      []float64
+	High      []float64
+	Low       []float64
+	Close     []float64
+	Volume    []float64
+	Timeframe time.Duration
+}
+
+// Len provides number of elements.
+func (f Floats) Len() int {
+	return len(f.Dates)
+}
+
+// Precision is number of decimal places of values converted
+// from float64 to decimal.Decimal.
+type Precision struct {
+	Price  int32 // Open, High, Low, Close
+	Volume int32
+}
+
+// PrecisionOf returns Precision of instrument s - PricePrecision
+// and QtyPrecision, the same as used by CSV output.
+func PrecisionOf(s instrument.Spec) Precision {
+	return Precision{Price: int32(s.PricePrecision()), Volume: int32(s.QtyPrecision())}
+}
+
+// Floats converts Vec to float64 columns.
+func (v *Vec) Floats() Floats {
+	return Floats{
+		Dates:     v.Dates(),
+		Open:      toFloats(v.open),
+		High:      toFloats(v.high),
+		Low:       toFloats(v.low),
+		Close:     toFloats(v.close),
+		Volume:    toFloats(v.volume),
+		Timeframe: v.timeframe,
+	}
+}
+
+func toFloats(lst []decimal.Decimal) []float64 {
+	output := make([]float64, len(lst))
+	for i, d := range lst {
+		output[i] = toFloat(d)
+	}
+	return output
+}
+
+// pow10 are powers of 10 exactly representable by float64.
+var pow10 = [...]float64{1e0, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10,
+	1e11, 1e12, 1e13, 1e14, 1e15, 1e16, 1e17, 1e18, 1e19, 1e20, 1e21, 1e22}
+
+// toFloat returns the nearest float64 value of d.
+// Coefficient < 2^53 and power of 10 <= 1e22 are exact float64 values
+// so single multiplication or division is correctly rounded;
+// slow path of decimal.Float64 is used otherwise.
+func toFloat(d decimal.Decimal) float64 {
+	exp := int(d.Exponent())
+	if exp >= -22 && exp <= 22 {
+		if c := d.Coefficient(); c.IsInt64() {
+			if ci := c.Int64(); ci > -1<<53 && ci < 1<<53 {
+				if exp < 0 {
+					return float64(ci) / pow10[-exp]
+				}
+				return float64(ci) * pow10[exp]
+			}
+		}
+	}
+	f, _ := d.Float64()
+	return f
+}
+
+// Vec converts Floats to Vec; prices and volume are rounded
+// to decimal places of p (half away from zero).
+// NaN and infinite values are errors.
+func (f Floats) Vec(p Precision) (Vec, error) {
+	cols := []struct {
+		name   string
+		values []float64
+		places int32
+	}{
+		{"Open", f.Open, p.Price},
+		{"High", f.High, p.Price},
+		{"Low", f.Low, p.Price},
+		{"Close", f.Close, p.Price},
+		{"Volume", f.Volume, p.Volume},
+	}
+	decs := make([][]decimal.Decimal, len(cols))
+	for i, col := range cols {
+		if len(col.values) != len(f.Dates) {
+			return Vec{}, fmt.Errorf("%s: length %d differs from Dates: %d", col.name, len(col.values), len(f.Dates))
+		}
+		decs[i] = make([]decimal.Decimal, len(col.values))
+		for j, x := range col.values {
+			if math.IsNaN(x) || math.IsInf(x, 0) {
+				return Vec{}, fmt.Errorf("%s - %s: invalid value %v", f.Dates[j], col.name, x)
+			}
+			decs[i][j] = decimal.NewFromFloat(x).Round(col.places)
+		}
+	}
+
+	bars := make([]OHLC, len(f.Dates))
+	for i, d := range f.Dates {
+		bars[i] = OHLC{Date: d, Open: decs[0][i], High: decs[1][i], Low: decs[2][i], Close: decs[3][i], Volume: decs[4][i]}
+	}
+
+	return NewVec(bars, f.Timeframe)
+}
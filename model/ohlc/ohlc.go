@@ -86,29 +86,36 @@ func (oid *OHLCintrad) Validate() error {
 	return nil
 }
 
-// Vec represents vector of OHLC data with some handy getter functions.
-// NOTE: Vec is designed to be immutable. It is not designed to be fast.
-// Each getter method returns new copy of requested data
-// i.e. NEW DATA ALLOCATION FOR EACH METHOD CALL!
-// If you use data multiple times, store it to variable and reuse
-// e.g. dates := v.Dates() // reuse dates
+// Vec represents vector of OHLC data sorted by Date.
+// Data are stored in columns (Dates, Open, High, Low, Close, Volume)
+// so single column can be used without copying.
+//
+// Column getters, Range and Slice return views sharing memory with Vec.
+// Views must not be modified. Vec can grow by Append; views created
+// before Append are not affected. Vec values copied by assignment
+// share memory too - append to one of them only.
 type Vec struct {
 	dates     []typedef.Date
-	data      map[typedef.Date]OHLC
+	open      []decimal.Decimal
+	high      []decimal.Decimal
+	low       []decimal.Decimal
+	close     []decimal.Decimal
+	volume    []decimal.Decimal
 	timeframe time.Duration
 	maxGap    time.Duration
 }
 
+// Len provides number of OHLC elements.
+func (v *Vec) Len() int {
+	return len(v.dates)
+}
+
 // Data provides sorted (by Date) list of OHLC elements.
+// NOTE: new list is allocated for each call.
 func (v *Vec) Data() []OHLC {
-	output := make([]OHLC, 0, len(v.data))
-
-	for _, d := range v.dates {
-		bar, ok := v.data[d]
-		if !ok {
-			panic(fmt.Sprintf("internal inconsistency: missing data for %s", d))
-		}
-		output = append(output, bar)
+	output := make([]OHLC, len(v.dates))
+	for i := range v.dates {
+		output[i] = v.at(i)
 	}
 
 	return output
@@ -116,92 +123,232 @@ func (v *Vec) Data() []OHLC {
 
 // Dates provides sorted list of Dates in OHLC vector.
 func (v *Vec) Dates() []typedef.Date {
-	output := make([]typedef.Date, 0, len(v.dates))
+	return v.dates[:len(v.dates):len(v.dates)]
+}
 
-	for _, d := range v.dates {
-		output = append(output, d)
-	}
+// Open provides column of Open prices.
+func (v *Vec) Open() []decimal.Decimal {
+	return v.open[:len(v.open):len(v.open)]
+}
 
-	return output
+// High provides column of High prices.
+func (v *Vec) High() []decimal.Decimal {
+	return v.high[:len(v.high):len(v.high)]
+}
+
+// Low provides column of Low prices.
+func (v *Vec) Low() []decimal.Decimal {
+	return v.low[:len(v.low):len(v.low)]
+}
+
+// Close provides column of Close prices.
+func (v *Vec) Close() []decimal.Decimal {
+	return v.close[:len(v.close):len(v.close)]
+}
+
+// Volume provides column of Volume.
+func (v *Vec) Volume() []decimal.Decimal {
+	return v.volume[:len(v.volume):len(v.volume)]
+}
+
+// Timeframe provides timeframe of OHLC bars.
+func (v *Vec) Timeframe() time.Duration {
+	return v.timeframe
+}
+
+// Search returns index of the first element with Date >= d
+// or Len if there is no such element.
+func (v *Vec) Search(d typedef.Date) int {
+	t := d.Time()
+	return sort.Search(len(v.dates), func(i int) bool { return !v.dates[i].Time().Before(t) })
+}
+
+// Index returns index of element with Date d.
+func (v *Vec) Index(d typedef.Date) (int, bool) {
+	i := v.Search(d)
+	if i < len(v.dates) && v.dates[i].Time().Equal(d.Time()) {
+		return i, true
+	}
+	return i, false
 }
 
 // At returns OHLC by date or error if not found.
 func (v *Vec) At(d typedef.Date) (OHLC, error) {
-	output := OHLC{}
-
-	o, ok := v.data[d]
+	i, ok := v.Index(d)
 	if !ok {
-		return output, fmt.Errorf("data for date %s not found", d)
+		return OHLC{}, fmt.Errorf("data for date %s not found", d)
 	}
-	output = o
 
-	return output, nil
+	return v.at(i), nil
 }
 
 // AtIdx returns OHLC by index or error if not found.
 func (v *Vec) AtIdx(i int) (OHLC, error) {
-	output := OHLC{}
-
 	if i < 0 || i >= len(v.dates) {
-		return output, fmt.Errorf("invalid index: %d, no data", i)
+		return OHLC{}, fmt.Errorf("invalid index: %d, no data", i)
 	}
 
-	d := v.dates[i]
-	output, ok := v.data[d]
-	if !ok {
-		return output, fmt.Errorf("internal inconsistency: missing data for %s at index %d", d, i)
+	return v.at(i), nil
+}
+
+func (v *Vec) at(i int) OHLC {
+	return OHLC{
+		Date:   v.dates[i],
+		Open:   v.open[i],
+		High:   v.high[i],
+		Low:    v.low[i],
+		Close:  v.close[i],
+		Volume: v.volume[i],
+	}
+}
+
+// Slice returns view of elements with index in range [i, j).
+func (v *Vec) Slice(i, j int) Vec {
+	switch {
+	case i < 0:
+		i = 0
+	case i > len(v.dates):
+		i = len(v.dates)
+	}
+	switch {
+	case j > len(v.dates):
+		j = len(v.dates)
+	case j < i:
+		j = i
 	}
 
-	return output, nil
+	return Vec{
+		dates:     v.dates[i:j:j],
+		open:      v.open[i:j:j],
+		high:      v.high[i:j:j],
+		low:       v.low[i:j:j],
+		close:     v.close[i:j:j],
+		volume:    v.volume[i:j:j],
+		timeframe: v.timeframe,
+		maxGap:    v.maxGap,
+	}
 }
 
-// Validate checks correctness of Vec data.
-func (v *Vec) Validate() error {
-	for _, bar := range v.Data() {
-		if bar.Validate() != nil {
-			return fmt.Errorf("%s - %s", bar.Date, bar.Validate())
+// Range returns view of elements with Date in range [from, to].
+// Zero from or to means unbounded.
+func (v *Vec) Range(from, to typedef.Date) Vec {
+	i, j := 0, len(v.dates)
+	if !from.Time().IsZero() {
+		i = v.Search(from)
+	}
+	if !to.Time().IsZero() {
+		t := to.Time()
+		j = sort.Search(len(v.dates), func(i int) bool { return v.dates[i].Time().After(t) })
+	}
+
+	return v.Slice(i, j)
+}
+
+// Append adds bars to the end of Vec. Bars must be sorted by Date
+// and newer than the last element; bar with Date of the last element
+// replaces it (e.g. update of incomplete bar) - Vec data are copied
+// in this case so views are not affected.
+// Vec is not changed on error.
+func (v *Vec) Append(bars ...OHLC) error {
+	last := time.Time{}
+	if len(v.dates) > 0 {
+		last = v.dates[len(v.dates)-1].Time()
+	}
+	for i := range bars {
+		if err := bars[i].Validate(); err != nil {
+			return fmt.Errorf("%s - %s", bars[i].Date, err)
+		}
+		t := bars[i].Date.Time()
+		replace := i == 0 && len(v.dates) > 0 && t.Equal(last)
+		if !replace && !t.After(last) {
+			return fmt.Errorf("%s - not after %s", bars[i].Date, typedef.Date(last))
+		}
+		last = t
+	}
+
+	for _, bar := range bars {
+		n := len(v.dates)
+		if n > 0 && v.dates[n-1].Time().Equal(bar.Date.Time()) {
+			// replace in copy - the last element can be shared by views
+			v.truncate(n - 1)
 		}
+		v.push(bar)
 	}
 
 	return nil
 }
 
-// NewVec creates Vec.
-func NewVec(lst []OHLC, timeframe time.Duration) (Vec, error) {
-	v := Vec{timeframe: timeframe}
+// truncate drops elements from index n; kept elements are copied
+// so that views are not affected by following push.
+func (v *Vec) truncate(n int) {
+	v.dates = append([]typedef.Date{}, v.dates[:n]...)
+	v.open = append([]decimal.Decimal{}, v.open[:n]...)
+	v.high = append([]decimal.Decimal{}, v.high[:n]...)
+	v.low = append([]decimal.Decimal{}, v.low[:n]...)
+	v.close = append([]decimal.Decimal{}, v.close[:n]...)
+	v.volume = append([]decimal.Decimal{}, v.volume[:n]...)
+}
 
-	// unique data
-	data := make(map[typedef.Date]OHLC, len(lst))
-	for _, ohlc := range lst {
-		data[ohlc.Date] = ohlc
+func (v *Vec) push(bar OHLC) {
+	if n := len(v.dates); n > 0 {
+		if gap := bar.Date.Time().Sub(v.dates[n-1].Time()); gap > v.maxGap {
+			v.maxGap = gap
+		}
 	}
-	v.data = data
+	v.dates = append(v.dates, bar.Date)
+	v.open = append(v.open, bar.Open)
+	v.high = append(v.high, bar.High)
+	v.low = append(v.low, bar.Low)
+	v.close = append(v.close, bar.Close)
+	v.volume = append(v.volume, bar.Volume)
+}
 
-	// sorted unique date list
-	dates := make([]typedef.Date, 0, len(data))
-	for d := range data {
-		dates = append(dates, d)
+// Validate checks correctness of Vec data.
+func (v *Vec) Validate() error {
+	for i := range v.dates {
+		bar := v.at(i)
+		if err := bar.Validate(); err != nil {
+			return fmt.Errorf("%s - %s", bar.Date, err)
+		}
 	}
-	sort.Slice(dates,
+
+	return nil
+}
+
+// NewVec creates Vec. Elements with duplicate Date are dropped
+// except the last one.
+func NewVec(lst []OHLC, timeframe time.Duration) (Vec, error) {
+	sorted := make([]OHLC, len(lst))
+	copy(sorted, lst)
+	sort.SliceStable(sorted,
 		func(i, j int) bool {
-			return dates[i].Time().Unix() < dates[j].Time().Unix()
+			return sorted[i].Date.Time().Before(sorted[j].Date.Time())
 		})
-	v.dates = dates
 
-	var maxGap time.Duration
-	for i := range dates { // ensure sorted dates
-		if i == 0 {
+	// unique data
+	unique := sorted[:0]
+	for _, bar := range sorted {
+		n := len(unique)
+		if n > 0 && unique[n-1].Date.Time().Equal(bar.Date.Time()) {
+			unique[n-1] = bar
 			continue
 		}
+		unique = append(unique, bar)
+	}
 
-		d := dates[i].Time()
-		dPrev := dates[i-1].Time()
-		gap := d.Sub(dPrev)
-		if gap > maxGap {
-			maxGap = gap
-		}
+	n := len(unique)
+	v := Vec{
+		dates:     make([]typedef.Date, 0, n),
+		open:      make([]decimal.Decimal, 0, n),
+		high:      make([]decimal.Decimal, 0, n),
+		low:       make([]decimal.Decimal, 0, n),
+		close:     make([]decimal.Decimal, 0, n),
+		volume:    make([]decimal.Decimal, 0, n),
+		timeframe: timeframe,
+	}
+	for _, bar := range unique {
+		v.push(bar)
 	}
-	v.maxGap = maxGap
 
 	return v, v.Validate()
 }
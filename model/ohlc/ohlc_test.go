@@ -0,0 +1,435 @@
+package ohlc
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/pkg/typedef"
+	"github.com/shopspring/decimal"
+)
+
+var day0 = time.Date(2005, 1, 3, 0, 0, 0, 0, time.UTC)
+
+func date(i int) typedef.Date {
+	return typedef.Date(day0.AddDate(0, 0, i))
+}
+
+// mkBars creates n daily bars starting at day0; Close is day index + 1.
+func mkBars(n int) []OHLC {
+	output := make([]OHLC, n)
+	for i := range output {
+		c := decimal.New(int64(i+1), 0)
+		output[i] = OHLC{Date: date(i), Open: c, High: c, Low: c, Close: c, Volume: c}
+	}
+	return output
+}
+
+func TestNewVec(t *testing.T) {
+	bars := mkBars(5)
+	dup := bars[2]
+	dup.Close = decimal.New(100, 0)
+	dup.High = dup.Close
+	input := []OHLC{bars[4], bars[2], bars[0], bars[3], bars[1], dup}
+
+	v, err := NewVec(input, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Len() != 5 {
+		t.Fatalf("expected 5 bars, got %d", v.Len())
+	}
+	for i, d := range v.Dates() {
+		if d != date(i) {
+			t.Errorf("dates should be sorted: %v", v.Dates())
+		}
+	}
+	if !v.Close()[2].Equal(dup.Close) {
+		t.Errorf("the last duplicate should be kept, got %s", v.Close()[2])
+	}
+	if v.maxGap != 24*time.Hour {
+		t.Errorf("unexpected maxGap: %s", v.maxGap)
+	}
+
+	invalid := mkBars(2)
+	invalid[1].Low = decimal.New(10, 0)
+	if _, err := NewVec(invalid, 24*time.Hour); err == nil {
+		t.Errorf("invalid bar - should have an error")
+	}
+}
+
+func TestVecLookup(t *testing.T) {
+	v, err := NewVec(mkBars(10), 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bar, err := v.At(date(3))
+	if err != nil || !bar.Close.Equal(decimal.New(4, 0)) {
+		t.Errorf("unexpected At: %v (%v)", bar, err)
+	}
+	if _, err := v.At(date(20)); err == nil {
+		t.Errorf("At of missing date - should have an error")
+	}
+	if _, err := v.AtIdx(10); err == nil {
+		t.Errorf("AtIdx out of range - should have an error")
+	}
+	if i, ok := v.Index(date(-1)); ok || i != 0 {
+		t.Errorf("unexpected Index: %d %v", i, ok)
+	}
+
+	r := v.Range(date(2), date(4))
+	if r.Len() != 3 || r.Dates()[0] != date(2) || r.Dates()[2] != date(4) {
+		t.Errorf("unexpected Range: %v", r.Dates())
+	}
+	if r := v.Range(typedef.Date{}, date(1)); r.Len() != 2 {
+		t.Errorf("unexpected open Range: %v", r.Dates())
+	}
+	if r := v.Range(date(20), date(30)); r.Len() != 0 {
+		t.Errorf("unexpected empty Range: %v", r.Dates())
+	}
+	if s := v.Slice(8, 20); s.Len() != 2 || s.Timeframe() != v.Timeframe() {
+		t.Errorf("unexpected Slice: %v", s.Dates())
+	}
+}
+
+func TestVecAppend(t *testing.T) {
+	bars := mkBars(6)
+	v, err := NewVec(bars[:3], 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	view := v.Range(date(0), date(2))
+	data := view.Data()
+
+	tests := []struct {
+		label  string
+		input  []OHLC
+		cnt    int
+		hasErr bool
+	}{
+		{"append", bars[3:5], 5, false},
+		{"replace last", []OHLC{{Date: date(4), Close: decimal.New(9, 0), High: decimal.New(9, 0)}}, 5, false},
+		{"replace and append", []OHLC{bars[4], bars[5]}, 6, false},
+		{"older", bars[1:2], 6, true},
+		{"unsorted", []OHLC{bars[5], bars[5]}, 6, true},
+		{"invalid", []OHLC{{Date: date(7), Close: decimal.New(-1, 0)}}, 6, true},
+	}
+
+	for _, tt := range tests {
+		err := v.Append(tt.input...)
+		switch {
+		case tt.hasErr && err == nil:
+			t.Errorf("%s - should have an error", tt.label)
+		case !tt.hasErr && err != nil:
+			t.Errorf("%s - unexpected error: %v", tt.label, err)
+		case v.Len() != tt.cnt:
+			t.Errorf("%s - expected %d bars, got %d", tt.label, tt.cnt, v.Len())
+		}
+	}
+
+	last, _ := v.AtIdx(v.Len() - 1)
+	if last != bars[5] {
+		t.Errorf("unexpected last bar: %v", last)
+	}
+	if view.Len() != len(data) {
+		t.Fatalf("view should not be changed by Append")
+	}
+	for i, bar := range view.Data() {
+		if bar != data[i] {
+			t.Errorf("view should not be changed by Append: %v", bar)
+		}
+	}
+	if err := v.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// mapVec is the former map based Vec design used as benchmark baseline.
+type mapVec struct {
+	dates []typedef.Date
+	data  map[typedef.Date]OHLC
+}
+
+func newMapVec(lst []OHLC) mapVec {
+	v := mapVec{data: make(map[typedef.Date]OHLC, len(lst))}
+	for _, bar := range lst {
+		v.data[bar.Date] = bar
+	}
+	for d := range v.data {
+		v.dates = append(v.dates, d)
+	}
+	sort.Slice(v.dates, func(i, j int) bool { return v.dates[i].Time().Unix() < v.dates[j].Time().Unix() })
+	for _, bar := range v.Data() {
+		if bar.Validate() != nil {
+			panic(bar.Validate())
+		}
+	}
+	return v
+}
+
+func (v mapVec) Data() []OHLC {
+	output := make([]OHLC, 0, len(v.data))
+	for _, d := range v.dates {
+		output = append(output, v.data[d])
+	}
+	return output
+}
+
+func (v mapVec) Dates() []typedef.Date {
+	return append([]typedef.Date{}, v.dates...)
+}
+
+// benchBars - 15 years of daily bars.
+var benchBars = mkBars(15 * 252)
+
+func BenchmarkNewVec(b *testing.B) {
+	b.Run("map", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			newMapVec(benchBars)
+		}
+	})
+	b.Run("columnar", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := NewVec(benchBars, 24*time.Hour); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkClose sums Close prices - typical indicator access pattern.
+func BenchmarkClose(b *testing.B) {
+	mv := newMapVec(benchBars)
+	v, _ := NewVec(benchBars, 24*time.Hour)
+
+	b.Run("map", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sum := decimal.Zero
+			for _, bar := range mv.Data() {
+				sum = sum.Add(bar.Close)
+			}
+		}
+	})
+	b.Run("columnar", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sum := decimal.Zero
+			for _, c := range v.Close() {
+				sum = sum.Add(c)
+			}
+		}
+	})
+}
+
+// BenchmarkRange takes the last year of data.
+func BenchmarkRange(b *testing.B) {
+	mv := newMapVec(benchBars)
+	v, _ := NewVec(benchBars, 24*time.Hour)
+	from, to := date(len(benchBars)-365), date(len(benchBars))
+
+	b.Run("map", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			dates := mv.Dates()
+			lo := sort.Search(len(dates), func(i int) bool { return !dates[i].Time().Before(from.Time()) })
+			hi := sort.Search(len(dates), func(i int) bool { return dates[i].Time().After(to.Time()) })
+			bars := make([]OHLC, 0, hi-lo)
+			for _, d := range dates[lo:hi] {
+				bars = append(bars, mv.data[d])
+			}
+		}
+	})
+	b.Run("columnar", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			v.Range(from, to)
+		}
+	})
+}
+
+func BenchmarkAt(b *testing.B) {
+	mv := newMapVec(benchBars)
+	v, _ := NewVec(benchBars, 24*time.Hour)
+
+	b.Run("map", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = mv.data[date(i%len(benchBars))]
+		}
+	})
+	b.Run("columnar", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := v.At(date(i % len(benchBars))); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkAppend adds one bar to Vec - incremental update.
+func BenchmarkAppend(b *testing.B) {
+	b.Run("map", func(b *testing.B) {
+		b.StopTimer()
+		for i := 0; i < b.N; i++ {
+			mv := newMapVec(benchBars[:len(benchBars)-1])
+			b.StartTimer()
+			newMapVec(append(mv.Data(), benchBars[len(benchBars)-1]))
+			b.StopTimer()
+		}
+	})
+	b.Run("columnar", func(b *testing.B) {
+		b.StopTimer()
+		for i := 0; i < b.N; i++ {
+			v, _ := NewVec(benchBars[:len(benchBars)-1], 24*time.Hour)
+			b.StartTimer()
+			if err := v.Append(benchBars[len(benchBars)-1]); err != nil {
+				b.Fatal(err)
+			}
+			b.StopTimer()
+		}
+	})
+}
+
+func TestFloats(t *testing.T) {
+	bars := mkBars(3)
+	bars[1].Close = decimal.RequireFromString("1.15")
+	bars[1].High = decimal.RequireFromString("2.5")
+	bars[2].Volume = decimal.RequireFromString("0.12345678")
+	v, err := NewVec(bars, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := v.Floats()
+	if f.Len() != 3 || f.Close[1] != 1.15 || f.Volume[2] != 0.12345678 || f.Timeframe != v.Timeframe() {
+		t.Errorf("unexpected Floats: %+v", f)
+	}
+
+	// round trip is exact for precision of source data
+	back, err := f.Vec(Precision{Price: 2, Volume: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, bar := range back.Data() {
+		if want, _ := v.AtIdx(i); !bar.Close.Equal(want.Close) || !bar.Volume.Equal(want.Volume) {
+			t.Errorf("round trip: expected %v, got %v", want, bar)
+		}
+	}
+
+	tests := []struct {
+		label     string
+		close     float64
+		precision Precision
+		output    string
+		hasErr    bool
+	}{
+		{"binary error", 0.1 + 0.2, Precision{Price: 2}, "0.3", false},
+		{"rounding", 2.345, Precision{Price: 2}, "2.35", false},
+		{"rounding half", 2.5, Precision{Price: 0}, "3", false},
+		{"NaN", math.NaN(), Precision{Price: 2}, "", true},
+		{"Inf", math.Inf(1), Precision{Price: 2}, "", true},
+	}
+	for _, tt := range tests {
+		g := Floats{
+			Dates:  []typedef.Date{date(0)},
+			Open:   []float64{0},
+			High:   []float64{10},
+			Low:    []float64{0},
+			Close:  []float64{tt.close},
+			Volume: []float64{0},
+		}
+		v, err := g.Vec(tt.precision)
+		switch {
+		case tt.hasErr && err == nil:
+			t.Errorf("%s - should have an error", tt.label)
+		case !tt.hasErr && err != nil:
+			t.Errorf("%s - unexpected error: %v", tt.label, err)
+		case tt.hasErr:
+		case v.Close()[0].String() != tt.output:
+			t.Errorf("%s - expected %s, got %s", tt.label, tt.output, v.Close()[0])
+		}
+	}
+
+	f.Volume = f.Volume[:2]
+	if _, err := f.Vec(Precision{}); err == nil {
+		t.Errorf("column length mismatch - should have an error")
+	}
+}
+
+func TestToFloat(t *testing.T) {
+	for _, s := range []string{"0", "1.15", "-0.1", "123456.789", "0.00000001",
+		"1e22", "1e23", "9007199254740993", "12345678901234567890.123"} {
+		d := decimal.RequireFromString(s)
+		want, _ := d.Float64()
+		if got := toFloat(d); got != want {
+			t.Errorf("%s - expected %v, got %v", s, want, got)
+		}
+	}
+}
+
+func TestPrecisionOf(t *testing.T) {
+	p := PrecisionOf(instrument.Spec{SecurityType: instrument.Crypto})
+	if p.Price != int32(instrument.SecurityDecimalPlaces(instrument.Crypto)) || p.Volume != 8 {
+		t.Errorf("unexpected Precision: %+v", p)
+	}
+}
+
+// BenchmarkSMA computes 200 days moving average of Close.
+func BenchmarkSMA(b *testing.B) {
+	v, _ := NewVec(benchBars, 24*time.Hour)
+	const n = 200
+
+	b.Run("decimal", func(b *testing.B) {
+		nd := decimal.New(n, 0)
+		for i := 0; i < b.N; i++ {
+			c := v.Close()
+			sum := decimal.Zero
+			for j := range c {
+				sum = sum.Add(c[j])
+				if j >= n {
+					sum = sum.Sub(c[j-n])
+				}
+				if j >= n-1 {
+					_ = sum.Div(nd)
+				}
+			}
+		}
+	})
+	b.Run("float64", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			c := v.Floats().Close
+			sum := 0.0
+			for j := range c {
+				sum += c[j]
+				if j >= n {
+					sum -= c[j-n]
+				}
+				if j >= n-1 {
+					_ = sum / n
+				}
+			}
+		}
+	})
+	b.Run("float64 reused", func(b *testing.B) {
+		c := v.Floats().Close
+		for i := 0; i < b.N; i++ {
+			sum := 0.0
+			for j := range c {
+				sum += c[j]
+				if j >= n {
+					sum -= c[j-n]
+				}
+				if j >= n-1 {
+					_ = sum / n
+				}
+			}
+		}
+	})
+}
+
+func ExampleVec_Range() {
+	v, _ := NewVec(mkBars(10), 24*time.Hour)
+	r := v.Range(date(2), date(4))
+	fmt.Println(r.Len(), r.Close())
+	// Output: 3 [3 4 5]
+}
@@ -0,0 +1,309 @@
+package ohlcio
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+// Format is CSV format of historical daily data.
+type Format int
+
+const (
+	// FormatAuto - format is detected by CSV header.
+	FormatAuto Format = iota
+
+	// FormatTrada - CSVheader columns in any order, see ToCSV.
+	// Date and Close columns are required.
+	FormatTrada
+
+	// FormatStooq - Stooq download (English or Polish header)
+	// e.g. Date,Open,High,Low,Close,Volume or Data,Otwarcie,...
+	FormatStooq
+
+	// FormatStooqASCII - Stooq bulk ASCII data e.g.
+	// <TICKER>,<PER>,<DATE>,<TIME>,<OPEN>,<HIGH>,<LOW>,<CLOSE>,<VOL>,<OPENINT>
+	FormatStooqASCII
+
+	// FormatYahoo - Yahoo Finance download
+	// Date,Open,High,Low,Close,Adj Close,Volume
+	FormatYahoo
+)
+
+var formatStrMap = map[Format]string{
+	FormatAuto:       "auto",
+	FormatTrada:      "trada",
+	FormatStooq:      "stooq",
+	FormatStooqASCII: "stooq-ascii",
+	FormatYahoo:      "yahoo",
+}
+
+func (f Format) String() string {
+	return formatStrMap[f]
+}
+
+// FormatFromString parses input string and returns Format.
+func FormatFromString(s string) (Format, error) {
+	sx := strings.ToLower(strings.TrimSpace(s))
+	for f, str := range formatStrMap {
+		if str == sx {
+			return f, nil
+		}
+	}
+	return FormatAuto, fmt.Errorf("invalid format string: %q", s)
+}
+
+// column names of supported formats, lower case
+var formatColumns = map[Format]map[string]string{
+	FormatStooq: {
+		"data": "date", "otwarcie": "open", "najwyzszy": "high",
+		"najnizszy": "low", "zamkniecie": "close", "wolumen": "volume",
+	},
+	FormatStooqASCII: {
+		"<date>": "date", "<open>": "open", "<high>": "high",
+		"<low>": "low", "<close>": "close", "<vol>": "volume", "<per>": "per",
+	},
+	FormatYahoo: {
+		"date": "date", "open": "open", "high": "high", "low": "low",
+		"close": "close", "adj close": "adjclose", "volume": "volume",
+	},
+	FormatTrada: {
+		"date": "date", "open": "open", "high": "high", "low": "low",
+		"close": "close", "volume": "volume",
+	},
+}
+
+// DetectFormat detects CSV format by header.
+// Stooq download in English has the same header as FormatTrada
+// and it is reported as FormatTrada.
+func DetectFormat(header []string) Format {
+	cols := make([]string, len(header))
+	for i, h := range header {
+		cols[i] = strings.ToLower(strings.TrimSpace(h))
+	}
+	has := func(name string) bool {
+		for _, c := range cols {
+			if c == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case has("<date>") && has("<close>"):
+		return FormatStooqASCII
+	case has("data") && has("zamkniecie"):
+		return FormatStooq
+	case has("date") && has("adj close"):
+		return FormatYahoo
+	case has("date") && has("close"):
+		return FormatTrada
+	}
+
+	return FormatAuto
+}
+
+// ImportOpts defines import options.
+type ImportOpts struct {
+	Format Format
+
+	// Adjusted - adjust Open, High, Low and Close by ratio of adjusted
+	// close to close (splits and dividends); formats with adjusted close only.
+	Adjusted bool
+}
+
+// FromCSV imports []ohlc.OHLC from CSV in FormatTrada.
+// Missing Open, High and Low are set to Close (e.g. reference rates),
+// missing Volume is zero. Column separator (comma or semicolon)
+// is detected from the header.
+func FromCSV(r io.Reader) ([]ohlc.OHLC, error) {
+	output, _, err := Import(r, ImportOpts{Format: FormatTrada})
+	return output, err
+}
+
+// Import imports []ohlc.OHLC from CSV in one of supported formats
+// and returns the format used. Rows with missing values (e.g. "null"
+// in Yahoo data) are skipped, output is sorted by date.
+func Import(r io.Reader, opts ImportOpts) ([]ohlc.OHLC, Format, error) {
+	output := []ohlc.OHLC{}
+	format := opts.Format
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return output, format, fmt.Errorf("CSV read error: %v", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return output, format, nil
+	}
+
+	rcsv := csv.NewReader(bytes.NewReader(data))
+	rcsv.Comma = detectComma(data)
+	rcsv.FieldsPerRecord = -1
+	rcsv.TrimLeadingSpace = true
+	records, err := rcsv.ReadAll()
+	if err != nil {
+		return output, format, fmt.Errorf("CSV read error: %v", err)
+	}
+
+	if format == FormatAuto {
+		format = DetectFormat(records[0])
+		if format == FormatAuto {
+			return output, format, fmt.Errorf("unknown CSV format, header: %q", strings.Join(records[0], ","))
+		}
+	}
+	cols, err := mapColumns(records[0], format)
+	if err != nil {
+		return output, format, err
+	}
+	_, hasAdj := cols["adjclose"]
+	if opts.Adjusted && !hasAdj {
+		return output, format, fmt.Errorf("%s format has no adjusted close column", format)
+	}
+
+	output = make([]ohlc.OHLC, 0, len(records)-1)
+	for i, row := range records[1:] {
+		bar, ok, err := parseRow(row, cols, format, opts.Adjusted)
+		if err != nil {
+			return output, format, fmt.Errorf("row %d: %v", i+2, err)
+		}
+		if ok {
+			output = append(output, bar)
+		}
+	}
+
+	v, err := ohlc.NewVec(output, 24*time.Hour)
+	if err != nil {
+		return output, format, err
+	}
+
+	return v.Data(), format, nil
+}
+
+// detectComma returns column separator used in the first line of data.
+func detectComma(data []byte) rune {
+	line := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		line = data[:i]
+	}
+	if bytes.Count(line, []byte{';'}) > bytes.Count(line, []byte{','}) {
+		return ';'
+	}
+	return ','
+}
+
+// mapColumns maps normalized column names of format to column indexes.
+func mapColumns(header []string, format Format) (map[string]int, error) {
+	names, ok := formatColumns[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+
+	cols := map[string]int{}
+	for i, h := range header {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if name, ok := names[h]; ok {
+			cols[name] = i
+		}
+	}
+
+	for _, name := range []string{"date", "close"} {
+		if _, ok := cols[name]; !ok {
+			return cols, fmt.Errorf("CSV header %q has no %s column", strings.Join(header, ","), name)
+		}
+	}
+
+	return cols, nil
+}
+
+// parseRow parses CSV row into OHLC using column mapping.
+// Reports false for rows with missing values.
+func parseRow(row []string, cols map[string]int, format Format, adjusted bool) (ohlc.OHLC, bool, error) {
+	output := ohlc.OHLC{}
+
+	field := func(name string) (string, bool) {
+		i, ok := cols[name]
+		if !ok || i >= len(row) {
+			return "", false
+		}
+		return strings.TrimSpace(row[i]), true
+	}
+
+	if per, ok := field("per"); ok && per != "D" {
+		return output, false, fmt.Errorf("unsupported period %q, daily (D) data expected", per)
+	}
+
+	s, _ := field("date")
+	layout := typedef.DateFormat
+	if format == FormatStooqASCII {
+		layout = "20060102"
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return output, false, fmt.Errorf("invalid date %q: %v", s, err)
+	}
+	output.Date = typedef.Date(t)
+
+	s, _ = field("close")
+	if missingValue(s) {
+		return output, false, nil
+	}
+	output.Close, err = decimal.NewFromString(s)
+	if err != nil {
+		return output, false, fmt.Errorf("invalid close %q: %v", s, err)
+	}
+	output.Open, output.High, output.Low = output.Close, output.Close, output.Close
+
+	for name, v := range map[string]*decimal.Decimal{
+		"open": &output.Open, "high": &output.High, "low": &output.Low, "volume": &output.Volume,
+	} {
+		s, ok := field(name)
+		if !ok || s == "" {
+			continue
+		}
+		if missingValue(s) {
+			return output, false, nil
+		}
+		*v, err = decimal.NewFromString(s)
+		if err != nil {
+			return output, false, fmt.Errorf("invalid %s %q: %v", name, s, err)
+		}
+	}
+
+	if adjusted {
+		s, _ := field("adjclose")
+		if missingValue(s) {
+			return output, false, nil
+		}
+		adj, err := decimal.NewFromString(s)
+		if err != nil {
+			return output, false, fmt.Errorf("invalid adjusted close %q: %v", s, err)
+		}
+		if !output.Close.IsPositive() {
+			return output, false, fmt.Errorf("adjustment needs positive close, got %s", output.Close)
+		}
+		ratio := adj.Div(output.Close)
+		output.Open, output.High, output.Low, output.Close =
+			output.Open.Mul(ratio), output.High.Mul(ratio), output.Low.Mul(ratio), adj
+	}
+
+	return output, true, output.Validate()
+}
+
+// missingValue reports whether s means missing value.
+func missingValue(s string) bool {
+	switch strings.ToLower(s) {
+	case "", "null", "nan", "n/a", "-":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package ohlcio
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFromCSV(t *testing.T) {
+	tests := []struct {
+		label  string
+		input  string
+		cnt    int
+		last   string // close of the last bar
+		hasErr bool
+	}{
+		{
+			label: "trada format",
+			input: "Date;Open;High;Low;Close;Volume\n2019-10-29;1.1;1.2;1.0;1.15;0\n2019-10-30;1.15;1.3;1.1;1.25;10\n",
+			cnt:   2, last: "1.25",
+		},
+		{
+			label: "comma and column order",
+			input: "close,date\n108.5,2019-10-29\n",
+			cnt:   1, last: "108.5",
+		},
+		{label: "empty", input: "", cnt: 0},
+		{label: "no close column", input: "Date,Open\n2019-10-29,1.1\n", hasErr: true},
+		{label: "invalid date", input: "Date,Close\n29.10.2019,1.1\n", hasErr: true},
+		{label: "invalid price", input: "Date,Close\n2019-10-29,x\n", hasErr: true},
+	}
+
+	for _, tc := range tests {
+		bars, err := FromCSV(strings.NewReader(tc.input))
+		switch {
+		case tc.hasErr && err == nil:
+			t.Errorf("%s - should have an error", tc.label)
+		case !tc.hasErr && err != nil:
+			t.Errorf("%s - unexpected error: %v", tc.label, err)
+		case tc.hasErr:
+		case len(bars) != tc.cnt:
+			t.Errorf("%s - expected %d bars, got %d", tc.label, tc.cnt, len(bars))
+		case tc.cnt > 0 && bars[len(bars)-1].Close.String() != tc.last:
+			t.Errorf("%s - last close should be %s, got %s", tc.label, tc.last, bars[len(bars)-1].Close)
+		}
+	}
+}
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		label    string
+		input    string
+		opts     ImportOpts
+		format   Format
+		cnt      int
+		first    string // date of the first bar
+		last     string // close of the last bar
+		lastOpen string // open of the last bar
+		hasErr   bool
+	}{
+		{
+			label:  "stooq",
+			input:  "Data,Otwarcie,Najwyzszy,Najnizszy,Zamkniecie,Wolumen\n2019-10-29,10,11,9,10.5,1000\n2019-10-30,10.5,12,10,11,1200\n",
+			format: FormatStooq, cnt: 2, first: "2019-10-29", last: "11", lastOpen: "10.5",
+		},
+		{
+			label: "stooq ascii",
+			input: "<TICKER>,<PER>,<DATE>,<TIME>,<OPEN>,<HIGH>,<LOW>,<CLOSE>,<VOL>,<OPENINT>\n" +
+				"AAPL.US,D,20191030,000000,244.76,245.3,241.21,243.26,31130522,0\n" +
+				"AAPL.US,D,20191029,000000,248.97,249.75,242.57,243.29,35709867,0\n",
+			format: FormatStooqASCII, cnt: 2, first: "2019-10-29", last: "243.26", lastOpen: "244.76",
+		},
+		{
+			label: "stooq ascii intraday",
+			input: "<TICKER>,<PER>,<DATE>,<TIME>,<OPEN>,<HIGH>,<LOW>,<CLOSE>,<VOL>,<OPENINT>\n" +
+				"AAPL.US,5,20191030,153000,244.76,245.3,241.21,243.26,31130522,0\n",
+			hasErr: true,
+		},
+		{
+			label: "yahoo with null row",
+			input: "Date,Open,High,Low,Close,Adj Close,Volume\n" +
+				"2019-10-29,100,110,90,100,50,1000\n2019-10-30,null,null,null,null,null,null\n2019-10-31,100,110,90,104,52,1000\n",
+			format: FormatYahoo, cnt: 2, first: "2019-10-29", last: "104", lastOpen: "100",
+		},
+		{
+			label: "yahoo adjusted",
+			input: "Date,Open,High,Low,Close,Adj Close,Volume\n" +
+				"2019-10-29,100,110,90,100,50,1000\n2019-10-31,100,110,90,104,52,1000\n",
+			opts:   ImportOpts{Adjusted: true},
+			format: FormatYahoo, cnt: 2, first: "2019-10-29", last: "52", lastOpen: "50",
+		},
+		{
+			label:  "adjusted without adj close column",
+			input:  "Date;Open;High;Low;Close;Volume\n2019-10-29;1;1;1;1;0\n",
+			opts:   ImportOpts{Adjusted: true},
+			hasErr: true,
+		},
+		{label: "unknown format", input: "Day,Price\n2019-10-29,1\n", hasErr: true},
+	}
+
+	for _, tc := range tests {
+		bars, format, err := Import(strings.NewReader(tc.input), tc.opts)
+		switch {
+		case tc.hasErr && err == nil:
+			t.Errorf("%s - should have an error", tc.label)
+		case !tc.hasErr && err != nil:
+			t.Errorf("%s - unexpected error: %v", tc.label, err)
+		case tc.hasErr:
+		case format != tc.format:
+			t.Errorf("%s - expected format %s, got %s", tc.label, tc.format, format)
+		case len(bars) != tc.cnt:
+			t.Errorf("%s - expected %d bars, got %d", tc.label, tc.cnt, len(bars))
+		case bars[0].Date.String() != tc.first:
+			t.Errorf("%s - first date should be %s, got %s", tc.label, tc.first, bars[0].Date)
+		case bars[len(bars)-1].Close.String() != tc.last || bars[len(bars)-1].Open.String() != tc.lastOpen:
+			t.Errorf("%s - last open/close should be %s/%s, got %s/%s", tc.label,
+				tc.lastOpen, tc.last, bars[len(bars)-1].Open, bars[len(bars)-1].Close)
+		}
+	}
+}
@@ -11,11 +11,14 @@ import (
 var CSVheader = []string{"Date", "Open", "High", "Low", "Close", "Volume"}
 
 // ToCSV converts []ohlc.OHLC into CSV representation which is [][]string.
-func ToCSV(ohlcLst []ohlc.OHLC, s instrument.Security) [][]string {
+// Prices are formatted with s.PricePrecision() decimal places,
+// volume with s.QtyPrecision() decimal places.
+func ToCSV(ohlcLst []ohlc.OHLC, s instrument.Spec) [][]string {
 	dataCSV := [][]string{}
 	dataCSV = append(dataCSV, CSVheader)
 
-	decimalPlaces := int32(instrument.SecurityDecimalPlaces(s))
+	decimalPlaces := int32(s.PricePrecision())
+	volDecimalPlaces := int32(s.QtyPrecision())
 
 	for _, ohlc := range ohlcLst {
 		row := []string{
@@ -24,12 +27,7 @@ func ToCSV(ohlcLst []ohlc.OHLC, s instrument.Security) [][]string {
 			fmt.Sprintf("%s", ohlc.High.StringFixed(decimalPlaces)),
 			fmt.Sprintf("%s", ohlc.Low.StringFixed(decimalPlaces)),
 			fmt.Sprintf("%s", ohlc.Close.StringFixed(decimalPlaces)),
-		}
-		switch s {
-		case instrument.Crypto: // volume with decimal places
-			row = append(row, fmt.Sprintf("%s", ohlc.Volume.StringFixed(decimalPlaces)))
-		default: // volume as integer
-			row = append(row, fmt.Sprintf("%s", ohlc.Volume.StringFixed(0)))
+			fmt.Sprintf("%s", ohlc.Volume.StringFixed(volDecimalPlaces)),
 		}
 
 		dataCSV = append(dataCSV, row)
@@ -0,0 +1,65 @@
+package panel
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/model/ohlc/ohlcio"
+)
+
+// Load creates Panel of instruments from <dir>/<symbol>.csv files
+// (as written by data fetchers). Instruments without data file
+// are skipped and reported by returned list.
+func Load(dir string, specs []instrument.Spec, opts Opts) (Panel, []string, error) {
+	loaded := []instrument.Spec{}
+	vecs := []ohlc.Vec{}
+	skipped := []string{}
+
+	for _, s := range specs {
+		fpath := filepath.Join(dir, s.Symbol+".csv")
+		fd, err := os.Open(fpath)
+		if os.IsNotExist(err) {
+			skipped = append(skipped, s.Symbol)
+			continue
+		}
+		if err != nil {
+			return Panel{}, skipped, err
+		}
+		data, err := ohlcio.FromCSV(fd)
+		fd.Close()
+		if err != nil {
+			return Panel{}, skipped, fmt.Errorf("%s: %v", fpath, err)
+		}
+		v, err := ohlc.NewVec(data, 24*time.Hour)
+		if err != nil {
+			return Panel{}, skipped, fmt.Errorf("%s: %v", fpath, err)
+		}
+
+		loaded = append(loaded, s)
+		vecs = append(vecs, v)
+	}
+
+	p, err := New(loaded, vecs, opts)
+	return p, skipped, err
+}
+
+// LoadWatchlist creates Panel of instruments of watchlist CSV
+// (see instrument.SpecLstFromCSV) with data files in dir.
+func LoadWatchlist(dir, watchlist string, opts Opts) (Panel, []string, error) {
+	fd, err := os.Open(watchlist)
+	if err != nil {
+		return Panel{}, []string{}, err
+	}
+	defer fd.Close()
+
+	specs, err := instrument.SpecLstFromCSV(fd)
+	if err != nil {
+		return Panel{}, []string{}, fmt.Errorf("%s: %v", watchlist, err)
+	}
+
+	return Load(dir, specs, opts)
+}
@@ -0,0 +1,349 @@
+// Package panel aligns OHLC series of many instruments by date.
+package panel
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+// Calendar defines dates of Panel.
+type Calendar int
+
+// Calendar values.
+const (
+	Union        Calendar = iota // dates of any series
+	Intersection                 // dates common to all series
+)
+
+// String implements fmt.Stringer.
+func (c Calendar) String() string {
+	switch c {
+	case Union:
+		return "union"
+	case Intersection:
+		return "intersection"
+	}
+	return fmt.Sprintf("Calendar(%d)", int(c))
+}
+
+// Fill defines handling of missing bars.
+type Fill int
+
+// Fill values.
+const (
+	FillNone    Fill = iota // missing bars are not available
+	FillForward             // missing bars are flat bars at the previous Close with zero Volume
+)
+
+// String implements fmt.Stringer.
+func (f Fill) String() string {
+	switch f {
+	case FillNone:
+		return "none"
+	case FillForward:
+		return "ffill"
+	}
+	return fmt.Sprintf("Fill(%d)", int(f))
+}
+
+// Opts are Panel options.
+type Opts struct {
+	Calendar Calendar
+	Fill     Fill
+	MaxFill  int // max. consecutive filled bars; 0 means unlimited
+}
+
+// Validate checks if Opts are valid.
+func (o Opts) Validate() error {
+	switch {
+	case o.Calendar != Union && o.Calendar != Intersection:
+		return fmt.Errorf("invalid Calendar %s", o.Calendar)
+
+	case o.Fill != FillNone && o.Fill != FillForward:
+		return fmt.Errorf("invalid Fill %s", o.Fill)
+
+	case o.MaxFill < 0:
+		return errors.New("MaxFill is < 0")
+	}
+
+	return nil
+}
+
+// Field of OHLC bar.
+type Field int
+
+// Field values.
+const (
+	Open Field = iota
+	High
+	Low
+	Close
+	Volume
+)
+
+// String implements fmt.Stringer.
+func (f Field) String() string {
+	switch f {
+	case Open:
+		return "open"
+	case High:
+		return "high"
+	case Low:
+		return "low"
+	case Close:
+		return "close"
+	case Volume:
+		return "volume"
+	}
+	return fmt.Sprintf("Field(%d)", int(f))
+}
+
+// value provides field of bar.
+func (f Field) value(bar ohlc.OHLC) decimal.Decimal {
+	switch f {
+	case Open:
+		return bar.Open
+	case High:
+		return bar.High
+	case Low:
+		return bar.Low
+	case Volume:
+		return bar.Volume
+	}
+	return bar.Close
+}
+
+// cell state
+const (
+	missing byte = iota
+	present
+	filled
+)
+
+// Panel is OHLC data of instruments aligned by date.
+// Panel is immutable; methods provide copies of data.
+type Panel struct {
+	dates []typedef.Date
+	specs []instrument.Spec
+	bars  [][]ohlc.OHLC // [instrument][date]
+	state [][]byte      // [instrument][date]
+}
+
+// New creates Panel of instruments specs with data vecs.
+func New(specs []instrument.Spec, vecs []ohlc.Vec, opts Opts) (Panel, error) {
+	p := Panel{}
+	switch {
+	case len(specs) != len(vecs):
+		return p, fmt.Errorf("number of specs %d and vecs %d differs", len(specs), len(vecs))
+
+	case opts.Validate() != nil:
+		return p, opts.Validate()
+	}
+
+	symbols := map[string]bool{}
+	data := make([][]ohlc.OHLC, len(vecs))
+	count := map[typedef.Date]int{}
+	for i, v := range vecs {
+		if symbols[specs[i].Symbol] {
+			return p, fmt.Errorf("duplicate symbol %s", specs[i].Symbol)
+		}
+		symbols[specs[i].Symbol] = true
+
+		data[i] = v.Data()
+		for _, bar := range data[i] {
+			count[bar.Date]++
+		}
+	}
+
+	dates := make([]typedef.Date, 0, len(count))
+	for d, n := range count {
+		if opts.Calendar == Intersection && n != len(vecs) {
+			continue
+		}
+		dates = append(dates, d)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Time().Before(dates[j].Time()) })
+
+	p.dates = dates
+	p.specs = append([]instrument.Spec{}, specs...)
+	p.bars = make([][]ohlc.OHLC, len(specs))
+	p.state = make([][]byte, len(specs))
+	for i := range specs {
+		p.bars[i], p.state[i] = align(data[i], dates, opts)
+	}
+
+	return p, nil
+}
+
+// align places sorted bars at dates.
+func align(data []ohlc.OHLC, dates []typedef.Date, opts Opts) ([]ohlc.OHLC, []byte) {
+	bars := make([]ohlc.OHLC, len(dates))
+	state := make([]byte, len(dates))
+
+	j := 0
+	gap := 0
+	for i, d := range dates {
+		for j < len(data) && data[j].Date.Time().Before(d.Time()) {
+			j++ // bar not in calendar
+		}
+		if j < len(data) && data[j].Date == d {
+			bars[i], state[i] = data[j], present
+			gap = 0
+			continue
+		}
+
+		if opts.Fill != FillForward || i == 0 || state[i-1] == missing {
+			continue
+		}
+		if opts.MaxFill > 0 && gap >= opts.MaxFill {
+			continue
+		}
+		c := bars[i-1].Close
+		bars[i] = ohlc.OHLC{Date: d, Open: c, High: c, Low: c, Close: c}
+		state[i] = filled
+		gap++
+	}
+
+	return bars, state
+}
+
+// Len provides number of dates.
+func (p Panel) Len() int {
+	return len(p.dates)
+}
+
+// Dates provides dates of Panel.
+func (p Panel) Dates() []typedef.Date {
+	return append([]typedef.Date{}, p.dates...)
+}
+
+// Specs provides instruments of Panel.
+func (p Panel) Specs() []instrument.Spec {
+	return append([]instrument.Spec{}, p.specs...)
+}
+
+// Symbols provides symbols of instruments.
+func (p Panel) Symbols() []string {
+	output := make([]string, len(p.specs))
+	for i, s := range p.specs {
+		output[i] = s.Symbol
+	}
+	return output
+}
+
+// index provides index of symbol.
+func (p Panel) index(symbol string) (int, error) {
+	for i, s := range p.specs {
+		if s.Symbol == symbol {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("symbol %s not found", symbol)
+}
+
+// Column is field of instrument aligned to Panel dates.
+type Column struct {
+	Dates  []typedef.Date
+	Values []decimal.Decimal // zero if not Valid
+	Valid  []bool
+}
+
+// Column provides field of instrument.
+func (p Panel) Column(symbol string, f Field) (Column, error) {
+	i, err := p.index(symbol)
+	if err != nil {
+		return Column{}, err
+	}
+
+	c := Column{
+		Dates:  p.Dates(),
+		Values: make([]decimal.Decimal, len(p.dates)),
+		Valid:  make([]bool, len(p.dates)),
+	}
+	for j, bar := range p.bars[i] {
+		if p.state[i][j] != missing {
+			c.Values[j] = f.value(bar)
+			c.Valid[j] = true
+		}
+	}
+
+	return c, nil
+}
+
+// Vec provides available (present and filled) bars of instrument.
+func (p Panel) Vec(symbol string, timeframe time.Duration) (ohlc.Vec, error) {
+	i, err := p.index(symbol)
+	if err != nil {
+		return ohlc.Vec{}, err
+	}
+
+	lst := []ohlc.OHLC{}
+	for j, bar := range p.bars[i] {
+		if p.state[i][j] != missing {
+			lst = append(lst, bar)
+		}
+	}
+
+	return ohlc.NewVec(lst, timeframe)
+}
+
+// Quote is bar of instrument in cross-section.
+type Quote struct {
+	Spec   instrument.Spec
+	Bar    ohlc.OHLC
+	Filled bool // bar was filled, it is not in source data
+}
+
+// CrossSection provides available bars of all instruments at date d.
+func (p Panel) CrossSection(d typedef.Date) ([]Quote, error) {
+	j := sort.Search(len(p.dates), func(i int) bool { return !p.dates[i].Time().Before(d.Time()) })
+	if j == len(p.dates) || p.dates[j] != d {
+		return []Quote{}, fmt.Errorf("date %s not found", d)
+	}
+
+	output := []Quote{}
+	for i, s := range p.specs {
+		if p.state[i][j] == missing {
+			continue
+		}
+		output = append(output, Quote{Spec: s, Bar: p.bars[i][j], Filled: p.state[i][j] == filled})
+	}
+
+	return output, nil
+}
+
+// Slice provides Panel restricted to dates from - to (inclusive).
+// Zero date means unbounded.
+func (p Panel) Slice(from, to typedef.Date) Panel {
+	lo, hi := 0, len(p.dates)
+	if !from.Time().IsZero() {
+		lo = sort.Search(len(p.dates), func(i int) bool { return !p.dates[i].Time().Before(from.Time()) })
+	}
+	if !to.Time().IsZero() {
+		hi = sort.Search(len(p.dates), func(i int) bool { return p.dates[i].Time().After(to.Time()) })
+	}
+	if hi < lo {
+		hi = lo
+	}
+
+	output := Panel{
+		dates: append([]typedef.Date{}, p.dates[lo:hi]...),
+		specs: p.Specs(),
+		bars:  make([][]ohlc.OHLC, len(p.specs)),
+		state: make([][]byte, len(p.specs)),
+	}
+	for i := range p.specs {
+		output.bars[i] = append([]ohlc.OHLC{}, p.bars[i][lo:hi]...)
+		output.state[i] = append([]byte{}, p.state[i][lo:hi]...)
+	}
+
+	return output
+}
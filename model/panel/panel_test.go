@@ -0,0 +1,202 @@
+package panel
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+func date(s string) typedef.Date {
+	d, err := typedef.DateFromStr(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// mkVec creates Vec from "date:close" items.
+func mkVec(t *testing.T, items ...string) ohlc.Vec {
+	lst := []ohlc.OHLC{}
+	for _, it := range items {
+		f := strings.Split(it, ":")
+		c := decimal.RequireFromString(f[1])
+		lst = append(lst, ohlc.OHLC{Date: date(f[0]), Open: c, High: c, Low: c, Close: c, Volume: decimal.New(100, 0)})
+	}
+	v, err := ohlc.NewVec(lst, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// column formats column as "date:value" items, "-" for invalid values.
+func column(c Column) string {
+	items := []string{}
+	for i, d := range c.Dates {
+		v := "-"
+		if c.Valid[i] {
+			v = c.Values[i].String()
+		}
+		items = append(items, d.String()[8:]+":"+v)
+	}
+	return strings.Join(items, " ")
+}
+
+func TestNew(t *testing.T) {
+	specs := []instrument.Spec{
+		{Symbol: "AAA", SecurityType: instrument.Equity},
+		{Symbol: "BBB", SecurityType: instrument.Equity},
+	}
+	vecs := []ohlc.Vec{
+		mkVec(t, "2020-01-02:10", "2020-01-03:11", "2020-01-06:12", "2020-01-07:13", "2020-01-08:14"),
+		mkVec(t, "2020-01-03:20", "2020-01-08:21"),
+	}
+
+	tests := []struct {
+		label string
+		opts  Opts
+		aaa   string
+		bbb   string
+	}{
+		{
+			label: "union",
+			opts:  Opts{Calendar: Union},
+			aaa:   "02:10 03:11 06:12 07:13 08:14",
+			bbb:   "02:- 03:20 06:- 07:- 08:21",
+		},
+		{
+			label: "union ffill",
+			opts:  Opts{Calendar: Union, Fill: FillForward},
+			bbb:   "02:- 03:20 06:20 07:20 08:21",
+		},
+		{
+			label: "union ffill limited",
+			opts:  Opts{Calendar: Union, Fill: FillForward, MaxFill: 1},
+			bbb:   "02:- 03:20 06:20 07:- 08:21",
+		},
+		{
+			label: "intersection",
+			opts:  Opts{Calendar: Intersection},
+			aaa:   "03:11 08:14",
+			bbb:   "03:20 08:21",
+		},
+	}
+
+	for _, tc := range tests {
+		p, err := New(specs, vecs, tc.opts)
+		if err != nil {
+			t.Errorf("%s - unexpected error: %v", tc.label, err)
+			continue
+		}
+		for sym, expected := range map[string]string{"AAA": tc.aaa, "BBB": tc.bbb} {
+			if expected == "" {
+				continue
+			}
+			c, err := p.Column(sym, Close)
+			if err != nil {
+				t.Errorf("%s - unexpected error: %v", tc.label, err)
+				continue
+			}
+			if column(c) != expected {
+				t.Errorf("%s - %s should be %q, got %q", tc.label, sym, expected, column(c))
+			}
+		}
+	}
+
+	if _, err := New(specs, vecs[:1], Opts{}); err == nil {
+		t.Errorf("specs and vecs mismatch - should have an error")
+	}
+	if _, err := New([]instrument.Spec{specs[0], specs[0]}, vecs, Opts{}); err == nil {
+		t.Errorf("duplicate symbol - should have an error")
+	}
+	if _, err := New(specs, vecs, Opts{MaxFill: -1}); err == nil {
+		t.Errorf("negative MaxFill - should have an error")
+	}
+}
+
+func TestSliceAndCrossSection(t *testing.T) {
+	specs := []instrument.Spec{
+		{Symbol: "AAA", SecurityType: instrument.Equity},
+		{Symbol: "BBB", SecurityType: instrument.Equity},
+	}
+	vecs := []ohlc.Vec{
+		mkVec(t, "2020-01-02:10", "2020-01-03:11", "2020-01-06:12"),
+		mkVec(t, "2020-01-02:20", "2020-01-06:21"),
+	}
+	p, err := New(specs, vecs, Opts{Fill: FillForward})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cs, err := p.CrossSection(date("2020-01-03"))
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case len(cs) != 2 || cs[0].Filled || !cs[1].Filled || !cs[1].Bar.Close.Equal(decimal.New(20, 0)) ||
+		!cs[1].Bar.Volume.IsZero():
+		t.Errorf("unexpected cross-section: %+v", cs)
+	}
+	if _, err := p.CrossSection(date("2020-01-04")); err == nil {
+		t.Errorf("date not in panel - should have an error")
+	}
+
+	s := p.Slice(date("2020-01-03"), typedef.Date{})
+	if s.Len() != 2 || s.Dates()[0] != date("2020-01-03") {
+		t.Errorf("unexpected slice dates: %v", s.Dates())
+	}
+	c, err := s.Column("BBB", Volume)
+	if err != nil || column(c) != "03:0 06:100" {
+		t.Errorf("unexpected sliced column: %s (%v)", column(c), err)
+	}
+	if e := p.Slice(date("2020-02-01"), date("2020-01-01")); e.Len() != 0 {
+		t.Errorf("empty range should have no dates, got %d", e.Len())
+	}
+
+	v, err := p.Vec("BBB", 24*time.Hour)
+	if err != nil || len(v.Dates()) != 3 {
+		t.Errorf("unexpected Vec: %v (%v)", v.Dates(), err)
+	}
+	if _, err := p.Column("XYZ", Close); err == nil {
+		t.Errorf("unknown symbol - should have an error")
+	}
+}
+
+func TestLoadWatchlist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "panel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("watchlist.csv", "sym;name;security\nAAA;A Inc;equity\nBBB;B Inc;equity\nCCC;C Inc;equity\n")
+	for i, sym := range []string{"AAA", "BBB"} {
+		write(sym+".csv", fmt.Sprintf("Date;Open;High;Low;Close;Volume\n2020-01-02;1;1;1;%d;10\n2020-01-03;1;1;1;2;10\n", i+1))
+	}
+
+	p, skipped, err := LoadWatchlist(dir, filepath.Join(dir, "watchlist.csv"), Opts{})
+	switch {
+	case err != nil:
+		t.Fatalf("unexpected error: %v", err)
+	case strings.Join(p.Symbols(), ",") != "AAA,BBB":
+		t.Errorf("unexpected symbols: %v", p.Symbols())
+	case strings.Join(skipped, ",") != "CCC":
+		t.Errorf("CCC should be skipped, got %v", skipped)
+	case p.Len() != 2:
+		t.Errorf("should have 2 dates, got %d", p.Len())
+	}
+}
@@ -0,0 +1,75 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/pkg/typedef"
+)
+
+// Series is price series of instrument used for marking positions.
+type Series struct {
+	Spec instrument.Spec
+	Data ohlc.Vec
+}
+
+// Point is point of equity curve.
+type Point struct {
+	Date    typedef.Date
+	Summary map[string]Summary // by currency
+}
+
+// EquityCurve books trades and marks positions to Close of series
+// at each date of series. Trades are booked before marking at their date,
+// trades dated before the first date are booked at the first date.
+// Trades must be sorted by date.
+// The last known Close is used for instruments without bar at the date.
+func (p *Portfolio) EquityCurve(trades []Trade, prices []Series) ([]Point, error) {
+	output := []Point{}
+
+	type feed struct {
+		spec instrument.Spec
+		data []ohlc.OHLC
+		idx  int
+	}
+	feeds := make([]*feed, 0, len(prices))
+	set := map[typedef.Date]bool{}
+	for _, s := range prices {
+		f := &feed{spec: s.Spec, data: s.Data.Data()}
+		for _, bar := range f.data {
+			set[bar.Date] = true
+		}
+		feeds = append(feeds, f)
+	}
+	dates := make([]typedef.Date, 0, len(set))
+	for d := range set {
+		dates = append(dates, d)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Time().Before(dates[j].Time()) })
+
+	ti := 0
+	for _, d := range dates {
+		for ; ti < len(trades) && !trades[ti].Date.Time().After(d.Time()); ti++ {
+			if _, err := p.Trade(trades[ti]); err != nil {
+				return output, fmt.Errorf("trade %d: %v", ti, err)
+			}
+		}
+		for _, f := range feeds {
+			if f.idx < len(f.data) && f.data[f.idx].Date == d {
+				if err := p.Mark(f.spec, f.data[f.idx]); err != nil {
+					return output, err
+				}
+				f.idx++
+			}
+		}
+		output = append(output, Point{Date: d, Summary: p.Summary()})
+	}
+
+	if ti < len(trades) {
+		return output, fmt.Errorf("trade %d: date %s is after the last price date", ti, trades[ti].Date)
+	}
+
+	return output, nil
+}
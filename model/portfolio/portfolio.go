@@ -0,0 +1,274 @@
+// Package portfolio provides accounting of positions, cash and P&L.
+//
+// Positions are tracked in lots matched by Method (FIFO, LIFO or
+// average cost). Commissions are realized when paid.
+// P&L and market values are in price currency of the instrument
+// rounded to its price precision (see instrument.Spec.PricePrecision,
+// SecurityDecimalPlaces by default).
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+// Method of matching closing trades with open lots.
+type Method int
+
+// Method values.
+const (
+	FIFO        Method = iota // first in, first out
+	LIFO                      // last in, first out
+	AverageCost               // single lot at average price
+)
+
+// String implements fmt.Stringer.
+func (m Method) String() string {
+	switch m {
+	case FIFO:
+		return "fifo"
+	case LIFO:
+		return "lifo"
+	case AverageCost:
+		return "average"
+	}
+	return fmt.Sprintf("Method(%d)", int(m))
+}
+
+// Key identifies instrument in Portfolio.
+type Key struct {
+	Symbol       string
+	SecurityType instrument.Security
+	Exchange     string
+}
+
+// KeyOf provides Key of instrument.
+func KeyOf(s instrument.Spec) Key {
+	return Key{Symbol: s.Symbol, SecurityType: s.SecurityType, Exchange: s.Exchange}
+}
+
+// Trade is execution of buy (positive Qty) or sell (negative Qty).
+type Trade struct {
+	Date       typedef.Date
+	Spec       instrument.Spec
+	Qty        decimal.Decimal
+	Price      decimal.Decimal
+	Commission decimal.Decimal
+
+	// set by Portfolio
+	Realized decimal.Decimal // realized P&L including commission
+}
+
+// Validate checks if Trade is valid.
+func (t Trade) Validate() error {
+	switch {
+	case t.Spec.Validate() != nil:
+		return fmt.Errorf("invalid Spec: %v", t.Spec.Validate())
+
+	case t.Qty.IsZero():
+		return fmt.Errorf("%s: Qty is zero", t.Spec.Symbol)
+
+	case t.Spec.ValidateQty(t.Qty.Abs()) != nil:
+		return t.Spec.ValidateQty(t.Qty.Abs())
+
+	case !t.Price.IsPositive():
+		return fmt.Errorf("%s: Price %s is not positive", t.Spec.Symbol, t.Price)
+
+	case t.Commission.IsNegative():
+		return fmt.Errorf("%s: Commission %s is less than zero", t.Spec.Symbol, t.Commission)
+	}
+
+	return nil
+}
+
+// Portfolio holds positions, cash balances per currency and trade ledger.
+type Portfolio struct {
+	method    Method
+	currency  string // of instruments without price currency
+	cash      map[string]decimal.Decimal
+	positions map[Key]*Position
+	ledger    []Trade
+}
+
+// New creates empty Portfolio. Currency is used for instruments
+// without price currency (see instrument.Spec.PriceCurrency).
+func New(m Method, currency string) (*Portfolio, error) {
+	switch {
+	case m != FIFO && m != LIFO && m != AverageCost:
+		return nil, fmt.Errorf("invalid method %s", m)
+
+	case instrument.ValidateCurrency(currency) != nil:
+		return nil, fmt.Errorf("currency: %v", instrument.ValidateCurrency(currency))
+	}
+
+	return &Portfolio{
+		method:    m,
+		currency:  currency,
+		cash:      map[string]decimal.Decimal{},
+		positions: map[Key]*Position{},
+	}, nil
+}
+
+// Method provides lot matching method.
+func (p *Portfolio) Method() Method {
+	return p.method
+}
+
+// Currency provides price currency of instrument.
+func (p *Portfolio) Currency(s instrument.Spec) string {
+	if c := s.PriceCurrency(); c != "" {
+		return c
+	}
+	return p.currency
+}
+
+// Deposit adds amount (negative for withdrawal) to cash balance of currency.
+func (p *Portfolio) Deposit(currency string, amount decimal.Decimal) error {
+	if err := instrument.ValidateCurrency(currency); err != nil {
+		return err
+	}
+	p.cash[currency] = p.cash[currency].Add(amount)
+
+	return nil
+}
+
+// Cash provides cash balance of currency.
+func (p *Portfolio) Cash(currency string) decimal.Decimal {
+	return p.cash[currency]
+}
+
+// Balances provides cash balances of all currencies.
+func (p *Portfolio) Balances() map[string]decimal.Decimal {
+	output := make(map[string]decimal.Decimal, len(p.cash))
+	for c, v := range p.cash {
+		output[c] = v
+	}
+	return output
+}
+
+// Trade books trade and provides it with realized P&L.
+func (p *Portfolio) Trade(t Trade) (Trade, error) {
+	if err := t.Validate(); err != nil {
+		return t, err
+	}
+
+	if n := len(p.ledger); n > 0 && t.Date.Time().Before(p.ledger[n-1].Date.Time()) {
+		return t, fmt.Errorf("%s: trade date %s is before the last trade %s",
+			t.Spec.Symbol, t.Date, p.ledger[n-1].Date)
+	}
+
+	k := KeyOf(t.Spec)
+	pos, ok := p.positions[k]
+	if !ok {
+		pos = &Position{Spec: t.Spec}
+		p.positions[k] = pos
+	}
+
+	t.Realized = pos.book(t, p.method)
+
+	ccy := p.Currency(t.Spec)
+	value := t.Qty.Mul(t.Price).Mul(multiplier(t.Spec))
+	p.cash[ccy] = p.cash[ccy].Sub(value).Sub(t.Commission)
+	p.ledger = append(p.ledger, t)
+
+	return t, nil
+}
+
+// Ledger provides all booked trades.
+func (p *Portfolio) Ledger() []Trade {
+	return append([]Trade{}, p.ledger...)
+}
+
+// Mark sets mark price of instrument to Close of bar.
+func (p *Portfolio) Mark(s instrument.Spec, bar ohlc.OHLC) error {
+	pos, ok := p.positions[KeyOf(s)]
+	if !ok {
+		pos = &Position{Spec: s}
+		p.positions[KeyOf(s)] = pos
+	}
+	if !bar.Close.IsPositive() {
+		return fmt.Errorf("%s: mark price %s is not positive", s.Symbol, bar.Close)
+	}
+	pos.Mark = bar.Close
+	pos.MarkDate = bar.Date
+
+	return nil
+}
+
+// Position provides position of instrument.
+func (p *Portfolio) Position(s instrument.Spec) Position {
+	pos, ok := p.positions[KeyOf(s)]
+	if !ok {
+		return Position{Spec: s, Lots: []Lot{}}
+	}
+	return pos.copy()
+}
+
+// Positions provides open positions sorted by symbol.
+func (p *Portfolio) Positions() []Position {
+	output := []Position{}
+	for _, pos := range p.positions {
+		if !pos.Qty().IsZero() {
+			output = append(output, pos.copy())
+		}
+	}
+	sort.Slice(output, func(i, j int) bool {
+		ki, kj := KeyOf(output[i].Spec), KeyOf(output[j].Spec)
+		if ki.Symbol != kj.Symbol {
+			return ki.Symbol < kj.Symbol
+		}
+		if ki.SecurityType != kj.SecurityType {
+			return ki.SecurityType < kj.SecurityType
+		}
+		return ki.Exchange < kj.Exchange
+	})
+
+	return output
+}
+
+// Summary is portfolio valuation in one currency.
+type Summary struct {
+	Cash       decimal.Decimal
+	Value      decimal.Decimal // market value of positions
+	Equity     decimal.Decimal // Cash + Value
+	Realized   decimal.Decimal
+	Unrealized decimal.Decimal
+}
+
+// Summary provides valuation per currency.
+// Positions without mark price are valued at cost.
+func (p *Portfolio) Summary() map[string]Summary {
+	output := map[string]Summary{}
+	for c, v := range p.cash {
+		output[c] = Summary{Cash: v, Equity: v}
+	}
+
+	for _, pos := range p.positions {
+		c := p.Currency(pos.Spec)
+		s := output[c]
+		s.Realized = s.Realized.Add(pos.Realized)
+		s.Unrealized = s.Unrealized.Add(pos.Unrealized())
+		s.Value = s.Value.Add(pos.Value())
+		s.Equity = s.Cash.Add(s.Value)
+		output[c] = s
+	}
+
+	return output
+}
+
+// multiplier provides contract multiplier of futures and options, 1 otherwise.
+func multiplier(s instrument.Spec) decimal.Decimal {
+	switch {
+	case s.Future != nil && s.Future.Multiplier.IsPositive():
+		return s.Future.Multiplier
+	case s.Option != nil && s.Option.Multiplier.IsPositive():
+		return s.Option.Multiplier
+	}
+	return decimal.New(1, 0)
+}
@@ -0,0 +1,189 @@
+package portfolio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func date(s string) typedef.Date {
+	d, err := typedef.DateFromStr(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func bar(d, close string) ohlc.OHLC {
+	c := dec(close)
+	return ohlc.OHLC{Date: date(d), Open: c, High: c, Low: c, Close: c}
+}
+
+func TestMethods(t *testing.T) {
+	spy := instrument.Spec{Symbol: "SPY", SecurityType: instrument.Equity, Exchange: "NYSE"}
+	trades := []Trade{
+		{Date: date("2020-01-02"), Spec: spy, Qty: dec("10"), Price: dec("100"), Commission: dec("1")},
+		{Date: date("2020-01-03"), Spec: spy, Qty: dec("10"), Price: dec("110"), Commission: dec("1")},
+		{Date: date("2020-01-06"), Spec: spy, Qty: dec("-15"), Price: dec("120"), Commission: dec("1")},
+	}
+
+	tests := []struct {
+		method     Method
+		realized   string // of the last trade
+		lots       []Lot
+		unrealized string
+	}{
+		{method: FIFO, realized: "249", lots: []Lot{{Qty: dec("5"), Price: dec("110")}}, unrealized: "100"},
+		{method: LIFO, realized: "199", lots: []Lot{{Qty: dec("5"), Price: dec("100")}}, unrealized: "150"},
+		{method: AverageCost, realized: "224", lots: []Lot{{Qty: dec("5"), Price: dec("105")}}, unrealized: "125"},
+	}
+
+	for _, tc := range tests {
+		p, err := New(tc.method, "USD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Deposit("USD", dec("10000"))
+
+		var last Trade
+		for _, tr := range trades {
+			last, err = p.Trade(tr)
+			if err != nil {
+				t.Fatalf("%s - unexpected error: %v", tc.method, err)
+			}
+		}
+		p.Mark(spy, bar("2020-01-06", "130"))
+
+		pos := p.Position(spy)
+		sum := p.Summary()["USD"]
+		switch {
+		case !last.Realized.Equal(dec(tc.realized)):
+			t.Errorf("%s - realized should be %s, got %s", tc.method, tc.realized, last.Realized)
+		case len(pos.Lots) != len(tc.lots) || !pos.Lots[0].Qty.Equal(tc.lots[0].Qty) ||
+			!pos.Lots[0].Price.Equal(tc.lots[0].Price):
+			t.Errorf("%s - unexpected lots: %+v", tc.method, pos.Lots)
+		case !pos.Unrealized().Equal(dec(tc.unrealized)):
+			t.Errorf("%s - unrealized should be %s, got %s", tc.method, tc.unrealized, pos.Unrealized())
+		case !sum.Cash.Equal(dec("9697")):
+			t.Errorf("%s - cash should be 9697, got %s", tc.method, sum.Cash)
+		case !sum.Equity.Equal(dec("10347")):
+			t.Errorf("%s - equity should be 10347, got %s", tc.method, sum.Equity)
+		case !sum.Realized.Add(sum.Unrealized).Equal(dec("347")):
+			t.Errorf("%s - total P&L should be 347, got %s + %s", tc.method, sum.Realized, sum.Unrealized)
+		}
+	}
+}
+
+func TestTrade(t *testing.T) {
+	p, err := New(FIFO, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	xyz := instrument.Spec{Symbol: "XYZ", SecurityType: instrument.Equity}
+	es, err := instrument.ParseSpec("ESZ19", instrument.Future)
+	if err != nil {
+		t.Fatal(err)
+	}
+	es.TickSize = dec("0.25")
+	es.Future.Multiplier = dec("50")
+	btc := instrument.Spec{Symbol: "btceur", SecurityType: instrument.Crypto, Currency: "EUR", PriceDecimals: 2}
+
+	tests := []struct {
+		label    string
+		trade    Trade
+		realized string
+		hasErr   bool
+	}{
+		{label: "short", trade: Trade{Date: date("2020-01-02"), Spec: xyz, Qty: dec("-5"), Price: dec("50")}, realized: "0"},
+		{label: "cover and reverse", trade: Trade{Date: date("2020-01-02"), Spec: xyz, Qty: dec("8"), Price: dec("40")}, realized: "50"},
+		{label: "future", trade: Trade{Date: date("2020-01-03"), Spec: es, Qty: dec("1"), Price: dec("3000")}, realized: "0"},
+		{label: "future close", trade: Trade{Date: date("2020-01-03"), Spec: es, Qty: dec("-1"), Price: dec("3010.25")}, realized: "512.5"},
+		{label: "crypto", trade: Trade{Date: date("2020-01-03"), Spec: btc, Qty: dec("0.5"), Price: dec("7000.01")}, realized: "0"},
+		{label: "crypto close", trade: Trade{Date: date("2020-01-04"), Spec: btc, Qty: dec("-0.123"), Price: dec("7100.07")}, realized: "12.31"},
+		{label: "zero qty", trade: Trade{Date: date("2020-01-04"), Spec: xyz, Price: dec("40")}, hasErr: true},
+		{label: "fractional qty", trade: Trade{Date: date("2020-01-04"), Spec: xyz, Qty: dec("1.5"), Price: dec("40")}, hasErr: true},
+		{label: "zero price", trade: Trade{Date: date("2020-01-04"), Spec: xyz, Qty: dec("1")}, hasErr: true},
+		{label: "out of order", trade: Trade{Date: date("2020-01-01"), Spec: xyz, Qty: dec("1"), Price: dec("40")}, hasErr: true},
+	}
+
+	for _, tc := range tests {
+		tr, err := p.Trade(tc.trade)
+		switch {
+		case tc.hasErr && err == nil:
+			t.Errorf("%s - should have an error", tc.label)
+		case !tc.hasErr && err != nil:
+			t.Errorf("%s - unexpected error: %v", tc.label, err)
+		case tc.hasErr:
+		case !tr.Realized.Equal(dec(tc.realized)):
+			t.Errorf("%s - realized should be %s, got %s", tc.label, tc.realized, tr.Realized)
+		}
+	}
+
+	if q := p.Position(xyz).Qty(); !q.Equal(dec("3")) {
+		t.Errorf("XYZ position should be 3, got %s", q)
+	}
+	if c := p.Cash("USD"); !c.Equal(dec("442.5")) { // 250 - 320 - 150000 + 150512.5
+		t.Errorf("USD cash should be 442.5, got %s", c)
+	}
+	if c := p.Cash("EUR"); !c.Equal(dec("-2626.69639")) { // -3500.005 + 873.30861
+		t.Errorf("EUR cash should be -2626.69639, got %s", c)
+	}
+	if len(p.Ledger()) != 6 {
+		t.Errorf("ledger should have 6 trades, got %d", len(p.Ledger()))
+	}
+	if len(p.Positions()) != 2 {
+		t.Errorf("should have 2 open positions, got %+v", p.Positions())
+	}
+}
+
+func TestEquityCurve(t *testing.T) {
+	spy := instrument.Spec{Symbol: "SPY", SecurityType: instrument.Equity}
+	lst := []ohlc.OHLC{bar("2020-01-02", "100"), bar("2020-01-03", "102"), bar("2020-01-06", "101.5")}
+	v, err := ohlc.NewVec(lst, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := New(FIFO, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Deposit("USD", dec("1000"))
+	trades := []Trade{
+		{Date: date("2020-01-02"), Spec: spy, Qty: dec("5"), Price: dec("100")},
+		{Date: date("2020-01-06"), Spec: spy, Qty: dec("-2"), Price: dec("101")},
+	}
+
+	curve, err := p.EquityCurve(trades, []Series{{Spec: spy, Data: v}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"1000", "1010", "1006.5"}
+	if len(curve) != len(expected) {
+		t.Fatalf("curve should have %d points, got %d", len(expected), len(curve))
+	}
+	for i, e := range expected {
+		if eq := curve[i].Summary["USD"].Equity; !eq.Equal(dec(e)) {
+			t.Errorf("%s - equity should be %s, got %s", curve[i].Date, e, eq)
+		}
+	}
+	if r := curve[2].Summary["USD"].Realized; !r.Equal(dec("2")) {
+		t.Errorf("realized should be 2, got %s", r)
+	}
+
+	late := []Trade{{Date: date("2020-02-03"), Spec: spy, Qty: dec("1"), Price: dec("100")}}
+	if _, err := p.EquityCurve(late, []Series{{Spec: spy, Data: v}}); err == nil {
+		t.Errorf("trade after the last price - should have an error")
+	}
+}
@@ -0,0 +1,125 @@
+package portfolio
+
+import (
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+// Lot is open quantity bought (positive Qty) or sold short (negative Qty)
+// at Price.
+type Lot struct {
+	Date  typedef.Date // of the opening trade; of the last one for AverageCost
+	Qty   decimal.Decimal
+	Price decimal.Decimal
+}
+
+// Position is holding of an instrument.
+type Position struct {
+	Spec     instrument.Spec
+	Lots     []Lot           // open lots in order of opening
+	Realized decimal.Decimal // cumulative realized P&L
+	Mark     decimal.Decimal // the last mark price; zero if not marked
+	MarkDate typedef.Date
+}
+
+// Qty provides position size; short position is negative.
+func (p Position) Qty() decimal.Decimal {
+	qty := decimal.Zero
+	for _, l := range p.Lots {
+		qty = qty.Add(l.Qty)
+	}
+	return qty
+}
+
+// Cost provides cost basis of open lots.
+func (p Position) Cost() decimal.Decimal {
+	cost := decimal.Zero
+	for _, l := range p.Lots {
+		cost = cost.Add(l.Qty.Mul(l.Price))
+	}
+	return p.round(cost.Mul(multiplier(p.Spec)))
+}
+
+// AvgPrice provides average price of open lots.
+func (p Position) AvgPrice() decimal.Decimal {
+	qty := p.Qty()
+	if qty.IsZero() {
+		return decimal.Zero
+	}
+	cost := decimal.Zero
+	for _, l := range p.Lots {
+		cost = cost.Add(l.Qty.Mul(l.Price))
+	}
+	return cost.Div(qty)
+}
+
+// Value provides market value of position at Mark,
+// at cost if position is not marked.
+func (p Position) Value() decimal.Decimal {
+	if p.Mark.IsZero() {
+		return p.Cost()
+	}
+	return p.round(p.Qty().Mul(p.Mark).Mul(multiplier(p.Spec)))
+}
+
+// Unrealized provides unrealized P&L of open lots at Mark,
+// zero if position is not marked.
+func (p Position) Unrealized() decimal.Decimal {
+	if p.Mark.IsZero() {
+		return decimal.Zero
+	}
+	return p.Value().Sub(p.Cost())
+}
+
+// book applies trade to position and provides realized P&L.
+func (p *Position) book(t Trade, m Method) decimal.Decimal {
+	realized := t.Commission.Neg()
+	mult := multiplier(p.Spec)
+	qty := t.Qty // remaining quantity of trade
+
+	for !qty.IsZero() && len(p.Lots) > 0 && p.Lots[0].Qty.Sign() != qty.Sign() {
+		i := 0 // FIFO and AverageCost (single lot)
+		if m == LIFO {
+			i = len(p.Lots) - 1
+		}
+		lot := &p.Lots[i]
+
+		// closed quantity with sign of lot
+		closed := lot.Qty
+		if qty.Abs().LessThan(lot.Qty.Abs()) {
+			closed = qty.Neg()
+		}
+		realized = realized.Add(closed.Mul(t.Price.Sub(lot.Price)).Mul(mult))
+		lot.Qty = lot.Qty.Sub(closed)
+		qty = qty.Add(closed)
+
+		if lot.Qty.IsZero() {
+			p.Lots = append(p.Lots[:i], p.Lots[i+1:]...)
+		}
+	}
+
+	if !qty.IsZero() {
+		p.Lots = append(p.Lots, Lot{Date: t.Date, Qty: qty, Price: t.Price})
+		if m == AverageCost && len(p.Lots) > 1 {
+			avg := Lot{Date: t.Date, Qty: p.Qty(), Price: p.AvgPrice()}
+			p.Lots = []Lot{avg}
+		}
+	}
+
+	realized = p.round(realized)
+	p.Realized = p.Realized.Add(realized)
+
+	return realized
+}
+
+// round rounds amount to price precision of instrument.
+func (p Position) round(d decimal.Decimal) decimal.Decimal {
+	return d.Round(int32(p.Spec.PricePrecision()))
+}
+
+func (p Position) copy() Position {
+	p.Lots = append([]Lot{}, p.Lots...)
+	return p
+}
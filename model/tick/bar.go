@@ -0,0 +1,190 @@
+package tick
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+// BarType is method of aggregation of trades into bars.
+type BarType int
+
+// BarType values.
+const (
+	TimeBars   BarType = iota // bar per time interval
+	TickBars                  // bar per number of trades
+	VolumeBars                // bar per traded size
+	DollarBars                // bar per traded value (price * size)
+)
+
+// String implements fmt.Stringer.
+func (t BarType) String() string {
+	switch t {
+	case TimeBars:
+		return "time"
+	case TickBars:
+		return "tick"
+	case VolumeBars:
+		return "volume"
+	case DollarBars:
+		return "dollar"
+	}
+	return fmt.Sprintf("BarType(%d)", t)
+}
+
+// BarOpts are options of Builder.
+type BarOpts struct {
+	Type      BarType
+	Interval  time.Duration   // of TimeBars
+	Threshold decimal.Decimal // number of trades, volume or value of other bar types
+}
+
+// Validate checks if BarOpts are valid.
+func (o BarOpts) Validate() error {
+	switch o.Type {
+	case TimeBars:
+		if o.Interval <= 0 {
+			return errors.New("Interval must be positive")
+		}
+
+	case TickBars, VolumeBars, DollarBars:
+		if !o.Threshold.IsPositive() {
+			return errors.New("Threshold must be positive")
+		}
+		if o.Type == TickBars && !o.Threshold.Equal(o.Threshold.Truncate(0)) {
+			return errors.New("Threshold of tick bars must be integer")
+		}
+
+	default:
+		return fmt.Errorf("invalid Type: %s", o.Type)
+	}
+
+	return nil
+}
+
+// Builder aggregates trades into OHLC bars.
+//
+// Date of time bar is start of its interval (aligned to Unix epoch
+// in UTC); intervals without trades produce no bars. Date of other bar
+// types is time of the first trade. Trades are not split, so bar
+// completed by a trade can exceed Threshold.
+type Builder struct {
+	opts  BarOpts
+	bar   ohlc.OHLCintrad
+	open  bool      // bar has trades
+	end   time.Time // of time bar interval
+	sum   decimal.Decimal
+	last  time.Time // time of the last trade
+	count int64     // of added trades
+}
+
+// NewBuilder creates Builder.
+func NewBuilder(opts BarOpts) (*Builder, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid BarOpts: %v", err)
+	}
+
+	return &Builder{opts: opts}, nil
+}
+
+// Add adds trade t. Completed bar is returned with true value.
+// Trades must be added in time order.
+func (b *Builder) Add(t Trade) (ohlc.OHLCintrad, bool, error) {
+	var (
+		output ohlc.OHLCintrad
+		done   bool
+	)
+
+	if err := t.Validate(); err != nil {
+		return output, false, fmt.Errorf("invalid trade: %v", err)
+	}
+	if b.count > 0 && t.Time.Before(b.last) {
+		return output, false, fmt.Errorf("trade %s is older than the previous one %s",
+			t.Time.Format(TimeFormat), b.last.Format(TimeFormat))
+	}
+	b.last = t.Time
+	b.count++
+
+	if b.opts.Type == TimeBars && b.open && !t.Time.Before(b.end) {
+		output, done = b.Flush()
+	}
+
+	if !b.open {
+		start := t.Time
+		if b.opts.Type == TimeBars {
+			start = t.Time.UTC().Truncate(b.opts.Interval)
+			b.end = start.Add(b.opts.Interval)
+		}
+		b.bar = ohlc.OHLCintrad{
+			Date:   typedef.Date(start),
+			Open:   t.Price,
+			High:   t.Price,
+			Low:    t.Price,
+			Volume: decimal.Zero,
+		}
+		b.sum = decimal.Zero
+		b.open = true
+	}
+
+	if t.Price.GreaterThan(b.bar.High) {
+		b.bar.High = t.Price
+	}
+	if t.Price.LessThan(b.bar.Low) {
+		b.bar.Low = t.Price
+	}
+	b.bar.Close = t.Price
+	b.bar.Volume = b.bar.Volume.Add(t.Size)
+
+	switch b.opts.Type {
+	case TickBars:
+		b.sum = b.sum.Add(decimal.New(1, 0))
+	case VolumeBars:
+		b.sum = b.sum.Add(t.Size)
+	case DollarBars:
+		b.sum = b.sum.Add(t.Value())
+	}
+	if b.opts.Type != TimeBars && !b.sum.LessThan(b.opts.Threshold) {
+		output, done = b.Flush()
+	}
+
+	return output, done, nil
+}
+
+// Flush completes current bar. Bar is returned with true value
+// if there was any trade since the last completed bar.
+func (b *Builder) Flush() (ohlc.OHLCintrad, bool) {
+	if !b.open {
+		return ohlc.OHLCintrad{}, false
+	}
+	b.open = false
+	return b.bar, true
+}
+
+// Bars aggregates trades into bars; the last bar can be incomplete.
+func Bars(trades []Trade, opts BarOpts) ([]ohlc.OHLCintrad, error) {
+	output := []ohlc.OHLCintrad{}
+
+	b, err := NewBuilder(opts)
+	if err != nil {
+		return output, err
+	}
+	for _, t := range trades {
+		bar, ok, err := b.Add(t)
+		if err != nil {
+			return output, err
+		}
+		if ok {
+			output = append(output, bar)
+		}
+	}
+	if bar, ok := b.Flush(); ok {
+		output = append(output, bar)
+	}
+
+	return output, nil
+}
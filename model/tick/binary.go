@@ -0,0 +1,298 @@
+package tick
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Binary format of trades and quotes is compact stream of varints:
+//
+//	header:  magic (4 bytes) version (1 byte)
+//	time:    nanoseconds since the previous record (the first one since Unix epoch)
+//	decimal: exponent, coefficient (must fit int64)
+//	string:  index to table of strings; new string (index == table size)
+//	         is followed by its length and bytes
+//	trade:   time price size exchange(string) conditions(count, strings)
+//	quote:   time bid bid-size ask ask-size exchange(string)
+const binaryVersion = 1
+
+var (
+	tradesMagic = [4]byte{'T', 'R', 'T', 'D'}
+	quotesMagic = [4]byte{'T', 'R', 'Q', 'T'}
+)
+
+// WriteTrades writes []Trade in binary format.
+func WriteTrades(w io.Writer, lst []Trade) error {
+	e := newEncoder(w, tradesMagic)
+	for _, t := range lst {
+		e.time(t.Time)
+		e.decimal(t.Price)
+		e.decimal(t.Size)
+		e.str(t.Exchange)
+		if len(t.Conditions) > maxConditions && e.err == nil {
+			e.err = fmt.Errorf("too many conditions: %d", len(t.Conditions))
+		}
+		e.uvarint(uint64(len(t.Conditions)))
+		for _, c := range t.Conditions {
+			e.str(c)
+		}
+		if e.err != nil {
+			return fmt.Errorf("trade %s: %v", t.Time.Format(TimeFormat), e.err)
+		}
+	}
+
+	return e.flush()
+}
+
+// maxConditions limits number of trade conditions read from input.
+const maxConditions = 1 << 8
+
+// ReadTrades reads []Trade in binary format written by WriteTrades.
+func ReadTrades(r io.Reader) ([]Trade, error) {
+	output := []Trade{}
+
+	d, err := newDecoder(r, tradesMagic)
+	if err != nil {
+		return output, err
+	}
+	for d.more() {
+		t := Trade{}
+		t.Time = d.time()
+		t.Price = d.decimal()
+		t.Size = d.decimal()
+		t.Exchange = d.str()
+		n := d.uvarint()
+		if n > maxConditions {
+			d.setErr(fmt.Errorf("too many conditions: %d", n))
+		}
+		if n > 0 && d.err == nil {
+			t.Conditions = make([]string, 0, n)
+			for i := uint64(0); i < n && d.err == nil; i++ {
+				t.Conditions = append(t.Conditions, d.str())
+			}
+		}
+		if d.err != nil {
+			return output, fmt.Errorf("record %d: %v", len(output)+1, d.err)
+		}
+		output = append(output, t)
+	}
+
+	return output, d.err
+}
+
+// WriteQuotes writes []Quote in binary format.
+func WriteQuotes(w io.Writer, lst []Quote) error {
+	e := newEncoder(w, quotesMagic)
+	for _, q := range lst {
+		e.time(q.Time)
+		e.decimal(q.BidPrice)
+		e.decimal(q.BidSize)
+		e.decimal(q.AskPrice)
+		e.decimal(q.AskSize)
+		e.str(q.Exchange)
+		if e.err != nil {
+			return fmt.Errorf("quote %s: %v", q.Time.Format(TimeFormat), e.err)
+		}
+	}
+
+	return e.flush()
+}
+
+// ReadQuotes reads []Quote in binary format written by WriteQuotes.
+func ReadQuotes(r io.Reader) ([]Quote, error) {
+	output := []Quote{}
+
+	d, err := newDecoder(r, quotesMagic)
+	if err != nil {
+		return output, err
+	}
+	for d.more() {
+		q := Quote{}
+		q.Time = d.time()
+		q.BidPrice = d.decimal()
+		q.BidSize = d.decimal()
+		q.AskPrice = d.decimal()
+		q.AskSize = d.decimal()
+		q.Exchange = d.str()
+		if d.err != nil {
+			return output, fmt.Errorf("record %d: %v", len(output)+1, d.err)
+		}
+		output = append(output, q)
+	}
+
+	return output, d.err
+}
+
+// encoder writes values of binary format; the first error is kept in err.
+type encoder struct {
+	w       *bufio.Writer
+	buf     [binary.MaxVarintLen64]byte
+	last    int64 // time of the previous record
+	strings map[string]uint64
+	err     error
+}
+
+func newEncoder(w io.Writer, magic [4]byte) *encoder {
+	e := &encoder{w: bufio.NewWriter(w), strings: map[string]uint64{}}
+	e.w.Write(magic[:])
+	e.w.WriteByte(binaryVersion)
+	return e
+}
+
+func (e *encoder) varint(x int64) {
+	n := binary.PutVarint(e.buf[:], x)
+	e.w.Write(e.buf[:n])
+}
+
+func (e *encoder) uvarint(x uint64) {
+	n := binary.PutUvarint(e.buf[:], x)
+	e.w.Write(e.buf[:n])
+}
+
+func (e *encoder) time(t time.Time) {
+	ns := t.UnixNano()
+	e.varint(ns - e.last)
+	e.last = ns
+}
+
+func (e *encoder) decimal(d decimal.Decimal) {
+	c := d.Coefficient()
+	if !c.IsInt64() {
+		if e.err == nil {
+			e.err = fmt.Errorf("number %s is too big", d)
+		}
+		return
+	}
+	e.varint(int64(d.Exponent()))
+	e.varint(c.Int64())
+}
+
+func (e *encoder) str(s string) {
+	if i, ok := e.strings[s]; ok {
+		e.uvarint(i)
+		return
+	}
+	i := uint64(len(e.strings))
+	e.strings[s] = i
+	e.uvarint(i)
+	e.uvarint(uint64(len(s)))
+	e.w.WriteString(s)
+}
+
+func (e *encoder) flush() error {
+	if err := e.w.Flush(); err != nil {
+		return fmt.Errorf("write error: %v", err)
+	}
+	return nil
+}
+
+// decoder reads values of binary format; the first error is kept in err.
+type decoder struct {
+	r       *bufio.Reader
+	last    int64 // time of the previous record
+	strings []string
+	err     error
+}
+
+func newDecoder(r io.Reader, magic [4]byte) (*decoder, error) {
+	d := &decoder{r: bufio.NewReader(r)}
+
+	header := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(d.r, header); err != nil {
+		return d, fmt.Errorf("invalid header: %v", err)
+	}
+	switch {
+	case string(header[:len(magic)]) != string(magic[:]):
+		return d, fmt.Errorf("invalid header: unexpected magic %q", header[:len(magic)])
+	case header[len(magic)] != binaryVersion:
+		return d, fmt.Errorf("unsupported version: %d", header[len(magic)])
+	}
+
+	return d, nil
+}
+
+// more reports if there is next record.
+func (d *decoder) more() bool {
+	if d.err != nil {
+		return false
+	}
+	_, err := d.r.Peek(1)
+	if err != nil && err != io.EOF {
+		d.err = err
+	}
+	return err == nil
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	x, err := binary.ReadVarint(d.r)
+	d.setErr(err)
+	return x
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	x, err := binary.ReadUvarint(d.r)
+	d.setErr(err)
+	return x
+}
+
+func (d *decoder) setErr(err error) {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if d.err == nil {
+		d.err = err
+	}
+}
+
+func (d *decoder) time() time.Time {
+	d.last += d.varint()
+	return time.Unix(0, d.last).UTC()
+}
+
+func (d *decoder) decimal() decimal.Decimal {
+	exp := d.varint()
+	c := d.varint()
+	if exp < -1<<31 || exp > 1<<31-1 {
+		d.setErr(fmt.Errorf("invalid exponent: %d", exp))
+	}
+	return decimal.New(c, int32(exp))
+}
+
+func (d *decoder) str() string {
+	i := d.uvarint()
+	switch {
+	case d.err != nil:
+		return ""
+	case i < uint64(len(d.strings)):
+		return d.strings[i]
+	case i > uint64(len(d.strings)):
+		d.setErr(fmt.Errorf("invalid string index: %d", i))
+		return ""
+	}
+
+	n := d.uvarint()
+	if n > 1<<16 {
+		d.setErr(errors.New("string is too long"))
+	}
+	if d.err != nil {
+		return ""
+	}
+	b := make([]byte, n)
+	_, err := io.ReadFull(d.r, b)
+	d.setErr(err)
+	s := string(b)
+	d.strings = append(d.strings, s)
+	return s
+}
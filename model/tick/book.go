@@ -0,0 +1,111 @@
+package tick
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Level is aggregated price level of order book.
+type Level struct {
+	Price decimal.Decimal
+	Size  decimal.Decimal
+}
+
+// Book is order book snapshot. Bids are sorted by price descending,
+// Asks ascending i.e. the best price is the first one.
+type Book struct {
+	Time     time.Time
+	Seq      int64 // sequence number of snapshot if provided by venue
+	Exchange string
+	Bids     []Level
+	Asks     []Level
+}
+
+// Top returns snapshot of the top n levels of both sides.
+func (b *Book) Top(n int) Book {
+	output := *b
+	if len(output.Bids) > n {
+		output.Bids = output.Bids[:n]
+	}
+	if len(output.Asks) > n {
+		output.Asks = output.Asks[:n]
+	}
+	return output
+}
+
+// Quote returns top of book quote.
+func (b *Book) Quote() Quote {
+	q := Quote{Time: b.Time, Exchange: b.Exchange}
+	if len(b.Bids) > 0 {
+		q.BidPrice, q.BidSize = b.Bids[0].Price, b.Bids[0].Size
+	}
+	if len(b.Asks) > 0 {
+		q.AskPrice, q.AskSize = b.Asks[0].Price, b.Asks[0].Size
+	}
+	return q
+}
+
+// Depth returns total size and value (price * size) of levels
+// within distance pct (percent) from mid price; bid and ask side.
+func (b *Book) Depth(pct decimal.Decimal) (bidSize, askSize, bidValue, askValue decimal.Decimal) {
+	q := b.Quote()
+	mid := q.Mid()
+	if mid.IsZero() {
+		return
+	}
+	dist := mid.Mul(pct).Div(decimal.New(100, 0))
+
+	for _, l := range b.Bids {
+		if l.Price.LessThan(mid.Sub(dist)) {
+			break
+		}
+		bidSize = bidSize.Add(l.Size)
+		bidValue = bidValue.Add(l.Price.Mul(l.Size))
+	}
+	for _, l := range b.Asks {
+		if l.Price.GreaterThan(mid.Add(dist)) {
+			break
+		}
+		askSize = askSize.Add(l.Size)
+		askValue = askValue.Add(l.Price.Mul(l.Size))
+	}
+	return
+}
+
+// Validate checks correctness of Book.
+func (b *Book) Validate() error {
+	if b.Time.IsZero() {
+		return fmt.Errorf("Time is not set")
+	}
+
+	sides := []struct {
+		name   string
+		levels []Level
+		desc   bool
+	}{
+		{"Bids", b.Bids, true},
+		{"Asks", b.Asks, false},
+	}
+	for _, s := range sides {
+		for i, l := range s.levels {
+			switch {
+			case !l.Price.IsPositive():
+				return fmt.Errorf("%s[%d]: price %s is not positive", s.name, i, l.Price)
+
+			case l.Size.IsNegative():
+				return fmt.Errorf("%s[%d]: size %s is less than zero", s.name, i, l.Size)
+
+			case i > 0 && s.desc && !l.Price.LessThan(s.levels[i-1].Price):
+				return fmt.Errorf("%s[%d]: price %s is not less than previous", s.name, i, l.Price)
+
+			case i > 0 && !s.desc && !l.Price.GreaterThan(s.levels[i-1].Price):
+				return fmt.Errorf("%s[%d]: price %s is not greater than previous", s.name, i, l.Price)
+			}
+		}
+	}
+
+	q := b.Quote()
+	return q.Validate()
+}
@@ -0,0 +1,226 @@
+package tick
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TimeFormat is format of time in CSV - RFC 3339 with nanoseconds in UTC.
+const TimeFormat = time.RFC3339Nano
+
+// TradesToCSV exports []Trade to CSV.
+// Conditions are exported as comma delimited list.
+func TradesToCSV(w io.Writer, lst []Trade) error {
+	data := make([][]string, 0, len(lst)+1)
+	// CSV output header
+	data = append(data, []string{"time", "price", "size", "exchange", "conditions"})
+	for _, t := range lst {
+		data = append(data, []string{
+			t.Time.UTC().Format(TimeFormat),
+			t.Price.String(),
+			t.Size.String(),
+			t.Exchange,
+			strings.Join(t.Conditions, ","),
+		})
+	}
+
+	return writeCSV(w, data)
+}
+
+// TradesFromCSV imports []Trade from CSV exported by TradesToCSV.
+// Columns exchange and conditions are optional.
+func TradesFromCSV(r io.Reader) ([]Trade, error) {
+	output := []Trade{}
+
+	data, err := readCSV(r)
+	if err != nil || len(data) == 0 {
+		return output, err
+	}
+
+	for i, row := range data[1:] { // skip CSV header
+		if len(row) < 3 {
+			return output, fmt.Errorf("row %d: expected 3 columns (time;price;size), got %d", i+2, len(row))
+		}
+
+		t := Trade{}
+		if t.Time, err = time.Parse(TimeFormat, row[0]); err != nil {
+			return output, fmt.Errorf("row %d: invalid time: %v", i+2, err)
+		}
+		if err := parseDecimals(row[1:3], &t.Price, &t.Size); err != nil {
+			return output, fmt.Errorf("row %d: %v", i+2, err)
+		}
+		if len(row) > 3 {
+			t.Exchange = row[3]
+		}
+		if len(row) > 4 && row[4] != "" {
+			t.Conditions = strings.Split(row[4], ",")
+		}
+		if err := t.Validate(); err != nil {
+			return output, fmt.Errorf("row %d: %v", i+2, err)
+		}
+
+		output = append(output, t)
+	}
+
+	return output, nil
+}
+
+// QuotesToCSV exports []Quote to CSV.
+func QuotesToCSV(w io.Writer, lst []Quote) error {
+	data := make([][]string, 0, len(lst)+1)
+	// CSV output header
+	data = append(data, []string{"time", "bid", "bid-size", "ask", "ask-size", "exchange"})
+	for _, q := range lst {
+		data = append(data, []string{
+			q.Time.UTC().Format(TimeFormat),
+			q.BidPrice.String(),
+			q.BidSize.String(),
+			q.AskPrice.String(),
+			q.AskSize.String(),
+			q.Exchange,
+		})
+	}
+
+	return writeCSV(w, data)
+}
+
+// QuotesFromCSV imports []Quote from CSV exported by QuotesToCSV.
+// Column exchange is optional.
+func QuotesFromCSV(r io.Reader) ([]Quote, error) {
+	output := []Quote{}
+
+	data, err := readCSV(r)
+	if err != nil || len(data) == 0 {
+		return output, err
+	}
+
+	for i, row := range data[1:] { // skip CSV header
+		if len(row) < 5 {
+			return output, fmt.Errorf("row %d: expected 5 columns (time;bid;bid-size;ask;ask-size), got %d",
+				i+2, len(row))
+		}
+
+		q := Quote{}
+		if q.Time, err = time.Parse(TimeFormat, row[0]); err != nil {
+			return output, fmt.Errorf("row %d: invalid time: %v", i+2, err)
+		}
+		if err := parseDecimals(row[1:5], &q.BidPrice, &q.BidSize, &q.AskPrice, &q.AskSize); err != nil {
+			return output, fmt.Errorf("row %d: %v", i+2, err)
+		}
+		if len(row) > 5 {
+			q.Exchange = row[5]
+		}
+		if err := q.Validate(); err != nil {
+			return output, fmt.Errorf("row %d: %v", i+2, err)
+		}
+
+		output = append(output, q)
+	}
+
+	return output, nil
+}
+
+// BookToCSV exports Book to CSV, one row per level:
+// bids from the best one followed by asks from the best one.
+func BookToCSV(w io.Writer, b Book) error {
+	data := make([][]string, 0, len(b.Bids)+len(b.Asks)+1)
+	// CSV output header
+	data = append(data, []string{"time", "seq", "exchange", "side", "price", "size"})
+	t := b.Time.UTC().Format(TimeFormat)
+	seq := strconv.FormatInt(b.Seq, 10)
+	for _, l := range b.Bids {
+		data = append(data, []string{t, seq, b.Exchange, "bid", l.Price.String(), l.Size.String()})
+	}
+	for _, l := range b.Asks {
+		data = append(data, []string{t, seq, b.Exchange, "ask", l.Price.String(), l.Size.String()})
+	}
+
+	return writeCSV(w, data)
+}
+
+// BookFromCSV imports Book from CSV exported by BookToCSV.
+func BookFromCSV(r io.Reader) (Book, error) {
+	output := Book{}
+
+	data, err := readCSV(r)
+	if err != nil || len(data) == 0 {
+		return output, err
+	}
+
+	for i, row := range data[1:] { // skip CSV header
+		if len(row) < 6 {
+			return output, fmt.Errorf("row %d: expected 6 columns (time;seq;exchange;side;price;size), got %d",
+				i+2, len(row))
+		}
+		if i == 0 {
+			if output.Time, err = time.Parse(TimeFormat, row[0]); err != nil {
+				return output, fmt.Errorf("row %d: invalid time: %v", i+2, err)
+			}
+			if output.Seq, err = strconv.ParseInt(row[1], 10, 64); err != nil {
+				return output, fmt.Errorf("row %d: invalid seq: %v", i+2, err)
+			}
+			output.Exchange = row[2]
+		}
+
+		l := Level{}
+		if err := parseDecimals(row[4:6], &l.Price, &l.Size); err != nil {
+			return output, fmt.Errorf("row %d: %v", i+2, err)
+		}
+		switch row[3] {
+		case "bid":
+			output.Bids = append(output.Bids, l)
+		case "ask":
+			output.Asks = append(output.Asks, l)
+		default:
+			return output, fmt.Errorf("row %d: invalid side %q", i+2, row[3])
+		}
+	}
+
+	if err := output.Validate(); err != nil {
+		return output, fmt.Errorf("invalid book: %v", err)
+	}
+
+	return output, nil
+}
+
+func writeCSV(w io.Writer, data [][]string) error {
+	wcsv := csv.NewWriter(w)
+	wcsv.Comma = ';'
+	wcsv.WriteAll(data)
+	if wcsv.Error() != nil {
+		return fmt.Errorf("CSV write error: %v", wcsv.Error())
+	}
+
+	return nil
+}
+
+func readCSV(r io.Reader) ([][]string, error) {
+	rcsv := csv.NewReader(r)
+	rcsv.Comma = ';'
+	rcsv.FieldsPerRecord = -1 // optional columns
+	data, err := rcsv.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("CSV read error: %v", err)
+	}
+
+	return data, nil
+}
+
+// parseDecimals parses values of lst to dst.
+func parseDecimals(lst []string, dst ...*decimal.Decimal) error {
+	for i, s := range lst {
+		d, err := decimal.NewFromString(s)
+		if err != nil {
+			return fmt.Errorf("invalid number %q: %v", s, err)
+		}
+		*dst[i] = d
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+// Package tick provides tick data - trades and top of book quotes,
+// their CSV and binary serialization and aggregation of trades
+// into OHLC bars (see Builder).
+package tick
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Trade represents executed trade (time and sales record).
+type Trade struct {
+	Time       time.Time
+	Price      decimal.Decimal
+	Size       decimal.Decimal
+	Exchange   string   // venue code e.g. XNAS; optional
+	Conditions []string // venue specific sale conditions e.g. "@", "T"; optional
+}
+
+// Value returns traded value - Price * Size.
+func (t *Trade) Value() decimal.Decimal {
+	return t.Price.Mul(t.Size)
+}
+
+// Validate checks correctness of Trade.
+func (t *Trade) Validate() error {
+	switch {
+	case t.Time.IsZero():
+		return fmt.Errorf("Time is not set")
+
+	case !t.Price.IsPositive():
+		return fmt.Errorf("Price: %s is not positive", t.Price)
+
+	case t.Size.IsNegative():
+		return fmt.Errorf("Size: %s is less than zero", t.Size)
+	}
+	return nil
+}
+
+// Quote represents top of book quote. Zero price and size means
+// empty side of the book.
+type Quote struct {
+	Time     time.Time
+	BidPrice decimal.Decimal
+	BidSize  decimal.Decimal
+	AskPrice decimal.Decimal
+	AskSize  decimal.Decimal
+	Exchange string // venue code; optional
+}
+
+// Mid returns average of bid and ask price or zero if a side is empty.
+func (q *Quote) Mid() decimal.Decimal {
+	if q.BidPrice.IsZero() || q.AskPrice.IsZero() {
+		return decimal.Zero
+	}
+	return q.BidPrice.Add(q.AskPrice).Div(decimal.New(2, 0))
+}
+
+// Spread returns difference of ask and bid price or zero if a side is empty.
+func (q *Quote) Spread() decimal.Decimal {
+	if q.BidPrice.IsZero() || q.AskPrice.IsZero() {
+		return decimal.Zero
+	}
+	return q.AskPrice.Sub(q.BidPrice)
+}
+
+// Validate checks correctness of Quote.
+func (q *Quote) Validate() error {
+	switch {
+	case q.Time.IsZero():
+		return fmt.Errorf("Time is not set")
+
+	case q.BidPrice.IsNegative():
+		return fmt.Errorf("BidPrice: %s is less than zero", q.BidPrice)
+
+	case q.BidSize.IsNegative():
+		return fmt.Errorf("BidSize: %s is less than zero", q.BidSize)
+
+	case q.AskPrice.IsNegative():
+		return fmt.Errorf("AskPrice: %s is less than zero", q.AskPrice)
+
+	case q.AskSize.IsNegative():
+		return fmt.Errorf("AskSize: %s is less than zero", q.AskSize)
+
+	case q.AskPrice.IsPositive() && q.BidPrice.GreaterThan(q.AskPrice):
+		return fmt.Errorf("BidPrice: %s is greater than AskPrice: %s", q.BidPrice, q.AskPrice)
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+package tick
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var t0 = time.Date(2020, 3, 2, 14, 30, 0, 0, time.UTC)
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+// mkTrades creates trades one second apart from "price size" pairs.
+func mkTrades(items ...string) []Trade {
+	output := []Trade{}
+	for i, item := range items {
+		f := strings.Fields(item)
+		output = append(output, Trade{
+			Time:  t0.Add(time.Duration(i) * time.Second),
+			Price: dec(f[0]),
+			Size:  dec(f[1]),
+		})
+	}
+	return output
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		label  string
+		err    error
+		hasErr bool
+	}{
+		{"trade", (&Trade{Time: t0, Price: dec("1.5"), Size: dec("10")}).Validate(), false},
+		{"trade without time", (&Trade{Price: dec("1.5")}).Validate(), true},
+		{"trade zero price", (&Trade{Time: t0}).Validate(), true},
+		{"trade negative size", (&Trade{Time: t0, Price: dec("1"), Size: dec("-1")}).Validate(), true},
+		{"quote", (&Quote{Time: t0, BidPrice: dec("1.5"), AskPrice: dec("1.6")}).Validate(), false},
+		{"quote empty ask", (&Quote{Time: t0, BidPrice: dec("1.5")}).Validate(), false},
+		{"quote crossed", (&Quote{Time: t0, BidPrice: dec("1.7"), AskPrice: dec("1.6")}).Validate(), true},
+		{"quote negative size", (&Quote{Time: t0, BidSize: dec("-1")}).Validate(), true},
+	}
+
+	for _, tt := range tests {
+		switch {
+		case tt.hasErr && tt.err == nil:
+			t.Errorf("%s - should have an error", tt.label)
+		case !tt.hasErr && tt.err != nil:
+			t.Errorf("%s - unexpected error: %v", tt.label, tt.err)
+		}
+	}
+
+	q := Quote{Time: t0, BidPrice: dec("1.5"), AskPrice: dec("1.6")}
+	if !q.Mid().Equal(dec("1.55")) || !q.Spread().Equal(dec("0.1")) {
+		t.Errorf("unexpected Mid %s or Spread %s", q.Mid(), q.Spread())
+	}
+}
+
+var (
+	trades = []Trade{
+		{Time: t0, Price: dec("101.25"), Size: dec("100"), Exchange: "XNAS", Conditions: []string{"@", "T"}},
+		{Time: t0.Add(1500 * time.Microsecond), Price: dec("101.26"), Size: dec("0.5"), Exchange: "XNYS"},
+		{Time: t0.Add(time.Second), Price: dec("101.2"), Size: dec("200"), Exchange: "XNAS", Conditions: []string{"T"}},
+	}
+	quotes = []Quote{
+		{Time: t0, BidPrice: dec("101.24"), BidSize: dec("300"), AskPrice: dec("101.26"), AskSize: dec("100"), Exchange: "XNAS"},
+		{Time: t0.Add(time.Millisecond), BidPrice: dec("101.25"), BidSize: dec("100"), AskPrice: dec("101.26"), AskSize: dec("200")},
+	}
+)
+
+// equal compares Trade or Quote values; decimals by value.
+func equal(a, b interface{}) bool {
+	switch x := a.(type) {
+	case Trade:
+		y := b.(Trade)
+		return x.Time.Equal(y.Time) && x.Price.Equal(y.Price) && x.Size.Equal(y.Size) &&
+			x.Exchange == y.Exchange && reflect.DeepEqual(x.Conditions, y.Conditions)
+	case Quote:
+		y := b.(Quote)
+		return x.Time.Equal(y.Time) && x.BidPrice.Equal(y.BidPrice) && x.BidSize.Equal(y.BidSize) &&
+			x.AskPrice.Equal(y.AskPrice) && x.AskSize.Equal(y.AskSize) && x.Exchange == y.Exchange
+	}
+	return false
+}
+
+func TestCSV(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := TradesToCSV(buf, trades); err != nil {
+		t.Fatal(err)
+	}
+	gotTrades, err := TradesFromCSV(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gotTrades) != len(trades) {
+		t.Fatalf("expected %d trades, got %d", len(trades), len(gotTrades))
+	}
+	for i := range trades {
+		if !equal(trades[i], gotTrades[i]) {
+			t.Errorf("expected %+v, got %+v", trades[i], gotTrades[i])
+		}
+	}
+
+	buf.Reset()
+	if err := QuotesToCSV(buf, quotes); err != nil {
+		t.Fatal(err)
+	}
+	gotQuotes, err := QuotesFromCSV(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gotQuotes) != len(quotes) {
+		t.Fatalf("expected %d quotes, got %d", len(quotes), len(gotQuotes))
+	}
+	for i := range quotes {
+		if !equal(quotes[i], gotQuotes[i]) {
+			t.Errorf("expected %+v, got %+v", quotes[i], gotQuotes[i])
+		}
+	}
+
+	tests := []struct {
+		label  string
+		input  string
+		cnt    int
+		hasErr bool
+	}{
+		{"empty", "", 0, false},
+		{"minimal", "time;price;size\n2020-03-02T14:30:00Z;1.5;10\n", 1, false},
+		{"missing column", "time;price\n2020-03-02T14:30:00Z;1.5\n", 0, true},
+		{"invalid time", "time;price;size\n2020-03-02;1.5;10\n", 0, true},
+		{"invalid price", "time;price;size\n2020-03-02T14:30:00Z;x;10\n", 0, true},
+		{"invalid trade", "time;price;size\n2020-03-02T14:30:00Z;0;10\n", 0, true},
+	}
+	for _, tt := range tests {
+		lst, err := TradesFromCSV(strings.NewReader(tt.input))
+		switch {
+		case tt.hasErr && err == nil:
+			t.Errorf("%s - should have an error", tt.label)
+		case !tt.hasErr && err != nil:
+			t.Errorf("%s - unexpected error: %v", tt.label, err)
+		case !tt.hasErr && len(lst) != tt.cnt:
+			t.Errorf("%s - expected %d trades, got %d", tt.label, tt.cnt, len(lst))
+		}
+	}
+}
+
+func TestBinary(t *testing.T) {
+	buf := &bytes.Buffer{}
+	if err := WriteTrades(buf, trades); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	csvBuf := &bytes.Buffer{}
+	TradesToCSV(csvBuf, trades)
+	if len(data) >= csvBuf.Len()/2 {
+		t.Errorf("binary format should be compact: %d bytes, CSV %d bytes", len(data), csvBuf.Len())
+	}
+
+	gotTrades, err := ReadTrades(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gotTrades) != len(trades) {
+		t.Fatalf("expected %d trades, got %d", len(trades), len(gotTrades))
+	}
+	for i := range trades {
+		if !equal(trades[i], gotTrades[i]) {
+			t.Errorf("expected %+v, got %+v", trades[i], gotTrades[i])
+		}
+	}
+
+	if _, err := ReadTrades(bytes.NewReader(data[:len(data)-1])); err == nil {
+		t.Errorf("truncated data - should have an error")
+	}
+	if _, err := ReadQuotes(bytes.NewReader(data)); err == nil {
+		t.Errorf("trades read as quotes - should have an error")
+	}
+	if _, err := ReadTrades(bytes.NewReader(nil)); err == nil {
+		t.Errorf("empty data - should have an error")
+	}
+	for i := len(tradesMagic) + 1; i < len(data); i++ {
+		ReadTrades(bytes.NewReader(data[:i])) // truncated data - no panic
+	}
+
+	// corrupted conditions count
+	corrupted := &bytes.Buffer{}
+	e := newEncoder(corrupted, tradesMagic)
+	e.time(t0)
+	e.decimal(dec("1"))
+	e.decimal(dec("1"))
+	e.str("")
+	e.uvarint(1 << 62)
+	if err := e.flush(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadTrades(corrupted); err == nil {
+		t.Errorf("corrupted conditions count - should have an error")
+	}
+	many := []Trade{{Time: t0, Price: dec("1"), Size: dec("1"), Conditions: make([]string, maxConditions+1)}}
+	if err := WriteTrades(&bytes.Buffer{}, many); err == nil {
+		t.Errorf("too many conditions - should have an error")
+	}
+	big := []Trade{{Time: t0, Price: dec("1e30").Add(dec("0.1")), Size: dec("1")}}
+	if err := WriteTrades(&bytes.Buffer{}, big); err == nil {
+		t.Errorf("too big number - should have an error")
+	}
+
+	buf.Reset()
+	if err := WriteQuotes(buf, quotes); err != nil {
+		t.Fatal(err)
+	}
+	gotQuotes, err := ReadQuotes(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gotQuotes) != len(quotes) {
+		t.Fatalf("expected %d quotes, got %d", len(quotes), len(gotQuotes))
+	}
+	for i := range quotes {
+		if !equal(quotes[i], gotQuotes[i]) {
+			t.Errorf("expected %+v, got %+v", quotes[i], gotQuotes[i])
+		}
+	}
+}
+
+func TestBars(t *testing.T) {
+	input := mkTrades("10 100", "12 50", "9 200", "11 100", "10.5 300", "10 10")
+
+	tests := []struct {
+		label  string
+		opts   BarOpts
+		output []string // open high low close volume
+		hasErr bool
+	}{
+		{"time", BarOpts{Type: TimeBars, Interval: 2 * time.Second},
+			[]string{"10 12 10 12 150", "9 11 9 11 300", "10.5 10.5 10 10 310"}, false},
+		{"tick", BarOpts{Type: TickBars, Threshold: dec("4")},
+			[]string{"10 12 9 11 450", "10.5 10.5 10 10 310"}, false},
+		{"volume", BarOpts{Type: VolumeBars, Threshold: dec("300")},
+			[]string{"10 12 9 9 350", "11 11 10.5 10.5 400", "10 10 10 10 10"}, false},
+		{"dollar", BarOpts{Type: DollarBars, Threshold: dec("2000")},
+			[]string{"10 12 9 9 350", "11 11 10.5 10.5 400", "10 10 10 10 10"}, false},
+		{"invalid interval", BarOpts{Type: TimeBars}, nil, true},
+		{"invalid threshold", BarOpts{Type: VolumeBars}, nil, true},
+		{"fractional ticks", BarOpts{Type: TickBars, Threshold: dec("1.5")}, nil, true},
+		{"invalid type", BarOpts{Type: BarType(9), Threshold: dec("1")}, nil, true},
+	}
+
+	for _, tt := range tests {
+		bars, err := Bars(input, tt.opts)
+		switch {
+		case tt.hasErr && err == nil:
+			t.Errorf("%s - should have an error", tt.label)
+			continue
+		case !tt.hasErr && err != nil:
+			t.Errorf("%s - unexpected error: %v", tt.label, err)
+			continue
+		case tt.hasErr:
+			continue
+		}
+
+		got := []string{}
+		for _, b := range bars {
+			got = append(got, strings.Join([]string{
+				b.Open.String(), b.High.String(), b.Low.String(), b.Close.String(), b.Volume.String()}, " "))
+		}
+		if !reflect.DeepEqual(got, tt.output) {
+			t.Errorf("%s - expected %v, got %v", tt.label, tt.output, got)
+		}
+	}
+
+	bars, _ := Bars(input, BarOpts{Type: TimeBars, Interval: 2 * time.Second})
+	if !bars[1].Date.Time().Equal(t0.Add(2 * time.Second)) {
+		t.Errorf("time bar should start at interval start, got %s", bars[1].Date.Time())
+	}
+
+	b, _ := NewBuilder(BarOpts{Type: TickBars, Threshold: dec("2")})
+	b.Add(input[1])
+	if _, _, err := b.Add(input[0]); err == nil {
+		t.Errorf("older trade - should have an error")
+	}
+}
+
+func TestBook(t *testing.T) {
+	b := Book{
+		Time:     t0,
+		Seq:      42,
+		Exchange: "kraken",
+		Bids:     []Level{{dec("99.5"), dec("1")}, {dec("99"), dec("2")}, {dec("97"), dec("10")}},
+		Asks:     []Level{{dec("100.5"), dec("0.5")}, {dec("101"), dec("3")}, {dec("103"), dec("10")}},
+	}
+	if err := b.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	q := b.Quote()
+	if !q.Mid().Equal(dec("100")) || !q.Spread().Equal(dec("1")) || !q.AskSize.Equal(dec("0.5")) {
+		t.Errorf("unexpected Quote: %+v", q)
+	}
+	bidSize, askSize, bidValue, askValue := b.Depth(dec("1"))
+	switch {
+	case !bidSize.Equal(dec("3")) || !askSize.Equal(dec("3.5")):
+		t.Errorf("unexpected depth size: %s %s", bidSize, askSize)
+	case !bidValue.Equal(dec("297.5")) || !askValue.Equal(dec("353.25")):
+		t.Errorf("unexpected depth value: %s %s", bidValue, askValue)
+	}
+	if top := b.Top(2); len(top.Bids) != 2 || len(top.Asks) != 2 || len(b.Bids) != 3 {
+		t.Errorf("unexpected Top: %+v", top)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := BookToCSV(buf, b); err != nil {
+		t.Fatal(err)
+	}
+	got, err := BookFromCSV(buf)
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case !got.Time.Equal(b.Time) || got.Seq != 42 || got.Exchange != "kraken" ||
+		len(got.Bids) != 3 || len(got.Asks) != 3 || !got.Asks[2].Price.Equal(dec("103")):
+		t.Errorf("unexpected book: %+v", got)
+	}
+
+	tests := []struct {
+		label string
+		book  Book
+	}{
+		{"without time", Book{Bids: b.Bids}},
+		{"unsorted bids", Book{Time: t0, Bids: []Level{b.Bids[1], b.Bids[0]}}},
+		{"unsorted asks", Book{Time: t0, Asks: []Level{b.Asks[1], b.Asks[0]}}},
+		{"zero price", Book{Time: t0, Asks: []Level{{Size: dec("1")}}}},
+		{"crossed", Book{Time: t0, Bids: b.Asks[1:2], Asks: b.Bids[:1]}},
+	}
+	for _, tt := range tests {
+		if tt.book.Validate() == nil {
+			t.Errorf("%s - should have an error", tt.label)
+		}
+	}
+}
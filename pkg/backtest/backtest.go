@@ -0,0 +1,172 @@
+// Package backtest replays market data bar by bar through a Strategy
+// and simulates order execution by a broker.
+//
+// Series are replayed in date order; bars of the same date are replayed
+// in order of Series passed to Run. Results are deterministic:
+// cash and prices are decimal.Decimal and no randomness is involved.
+// All Series are expected to be quoted in the same currency as cash.
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+// Series is market data of an instrument.
+type Series struct {
+	Spec instrument.Spec
+	Data ohlc.Vec
+}
+
+// Bar is OHLC bar of an instrument.
+type Bar struct {
+	Symbol string
+	ohlc.OHLC
+}
+
+// Strategy reacts to market data and fills.
+type Strategy interface {
+	// OnBar is called for each bar after pending orders were processed.
+	OnBar(b *Broker, bar Bar)
+	// OnFill is called for each (partial or complete) order fill.
+	OnFill(b *Broker, f Fill)
+}
+
+// FillAt defines when market orders are filled.
+type FillAt int
+
+// FillAt values.
+const (
+	FillNextOpen FillAt = iota // at open of the next bar
+	FillClose                  // at close of the current bar
+)
+
+// String implements fmt.Stringer.
+func (f FillAt) String() string {
+	switch f {
+	case FillNextOpen:
+		return "next-open"
+	case FillClose:
+		return "close"
+	}
+	return fmt.Sprintf("FillAt(%d)", int(f))
+}
+
+// Config is setup of backtest.
+type Config struct {
+	Cash       decimal.Decimal // initial cash
+	FillAt     FillAt
+	Commission Commission // none if nil
+	Slippage   Slippage   // none if nil
+	AllowShort bool
+}
+
+// Validate checks if Config is valid.
+func (c Config) Validate() error {
+	switch {
+	case c.Cash.IsNegative():
+		return fmt.Errorf("Cash: %s is less than zero", c.Cash)
+
+	case c.FillAt != FillNextOpen && c.FillAt != FillClose:
+		return fmt.Errorf("FillAt: invalid value %s", c.FillAt)
+	}
+
+	return nil
+}
+
+// Point is point of equity curve.
+type Point struct {
+	Date   typedef.Date
+	Cash   decimal.Decimal
+	Equity decimal.Decimal // cash and positions marked to the last close
+}
+
+// Result is outcome of backtest.
+type Result struct {
+	Orders    []Order
+	Fills     []Fill
+	Equity    []Point // one point per replayed date
+	Positions map[string]decimal.Decimal
+	Cash      decimal.Decimal
+}
+
+// Run replays series through strategy s.
+func Run(cfg Config, s Strategy, series ...Series) (Result, error) {
+	if err := cfg.Validate(); err != nil {
+		return Result{}, fmt.Errorf("config: %v", err)
+	}
+	if len(series) == 0 {
+		return Result{}, errors.New("no series")
+	}
+
+	b := newBroker(cfg)
+	for _, sr := range series {
+		if err := sr.Spec.Validate(); err != nil {
+			return Result{}, err
+		}
+		if _, ok := b.feeds[sr.Spec.Symbol]; ok {
+			return Result{}, fmt.Errorf("duplicate series %s", sr.Spec.Symbol)
+		}
+		f := &feed{spec: sr.Spec, data: sr.Data.Data(), idx: -1}
+		b.feeds[f.spec.Symbol] = f
+		b.order = append(b.order, f)
+	}
+
+	for _, d := range b.dates() {
+		b.date = d
+		for _, f := range b.order {
+			if f.idx+1 >= len(f.data) || f.data[f.idx+1].Date != d {
+				continue
+			}
+			f.idx++
+			bar := Bar{Symbol: f.spec.Symbol, OHLC: f.data[f.idx]}
+
+			b.execute(s, f, bar, false)
+			s.OnBar(b, bar)
+			if cfg.FillAt == FillClose {
+				b.execute(s, f, bar, true)
+			}
+		}
+		b.equity = append(b.equity, Point{Date: d, Cash: b.cash, Equity: b.Equity()})
+	}
+
+	res := Result{
+		Orders:    b.Orders(),
+		Fills:     append([]Fill{}, b.fills...),
+		Equity:    b.equity,
+		Positions: map[string]decimal.Decimal{},
+		Cash:      b.cash,
+	}
+	for sym, qty := range b.positions {
+		if !qty.IsZero() {
+			res.Positions[sym] = qty
+		}
+	}
+
+	return res, nil
+}
+
+// dates provides sorted union of dates of all series.
+func (b *Broker) dates() []typedef.Date {
+	set := map[typedef.Date]bool{}
+	for _, f := range b.order {
+		for _, bar := range f.data {
+			set[bar.Date] = true
+		}
+	}
+
+	output := make([]typedef.Date, 0, len(set))
+	for d := range set {
+		output = append(output, d)
+	}
+	sort.Slice(output, func(i, j int) bool { return output[i].Time().Before(output[j].Time()) })
+
+	return output
+}
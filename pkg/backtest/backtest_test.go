@@ -0,0 +1,280 @@
+package backtest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+// mkSeries creates Series from rows "date open high low close".
+func mkSeries(t *testing.T, sym string, rows ...string) Series {
+	lst := []ohlc.OHLC{}
+	for _, r := range rows {
+		f := strings.Fields(r)
+		d, err := typedef.DateFromStr(f[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		lst = append(lst, ohlc.OHLC{
+			Date:   d,
+			Open:   decimal.RequireFromString(f[1]),
+			High:   decimal.RequireFromString(f[2]),
+			Low:    decimal.RequireFromString(f[3]),
+			Close:  decimal.RequireFromString(f[4]),
+			Volume: decimal.New(1000, 0),
+		})
+	}
+	v, err := ohlc.NewVec(lst, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return Series{Spec: instrument.Spec{Symbol: sym, SecurityType: instrument.Equity}, Data: v}
+}
+
+// scripted strategy submits orders on given dates.
+type scripted struct {
+	orders map[string][]Order // by date
+	bars   []string
+	fills  []Fill
+	errs   []error
+}
+
+func (s *scripted) OnBar(b *Broker, bar Bar) {
+	s.bars = append(s.bars, bar.Date.String()+" "+bar.Symbol)
+	for _, o := range s.orders[bar.Date.String()+" "+bar.Symbol] {
+		if _, err := b.Submit(o); err != nil {
+			s.errs = append(s.errs, err)
+		}
+	}
+}
+
+func (s *scripted) OnFill(b *Broker, f Fill) {
+	s.fills = append(s.fills, f)
+}
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func TestRun(t *testing.T) {
+	spy := []string{
+		"2020-01-02 100 102 99 101",
+		"2020-01-03 101 105 100 104",
+		"2020-01-06 103 104 97 98",
+		"2020-01-07 98 99 95 96",
+	}
+
+	tests := []struct {
+		label  string
+		cfg    Config
+		orders []Order // submitted on the first bar
+		fills  []string
+		cash   string
+		pos    string
+		equity string // last point
+		status []OrderStatus
+	}{
+		{
+			label:  "market at next open",
+			cfg:    Config{Cash: dec("1000")},
+			orders: []Order{{Symbol: "SPY", Side: Buy, Qty: dec("5")}},
+			fills:  []string{"2020-01-03 buy 5 @ 101"},
+			cash:   "495", pos: "5", equity: "975",
+		},
+		{
+			label:  "market at close",
+			cfg:    Config{Cash: dec("1000"), FillAt: FillClose},
+			orders: []Order{{Symbol: "SPY", Side: Buy, Qty: dec("5")}},
+			fills:  []string{"2020-01-02 buy 5 @ 101"},
+			cash:   "495", pos: "5", equity: "975",
+		},
+		{
+			label: "commission and slippage",
+			cfg: Config{Cash: dec("1000"), Commission: PerShare{Rate: dec("0.01"), Min: dec("1")},
+				Slippage: FixedSlippage{Amount: dec("0.5")}},
+			orders: []Order{{Symbol: "SPY", Side: Buy, Qty: dec("5")}},
+			fills:  []string{"2020-01-03 buy 5 @ 101.5 fee 1"},
+			cash:   "491.5", pos: "5", equity: "971.5",
+		},
+		{
+			label: "limit buy",
+			cfg:   Config{Cash: dec("1000")},
+			orders: []Order{
+				{Symbol: "SPY", Side: Buy, Type: Limit, Qty: dec("2"), Price: dec("97.5")},
+				{Symbol: "SPY", Side: Buy, Type: Limit, Qty: dec("1"), Price: dec("90")},
+			},
+			fills: []string{"2020-01-06 buy 2 @ 97.5"},
+			cash:  "805", pos: "2", equity: "997",
+			status: []OrderStatus{Filled, Pending},
+		},
+		{
+			label: "stop buy with gap",
+			cfg:   Config{Cash: dec("1000")},
+			orders: []Order{
+				{Symbol: "SPY", Side: Buy, Type: Stop, Qty: dec("1"), Price: dec("100.5")},
+				{Symbol: "SPY", Side: Buy, Type: Stop, Qty: dec("1"), Price: dec("104.5")},
+			},
+			fills: []string{"2020-01-03 buy 1 @ 101", "2020-01-03 buy 1 @ 104.5"},
+			cash:  "794.5", pos: "2", equity: "986.5",
+		},
+		{
+			label:  "short selling is rejected",
+			cfg:    Config{Cash: dec("1000")},
+			orders: []Order{{Symbol: "SPY", Side: Sell, Qty: dec("1")}},
+			cash:   "1000", pos: "0", equity: "1000",
+			status: []OrderStatus{Rejected},
+		},
+		{
+			label:  "short selling",
+			cfg:    Config{Cash: dec("1000"), AllowShort: true},
+			orders: []Order{{Symbol: "SPY", Side: Sell, Qty: dec("1")}},
+			fills:  []string{"2020-01-03 sell 1 @ 101"},
+			cash:   "1101", pos: "-1", equity: "1005",
+		},
+		{
+			label:  "insufficient cash",
+			cfg:    Config{Cash: dec("100")},
+			orders: []Order{{Symbol: "SPY", Side: Buy, Qty: dec("1")}},
+			cash:   "100", pos: "0", equity: "100",
+			status: []OrderStatus{Rejected},
+		},
+	}
+
+	for _, tc := range tests {
+		s := &scripted{orders: map[string][]Order{"2020-01-02 SPY": tc.orders}}
+		res, err := Run(tc.cfg, s, mkSeries(t, "SPY", spy...))
+		if err != nil {
+			t.Errorf("%s - unexpected error: %v", tc.label, err)
+			continue
+		}
+		if len(s.errs) > 0 {
+			t.Errorf("%s - unexpected submit error: %v", tc.label, s.errs)
+		}
+
+		fills := []string{}
+		for _, f := range res.Fills {
+			str := f.Date.String() + " " + f.Side.String() + " " + f.Qty.String() + " @ " + f.Price.String()
+			if !f.Commission.IsZero() {
+				str += " fee " + f.Commission.String()
+			}
+			fills = append(fills, str)
+		}
+		if strings.Join(fills, ", ") != strings.Join(tc.fills, ", ") {
+			t.Errorf("%s - fills should be %v, got %v", tc.label, tc.fills, fills)
+		}
+		if len(s.fills) != len(res.Fills) {
+			t.Errorf("%s - OnFill should be called %d times, got %d", tc.label, len(res.Fills), len(s.fills))
+		}
+
+		last := res.Equity[len(res.Equity)-1]
+		switch {
+		case !res.Cash.Equal(dec(tc.cash)):
+			t.Errorf("%s - cash should be %s, got %s", tc.label, tc.cash, res.Cash)
+		case !res.Positions["SPY"].Equal(dec(tc.pos)):
+			t.Errorf("%s - position should be %s, got %s", tc.label, tc.pos, res.Positions["SPY"])
+		case !last.Equity.Equal(dec(tc.equity)):
+			t.Errorf("%s - equity should be %s, got %s", tc.label, tc.equity, last.Equity)
+		case len(res.Equity) != len(spy):
+			t.Errorf("%s - equity curve should have %d points, got %d", tc.label, len(spy), len(res.Equity))
+		}
+		for i, st := range tc.status {
+			if res.Orders[i].Status != st {
+				t.Errorf("%s - order %d should be %s, got %s (%s)",
+					tc.label, res.Orders[i].ID, st, res.Orders[i].Status, res.Orders[i].Reason)
+			}
+		}
+	}
+}
+
+func TestLimitPriceRounding(t *testing.T) {
+	// equity prices have 2 decimal places; limit 10.005 is between ticks
+	series := mkSeries(t, "SPY",
+		"2020-01-02 10.2 10.2 10.2 10.2",
+		"2020-01-03 10.2 10.3 9.9 10",
+		"2020-01-06 9.8 10.2 9.8 10.1",
+	)
+	s := &scripted{orders: map[string][]Order{
+		"2020-01-02 SPY": {{Symbol: "SPY", Side: Buy, Type: Limit, Qty: dec("1"), Price: dec("10.005")}},
+		"2020-01-03 SPY": {{Symbol: "SPY", Side: Sell, Type: Limit, Qty: dec("1"), Price: dec("10.005")}},
+	}}
+	res, err := Run(Config{Cash: dec("100")}, s, series)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.errs) > 0 {
+		t.Fatalf("unexpected submit error: %v", s.errs)
+	}
+	if len(res.Fills) != 2 {
+		t.Fatalf("expected 2 fills, got %d", len(res.Fills))
+	}
+
+	tests := []struct {
+		label string
+		price string
+	}{
+		{"buy limit is rounded down", "10"},
+		{"sell limit is rounded up", "10.01"},
+	}
+	for i, tc := range tests {
+		if !res.Orders[i].Price.Equal(dec(tc.price)) {
+			t.Errorf("%s - order price should be %s, got %s", tc.label, tc.price, res.Orders[i].Price)
+		}
+		if !res.Fills[i].Price.Equal(dec(tc.price)) {
+			t.Errorf("%s - fill price should be %s, got %s", tc.label, tc.price, res.Fills[i].Price)
+		}
+	}
+
+	// limit price rounded to zero is rejected
+	s = &scripted{orders: map[string][]Order{
+		"2020-01-02 SPY": {{Symbol: "SPY", Side: Buy, Type: Limit, Qty: dec("1"), Price: dec("0.001")}},
+	}}
+	if _, err := Run(Config{Cash: dec("100")}, s, series); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.errs) != 1 {
+		t.Errorf("buy limit below tick - should have an error")
+	}
+}
+
+func TestRunMultipleSeries(t *testing.T) {
+	a := mkSeries(t, "A", "2020-01-02 10 10 10 10", "2020-01-06 12 12 12 12")
+	b := mkSeries(t, "B", "2020-01-02 20 20 20 20", "2020-01-03 21 21 21 21", "2020-01-06 22 22 22 22")
+
+	s := &scripted{orders: map[string][]Order{
+		"2020-01-02 A": {{Symbol: "A", Side: Buy, Qty: dec("10")}},
+		"2020-01-02 B": {{Symbol: "C", Side: Buy, Qty: dec("1")}, {Symbol: "B", Side: Buy, Qty: dec("0")}},
+	}}
+	res, err := Run(Config{Cash: dec("1000")}, s, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "2020-01-02 A, 2020-01-02 B, 2020-01-03 B, 2020-01-06 A, 2020-01-06 B"
+	if strings.Join(s.bars, ", ") != expected {
+		t.Errorf("bars should be replayed as %s, got %s", expected, strings.Join(s.bars, ", "))
+	}
+	if len(s.errs) != 2 {
+		t.Errorf("unknown symbol and zero qty - should have 2 errors, got %v", s.errs)
+	}
+	// A is not traded on 2020-01-03 so the order is filled on 2020-01-06
+	if len(res.Fills) != 1 || res.Fills[0].Date.String() != "2020-01-06" {
+		t.Errorf("unexpected fills: %+v", res.Fills)
+	}
+	if !res.Equity[1].Equity.Equal(dec("1000")) || !res.Equity[2].Equity.Equal(dec("1000")) {
+		t.Errorf("unexpected equity curve: %+v", res.Equity)
+	}
+
+	if _, err := Run(Config{Cash: dec("1000")}, s, a, a); err == nil {
+		t.Errorf("duplicate series - should have an error")
+	}
+	if _, err := Run(Config{Cash: dec("-1")}, s, a); err == nil {
+		t.Errorf("negative cash - should have an error")
+	}
+}
@@ -0,0 +1,382 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/profioss/trada/model/instrument"
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/pkg/typedef"
+
+	"github.com/shopspring/decimal"
+)
+
+// Side of order.
+type Side int
+
+// Side values.
+const (
+	Buy Side = iota
+	Sell
+)
+
+// String implements fmt.Stringer.
+func (s Side) String() string {
+	switch s {
+	case Buy:
+		return "buy"
+	case Sell:
+		return "sell"
+	}
+	return fmt.Sprintf("Side(%d)", int(s))
+}
+
+// OrderType defines order execution.
+type OrderType int
+
+// OrderType values.
+const (
+	Market OrderType = iota
+	Limit            // at Price or better
+	Stop             // market order once Price is reached
+)
+
+// String implements fmt.Stringer.
+func (t OrderType) String() string {
+	switch t {
+	case Market:
+		return "market"
+	case Limit:
+		return "limit"
+	case Stop:
+		return "stop"
+	}
+	return fmt.Sprintf("OrderType(%d)", int(t))
+}
+
+// OrderStatus is state of order.
+type OrderStatus int
+
+// OrderStatus values.
+const (
+	Pending OrderStatus = iota
+	Filled
+	Cancelled
+	Rejected
+)
+
+// String implements fmt.Stringer.
+func (s OrderStatus) String() string {
+	switch s {
+	case Pending:
+		return "pending"
+	case Filled:
+		return "filled"
+	case Cancelled:
+		return "cancelled"
+	case Rejected:
+		return "rejected"
+	}
+	return fmt.Sprintf("OrderStatus(%d)", int(s))
+}
+
+// Order is order to buy or sell an instrument.
+// Orders are valid until filled or cancelled.
+type Order struct {
+	Symbol string
+	Side   Side
+	Type   OrderType
+	Qty    decimal.Decimal
+	Price  decimal.Decimal // limit or stop price
+
+	// set by Broker
+	ID     int
+	Placed typedef.Date
+	Status OrderStatus
+	Reason string // why the order was rejected
+}
+
+// Fill is execution of order.
+type Fill struct {
+	OrderID    int
+	Symbol     string
+	Side       Side
+	Date       typedef.Date
+	Qty        decimal.Decimal
+	Price      decimal.Decimal
+	Commission decimal.Decimal
+}
+
+// Value provides traded value without commission.
+func (f Fill) Value() decimal.Decimal {
+	return f.Qty.Mul(f.Price)
+}
+
+// feed is replayed series.
+type feed struct {
+	spec instrument.Spec
+	data []ohlc.OHLC
+	idx  int // index of the current bar, -1 before the first bar
+}
+
+// Broker simulates order execution and keeps account of cash and positions.
+type Broker struct {
+	cfg       Config
+	date      typedef.Date
+	cash      decimal.Decimal
+	positions map[string]decimal.Decimal
+	feeds     map[string]*feed
+	order     []*feed // feeds in order of replay
+	orders    []Order
+	fills     []Fill
+	equity    []Point
+}
+
+func newBroker(cfg Config) *Broker {
+	return &Broker{
+		cfg:       cfg,
+		cash:      cfg.Cash,
+		positions: map[string]decimal.Decimal{},
+		feeds:     map[string]*feed{},
+	}
+}
+
+// Date provides date of the current bar.
+func (b *Broker) Date() typedef.Date {
+	return b.date
+}
+
+// Cash provides available cash.
+func (b *Broker) Cash() decimal.Decimal {
+	return b.cash
+}
+
+// Position provides position in symbol; short position is negative.
+func (b *Broker) Position(symbol string) decimal.Decimal {
+	return b.positions[symbol]
+}
+
+// Equity provides cash and positions marked to the last close.
+func (b *Broker) Equity() decimal.Decimal {
+	eq := b.cash
+	for _, f := range b.order {
+		qty := b.positions[f.spec.Symbol]
+		if qty.IsZero() || f.idx < 0 {
+			continue
+		}
+		eq = eq.Add(qty.Mul(f.data[f.idx].Close))
+	}
+	return eq
+}
+
+// History provides last n bars of symbol up to the current bar
+// (all replayed bars if n < 1).
+func (b *Broker) History(symbol string, n int) []ohlc.OHLC {
+	f, ok := b.feeds[symbol]
+	if !ok || f.idx < 0 {
+		return []ohlc.OHLC{}
+	}
+
+	from := 0
+	if n > 0 && f.idx+1-n > 0 {
+		from = f.idx + 1 - n
+	}
+	return append([]ohlc.OHLC{}, f.data[from:f.idx+1]...)
+}
+
+// Orders provides all orders.
+func (b *Broker) Orders() []Order {
+	return append([]Order{}, b.orders...)
+}
+
+// Open provides pending orders.
+func (b *Broker) Open() []Order {
+	output := []Order{}
+	for _, o := range b.orders {
+		if o.Status == Pending {
+			output = append(output, o)
+		}
+	}
+	return output
+}
+
+// Submit places order and provides its ID.
+func (b *Broker) Submit(o Order) (int, error) {
+	f, ok := b.feeds[o.Symbol]
+	switch {
+	case !ok:
+		return 0, fmt.Errorf("unknown symbol %q", o.Symbol)
+
+	case o.Side != Buy && o.Side != Sell:
+		return 0, fmt.Errorf("%s: invalid side %s", o.Symbol, o.Side)
+
+	case o.Type != Market && o.Type != Limit && o.Type != Stop:
+		return 0, fmt.Errorf("%s: invalid order type %s", o.Symbol, o.Type)
+
+	case o.Type != Market && !o.Price.IsPositive():
+		return 0, fmt.Errorf("%s: %s order price %s is not positive", o.Symbol, o.Type, o.Price)
+	}
+	if err := f.spec.ValidateQty(o.Qty); err != nil {
+		return 0, err
+	}
+	if o.Type == Limit {
+		o.Price = limitPrice(f.spec, o.Side, o.Price)
+		if !o.Price.IsPositive() {
+			return 0, fmt.Errorf("%s: limit price is below tick %s", o.Symbol, f.spec.Tick())
+		}
+	}
+
+	o.ID = len(b.orders) + 1
+	o.Placed = b.date
+	o.Status = Pending
+	o.Reason = ""
+	b.orders = append(b.orders, o)
+
+	return o.ID, nil
+}
+
+// limitPrice rounds limit price to tick toward the trader (down for buy,
+// up for sell), so that fills never exceed the requested limit.
+func limitPrice(spec instrument.Spec, side Side, price decimal.Decimal) decimal.Decimal {
+	ticks := price.Div(spec.Tick())
+	if side == Buy {
+		ticks = ticks.Floor()
+	} else {
+		ticks = ticks.Ceil()
+	}
+	return ticks.Mul(spec.Tick()).Round(int32(spec.PricePrecision()))
+}
+
+// Cancel cancels pending order.
+func (b *Broker) Cancel(id int) error {
+	if id < 1 || id > len(b.orders) {
+		return fmt.Errorf("unknown order %d", id)
+	}
+	o := &b.orders[id-1]
+	if o.Status != Pending {
+		return fmt.Errorf("order %d is %s", id, o.Status)
+	}
+	o.Status = Cancelled
+
+	return nil
+}
+
+// execute fills pending orders of feed f at bar.
+// Orders placed before the bar are processed at open (atClose is false),
+// market orders placed on the bar are processed at close.
+func (b *Broker) execute(s Strategy, f *feed, bar Bar, atClose bool) {
+	n := len(b.orders) // orders submitted in OnFill wait for the next pass
+	for i := 0; i < n; i++ {
+		o := b.orders[i]
+		if o.Status != Pending || o.Symbol != f.spec.Symbol {
+			continue
+		}
+
+		var price decimal.Decimal
+		var ok bool
+		switch {
+		case atClose && o.Placed == bar.Date && o.Type == Market:
+			price, ok = bar.Close, true
+		case !atClose && o.Placed.Time().Before(bar.Date.Time()):
+			price, ok = triggerPrice(o, bar.OHLC)
+		}
+		if !ok {
+			continue
+		}
+
+		if o.Type != Limit && b.cfg.Slippage != nil {
+			price = clamp(b.cfg.Slippage.Price(o.Side, price), bar.Low, bar.High)
+		}
+		price = f.spec.RoundPrice(price)
+
+		fill, err := b.fill(o, bar.Date, price)
+		if err != nil {
+			b.orders[i].Status = Rejected
+			b.orders[i].Reason = err.Error()
+			continue
+		}
+		b.orders[i].Status = Filled
+		s.OnFill(b, fill)
+	}
+}
+
+// triggerPrice provides fill price of order at bar opening.
+func triggerPrice(o Order, bar ohlc.OHLC) (decimal.Decimal, bool) {
+	switch {
+	case o.Type == Market:
+		return bar.Open, true
+
+	case o.Type == Limit && o.Side == Buy:
+		if bar.Open.LessThanOrEqual(o.Price) {
+			return bar.Open, true
+		}
+		if bar.Low.LessThanOrEqual(o.Price) {
+			return o.Price, true
+		}
+
+	case o.Type == Limit && o.Side == Sell:
+		if bar.Open.GreaterThanOrEqual(o.Price) {
+			return bar.Open, true
+		}
+		if bar.High.GreaterThanOrEqual(o.Price) {
+			return o.Price, true
+		}
+
+	case o.Type == Stop && o.Side == Buy:
+		if bar.Open.GreaterThanOrEqual(o.Price) {
+			return bar.Open, true
+		}
+		if bar.High.GreaterThanOrEqual(o.Price) {
+			return o.Price, true
+		}
+
+	case o.Type == Stop && o.Side == Sell:
+		if bar.Open.LessThanOrEqual(o.Price) {
+			return bar.Open, true
+		}
+		if bar.Low.LessThanOrEqual(o.Price) {
+			return o.Price, true
+		}
+	}
+
+	return decimal.Zero, false
+}
+
+// fill books execution of order o.
+func (b *Broker) fill(o Order, d typedef.Date, price decimal.Decimal) (Fill, error) {
+	f := Fill{OrderID: o.ID, Symbol: o.Symbol, Side: o.Side, Date: d, Qty: o.Qty, Price: price}
+	if b.cfg.Commission != nil {
+		f.Commission = b.cfg.Commission.Commission(f.Qty, f.Price)
+	}
+
+	pos := b.positions[o.Symbol]
+	switch o.Side {
+	case Buy:
+		cost := f.Value().Add(f.Commission)
+		if cost.GreaterThan(b.cash) {
+			return f, fmt.Errorf("insufficient cash %s for %s", b.cash, cost)
+		}
+		b.cash = b.cash.Sub(cost)
+		b.positions[o.Symbol] = pos.Add(f.Qty)
+
+	case Sell:
+		if !b.cfg.AllowShort && f.Qty.GreaterThan(pos) {
+			return f, errors.New("short selling is not allowed")
+		}
+		b.cash = b.cash.Add(f.Value()).Sub(f.Commission)
+		b.positions[o.Symbol] = pos.Sub(f.Qty)
+	}
+	b.fills = append(b.fills, f)
+
+	return f, nil
+}
+
+func clamp(v, min, max decimal.Decimal) decimal.Decimal {
+	switch {
+	case v.LessThan(min):
+		return min
+	case v.GreaterThan(max):
+		return max
+	}
+	return v
+}
@@ -0,0 +1,75 @@
+package backtest
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// Commission computes commission of trade.
+type Commission interface {
+	Commission(qty, price decimal.Decimal) decimal.Decimal
+}
+
+// PerShare commission is Rate per unit, at least Min.
+type PerShare struct {
+	Rate decimal.Decimal
+	Min  decimal.Decimal
+}
+
+// Commission implements Commission.
+func (c PerShare) Commission(qty, price decimal.Decimal) decimal.Decimal {
+	return decimal.Max(qty.Abs().Mul(c.Rate), c.Min)
+}
+
+// PerValue commission is Rate (e.g. 0.001 for 0.1%) of traded value, at least Min.
+type PerValue struct {
+	Rate decimal.Decimal
+	Min  decimal.Decimal
+}
+
+// Commission implements Commission.
+func (c PerValue) Commission(qty, price decimal.Decimal) decimal.Decimal {
+	return decimal.Max(qty.Mul(price).Abs().Mul(c.Rate), c.Min)
+}
+
+// FixedFee commission is Fee per trade.
+type FixedFee struct {
+	Fee decimal.Decimal
+}
+
+// Commission implements Commission.
+func (c FixedFee) Commission(qty, price decimal.Decimal) decimal.Decimal {
+	return c.Fee
+}
+
+// Slippage adjusts fill price of market and stop orders.
+// Adjusted price is limited by range of the bar.
+type Slippage interface {
+	Price(side Side, price decimal.Decimal) decimal.Decimal
+}
+
+// FixedSlippage moves price by Amount against the trader.
+type FixedSlippage struct {
+	Amount decimal.Decimal
+}
+
+// Price implements Slippage.
+func (s FixedSlippage) Price(side Side, price decimal.Decimal) decimal.Decimal {
+	if side == Buy {
+		return price.Add(s.Amount)
+	}
+	return price.Sub(s.Amount)
+}
+
+// PercentSlippage moves price by Rate (e.g. 0.001 for 0.1%) against the trader.
+type PercentSlippage struct {
+	Rate decimal.Decimal
+}
+
+// Price implements Slippage.
+func (s PercentSlippage) Price(side Side, price decimal.Decimal) decimal.Decimal {
+	adj := price.Mul(s.Rate)
+	if side == Buy {
+		return price.Add(adj)
+	}
+	return price.Sub(adj)
+}
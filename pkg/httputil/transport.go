@@ -0,0 +1,102 @@
+package httputil
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HostLimiter limits request rate per host.
+// Requests to the same host are spaced by at least interval.
+// Zero interval means no limit.
+type HostLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+// NewHostLimiter creates HostLimiter.
+func NewHostLimiter(interval time.Duration) *HostLimiter {
+	return &HostLimiter{
+		interval: interval,
+		next:     make(map[string]time.Time),
+	}
+}
+
+// Wait blocks until request to host is allowed or ctx is done.
+func (l *HostLimiter) Wait(ctx context.Context, host string) error {
+	if l == nil || l.interval <= 0 {
+		return ctx.Err()
+	}
+
+	// reserve time slot for this request
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	l.next[host] = at.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := at.Sub(now)
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Transport is http.RoundTripper which sets User-Agent header
+// and limits request rate per host.
+type Transport struct {
+	// Base is underlying RoundTripper; http.DefaultTransport is used if nil.
+	Base http.RoundTripper
+
+	// UserAgent is set to each request if not empty.
+	UserAgent string
+
+	// Limiter limits request rate per host; no limit if nil.
+	Limiter *HostLimiter
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if err := t.Limiter.Wait(ctx, req.URL.Host); err != nil {
+		return nil, err
+	}
+
+	if t.UserAgent != "" {
+		req = req.Clone(ctx) // RoundTrip must not modify request
+		req.Header.Set("User-Agent", t.UserAgent)
+	}
+
+	return t.base().RoundTrip(req)
+}
+
+// CloseIdleConnections closes idle connections of the Base RoundTripper.
+func (t *Transport) CloseIdleConnections() {
+	type closeIdler interface {
+		CloseIdleConnections()
+	}
+	if ci, ok := t.base().(closeIdler); ok {
+		ci.CloseIdleConnections()
+	}
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base == nil {
+		return http.DefaultTransport
+	}
+	return t.Base
+}
@@ -0,0 +1,61 @@
+package httputil
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHostLimiter(t *testing.T) {
+	interval := 50 * time.Millisecond
+	l := NewHostLimiter(interval)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(ctx, "a.example.com"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 2*interval {
+		t.Errorf("3 requests to the same host should take at least %s, took %s", 2*interval, elapsed)
+	}
+
+	// other host is not affected
+	start = time.Now()
+	if err := l.Wait(ctx, "b.example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= interval {
+		t.Errorf("first request to other host should not wait, took %s", elapsed)
+	}
+
+	// cancelled context
+	ctxCancel, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := l.Wait(ctxCancel, "a.example.com"); err == nil {
+		t.Errorf("cancelled context should return an error")
+	}
+}
+
+func TestTransportUserAgent(t *testing.T) {
+	ua := "trada-test/1.0 (https://github.com/profioss/trada)"
+	got := ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &Transport{UserAgent: ua}}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if got != ua {
+		t.Errorf("User-Agent should be %q, got %q", ua, got)
+	}
+}
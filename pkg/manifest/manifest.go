@@ -0,0 +1,171 @@
+// Package manifest records outcome of a market data fetcher run.
+// Manifest is stored as JSON in output directory so that runs
+// can be audited and compared later.
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/profioss/trada/pkg/osutil"
+)
+
+// Status is outcome of fetching single symbol.
+type Status string
+
+// Symbol statuses.
+const (
+	StatusOK      Status = "ok"      // data fetched and stored
+	StatusEmpty   Status = "empty"   // no data returned
+	StatusFailed  Status = "failed"  // fetch, parse or store failed
+	StatusSkipped Status = "skipped" // not attempted e.g. cancelled run
+)
+
+// timeFormat is format of run id.
+const timeFormat = "20060102T150405Z"
+
+// Changes counts rows of stored data changed by the run.
+type Changes struct {
+	New     int `json:"new"`     // rows of dates not stored before
+	Updated int `json:"updated"` // existing rows with changed values
+}
+
+// Symbol records outcome of single symbol.
+type Symbol struct {
+	Symbol string `json:"symbol"`
+	Status Status `json:"status"`
+	Bars   int    `json:"bars"` // fetched bars
+	Changes
+	First string `json:"first,omitempty"` // date of the first fetched bar
+	Last  string `json:"last,omitempty"`  // date of the last fetched bar
+	File  string `json:"file,omitempty"`
+	Error string `json:"error,omitempty"`
+
+	// optional capture of market snapshot e.g. order book
+	Snapshot      Status `json:"snapshot,omitempty"`
+	SnapshotError string `json:"snapshot-error,omitempty"`
+}
+
+// Manifest records outcome of fetcher run.
+type Manifest struct {
+	RunID    string    `json:"run-id"`
+	Command  string    `json:"command"`
+	Provider string    `json:"provider"`
+	Range    string    `json:"range,omitempty"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Symbols  []Symbol  `json:"symbols"`
+
+	mu sync.Mutex
+}
+
+// New starts Manifest of command run.
+func New(command, provider, rng string) *Manifest {
+	start := time.Now().UTC()
+	return &Manifest{
+		RunID:    fmt.Sprintf("%s-%d", start.Format(timeFormat), os.Getpid()),
+		Command:  command,
+		Provider: provider,
+		Range:    rng,
+		Start:    start,
+		Symbols:  []Symbol{},
+	}
+}
+
+// Add records outcome of symbol. It is safe for concurrent use.
+func (m *Manifest) Add(s Symbol) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Symbols = append(m.Symbols, s)
+}
+
+// Skip records symbols which were not attempted.
+func (m *Manifest) Skip(reason string, symbols ...string) {
+	for _, sym := range symbols {
+		m.Add(Symbol{Symbol: sym, Status: StatusSkipped, Error: reason})
+	}
+}
+
+// Finish sets End time and sorts symbols.
+func (m *Manifest) Finish() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.End = time.Now().UTC()
+	sort.SliceStable(m.Symbols, func(i, j int) bool { return m.Symbols[i].Symbol < m.Symbols[j].Symbol })
+}
+
+// Count returns number of symbols with given status.
+func (m *Manifest) Count(st Status) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, s := range m.Symbols {
+		if s.Status == st {
+			n++
+		}
+	}
+	return n
+}
+
+// Save writes manifest to <dir>/manifest-<RunID>.json.
+func (m *Manifest) Save(dir string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fpath := filepath.Join(dir, "manifest-"+m.RunID+".json")
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fpath, fmt.Errorf("marshal manifest error: %v", err)
+	}
+
+	err = osutil.WriteFile(fpath+".swp", append(data, '\n'))
+	if err != nil {
+		return fpath, err
+	}
+	err = os.Rename(fpath+".swp", fpath)
+	if err != nil {
+		return fpath, fmt.Errorf("rename %s -> %s: %s", fpath+".swp", fpath, err)
+	}
+
+	return fpath, nil
+}
+
+// PrintSummary writes table of symbols and run totals.
+func (m *Manifest) PrintSummary(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "SYMBOL\tSTATUS\tBARS\tNEW\tUPDATED\tFIRST\tLAST\tERROR")
+	for _, s := range m.Symbols {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%s\t%s\t%s\n",
+			s.Symbol, s.Status, s.Bars, s.New, s.Updated, dash(s.First), dash(s.Last), s.errors())
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "run %s: %d symbols - ok %d, empty %d, failed %d, skipped %d in %s\n",
+		m.RunID, len(m.Symbols), m.Count(StatusOK), m.Count(StatusEmpty),
+		m.Count(StatusFailed), m.Count(StatusSkipped), m.End.Sub(m.Start).Round(time.Millisecond))
+}
+
+// errors joins errors of data and snapshot.
+func (s Symbol) errors() string {
+	switch {
+	case s.SnapshotError == "":
+		return s.Error
+	case s.Error == "":
+		return "snapshot: " + s.SnapshotError
+	}
+	return s.Error + "; snapshot: " + s.SnapshotError
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,53 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := New("get-md-test", "test", "3m")
+	m.Add(Symbol{Symbol: "MSFT", Status: StatusOK, Bars: 2, Changes: Changes{New: 1, Updated: 1},
+		First: "2019-10-29", Last: "2019-10-30"})
+	m.Add(Symbol{Symbol: "AAPL", Status: StatusFailed, Error: "HTTP 500",
+		Snapshot: StatusFailed, SnapshotError: "HTTP 404"})
+	m.Skip("cancelled", "SPY", "QQQ")
+	m.Finish()
+
+	if m.Symbols[0].Symbol != "AAPL" || m.Count(StatusSkipped) != 2 {
+		t.Errorf("unexpected symbols: %v", m.Symbols)
+	}
+
+	fpath, err := m.Save(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := Manifest{}
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.RunID != m.RunID || len(loaded.Symbols) != 4 || loaded.Symbols[1].New != 1 {
+		t.Errorf("unexpected manifest: %s", data)
+	}
+
+	buf := &bytes.Buffer{}
+	m.PrintSummary(buf)
+	if !strings.Contains(buf.String(), "ok 1, empty 0, failed 1, skipped 2") ||
+		!strings.Contains(buf.String(), "HTTP 500; snapshot: HTTP 404") {
+		t.Errorf("unexpected summary:\n%s", buf)
+	}
+}
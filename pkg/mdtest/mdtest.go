@@ -0,0 +1,248 @@
+// Package mdtest provides fake market data server for integration tests.
+// It emulates IEX Cloud and Cryptowatch REST endpoints used by the fetchers
+// and serves responses from fixture files.
+//
+// Fixture layout (relative to fixtures directory):
+//
+//	iex/<SYMBOL>-chart.json     GET /stock/<SYMBOL>/chart/<range>
+//	iex/<SYMBOL>-previous.json  GET /stock/<SYMBOL>/previous
+//	cw/<exchange>/<pair>.json   GET /markets/<exchange>/<pair>/ohlc
+//	cw/<exchange>/<pair>-orderbook.json  GET /markets/<exchange>/<pair>/orderbook
+//	cw/<exchange>/<pair>-trades.json     GET /markets/<exchange>/<pair>/trades
+//
+// Missing fixture results in 404 Not Found.
+package mdtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Mode is response mode of emulated endpoint.
+type Mode int
+
+const (
+	// ModeOK - fixture data is served.
+	ModeOK Mode = iota
+
+	// ModeEmpty - valid response without data.
+	ModeEmpty
+
+	// ModeRateLimited - 429 Too Many Requests.
+	ModeRateLimited
+
+	// ModeServerError - 500 Internal Server Error.
+	ModeServerError
+
+	// ModeMalformed - truncated JSON with 200 OK.
+	ModeMalformed
+)
+
+// Server is fake market data server.
+type Server struct {
+	*httptest.Server
+
+	fixtures string
+
+	mu       sync.Mutex
+	modes    map[string]Mode // by symbol or pair
+	requests []string        // request paths
+
+	// Cryptowatch allowance; disabled if allowance is zero
+	allowance int64
+	cost      int64
+}
+
+// NewServer starts fake server serving fixtures from given directory.
+// Caller should call Close when finished.
+func NewServer(fixtures string) *Server {
+	s := &Server{fixtures: fixtures, modes: map[string]Mode{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stock/", s.handleIEX)
+	mux.HandleFunc("/markets/", s.handleCW)
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// SetMode sets response mode for symbol (IEX) or pair (Cryptowatch).
+func (s *Server) SetMode(symbol string, m Mode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.modes[symbol] = m
+}
+
+// SetAllowance enables Cryptowatch allowance emulation: each request costs
+// cost units; requests are rejected by 429 when remaining allowance
+// is less than the cost.
+func (s *Server) SetAllowance(remaining, cost int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.allowance, s.cost = remaining, cost
+}
+
+// Requests returns paths of received requests.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	output := make([]string, len(s.requests))
+	copy(output, s.requests)
+	return output
+}
+
+func (s *Server) mode(symbol string, path string) Mode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, path)
+	return s.modes[symbol]
+}
+
+// handleIEX serves /stock/<SYMBOL>/chart/<range> and /stock/<SYMBOL>/previous.
+func (s *Server) handleIEX(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 3 || (parts[2] != "chart" && parts[2] != "previous") {
+		http.NotFound(w, r)
+		return
+	}
+	sym, endpoint := parts[1], parts[2]
+
+	switch s.mode(sym, r.URL.Path) {
+	case ModeEmpty:
+		if endpoint == "previous" {
+			writeJSON(w, http.StatusOK, []byte("{}"))
+			return
+		}
+		writeJSON(w, http.StatusOK, []byte("[]"))
+		return
+	case ModeRateLimited:
+		http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+		return
+	case ModeServerError:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	case ModeMalformed:
+		writeJSON(w, http.StatusOK, []byte(`[{"date":"2019-10-29","open":`))
+		return
+	}
+
+	data, err := s.fixture("iex", sym+"-"+endpoint+".json")
+	if err != nil {
+		http.Error(w, "Unknown symbol", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+// cwEmpty are results of Cryptowatch endpoints without data.
+var cwEmpty = map[string]json.RawMessage{
+	"ohlc":      json.RawMessage(`{"86400":[]}`),
+	"orderbook": json.RawMessage(`{"asks":[],"bids":[],"seqNum":0}`),
+	"trades":    json.RawMessage(`[]`),
+}
+
+// handleCW serves /markets/<exchange>/<pair>/{ohlc,orderbook,trades}.
+func (s *Server) handleCW(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 4 || cwEmpty[parts[3]] == nil {
+		http.NotFound(w, r)
+		return
+	}
+	exchange, pair, endpoint := parts[1], parts[2], parts[3]
+	mode := s.mode(pair, r.URL.Path)
+
+	allowance, ok := s.spend()
+	if !ok {
+		writeJSON(w, http.StatusTooManyRequests, []byte(`{"error":"Out of allowance"}`))
+		return
+	}
+
+	switch mode {
+	case ModeEmpty:
+		writeCW(w, cwEmpty[endpoint], allowance)
+		return
+	case ModeRateLimited:
+		writeJSON(w, http.StatusTooManyRequests, []byte(`{"error":"Too many requests"}`))
+		return
+	case ModeServerError:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	case ModeMalformed:
+		writeJSON(w, http.StatusOK, []byte(`{"result":{"86400":[[1572393600,`))
+		return
+	}
+
+	fname := pair + ".json"
+	if endpoint != "ohlc" {
+		fname = pair + "-" + endpoint + ".json"
+	}
+	data, err := s.fixture("cw", exchange, fname)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, []byte(`{"error":"Instrument not found"}`))
+		return
+	}
+	resp := struct {
+		Result json.RawMessage `json:"result"`
+	}{}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		http.Error(w, fmt.Sprintf("invalid fixture: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeCW(w, resp.Result, allowance)
+}
+
+// spend charges request cost from allowance;
+// reports false if allowance is exhausted.
+func (s *Server) spend() (cwAllowance, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cost == 0 {
+		return cwAllowance{Remaining: 8000000000}, true
+	}
+	if s.allowance < s.cost {
+		return cwAllowance{Cost: s.cost, Remaining: s.allowance}, false
+	}
+	s.allowance -= s.cost
+	return cwAllowance{Cost: s.cost, Remaining: s.allowance}, true
+}
+
+type cwAllowance struct {
+	Cost      int64 `json:"cost"`
+	Remaining int64 `json:"remaining"`
+}
+
+func writeCW(w http.ResponseWriter, result json.RawMessage, allowance cwAllowance) {
+	data, err := json.Marshal(struct {
+		Result    json.RawMessage `json:"result"`
+		Allowance cwAllowance     `json:"allowance"`
+	}{result, allowance})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+func writeJSON(w http.ResponseWriter, status int, data []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+// fixture reads fixture file; path elements are sanitized.
+func (s *Server) fixture(elem ...string) ([]byte, error) {
+	for _, e := range elem {
+		if e == "" || e == "." || e == ".." || strings.ContainsAny(e, `/\`) {
+			return nil, os.ErrNotExist
+		}
+	}
+	return ioutil.ReadFile(filepath.Join(append([]string{s.fixtures}, elem...)...))
+}
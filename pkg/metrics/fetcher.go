@@ -0,0 +1,185 @@
+package metrics
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/profioss/trada/pkg/manifest"
+)
+
+// Fetcher is standard set of metrics of data fetcher commands.
+// Methods of nil *Fetcher do nothing so metrics are optional.
+type Fetcher struct {
+	Registry *Registry
+
+	Requests    Counter   // HTTP requests by host and status code
+	Latency     Histogram // HTTP request latency by host
+	Bars        Counter   // rows written (new and updated)
+	Symbols     Counter   // processed symbols (resources) by status
+	Allowance   Gauge     // remaining API allowance by provider
+	LastSuccess Gauge     // time of the last successful run
+}
+
+// NewFetcher creates Fetcher metrics of command.
+func NewFetcher(command string) *Fetcher {
+	r := NewRegistry("command", command)
+	return &Fetcher{
+		Registry: r,
+		Requests: r.Counter("trada_http_requests_total",
+			"HTTP requests by host and status code (error for transport errors).", "host", "code"),
+		Latency: r.Histogram("trada_http_request_duration_seconds",
+			"HTTP request latency in seconds.", DefBuckets, "host"),
+		Bars: r.Counter("trada_bars_written_total",
+			"Data rows written to output files (new and updated)."),
+		Symbols: r.Counter("trada_symbols_total",
+			"Processed symbols or resources by status.", "status"),
+		Allowance: r.Gauge("trada_api_allowance_remaining",
+			"Remaining API allowance reported by provider.", "provider"),
+		LastSuccess: r.Gauge("trada_last_success_timestamp_seconds",
+			"Unix time of the last run without failures."),
+	}
+}
+
+// Transport wraps base RoundTripper to record request metrics.
+// http.DefaultTransport is used if base is nil.
+func (f *Fetcher) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if f == nil {
+		return base
+	}
+	return &transport{base: base, f: f}
+}
+
+type transport struct {
+	base http.RoundTripper
+	f    *Fetcher
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+
+	host := req.URL.Host
+	t.f.Latency.Observe(time.Since(start).Seconds(), host)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	t.f.Requests.Inc(host, code)
+
+	return resp, err
+}
+
+// CloseIdleConnections closes idle connections of base transport.
+func (t *transport) CloseIdleConnections() {
+	type closeIdler interface {
+		CloseIdleConnections()
+	}
+	if tr, ok := t.base.(closeIdler); ok {
+		tr.CloseIdleConnections()
+	}
+}
+
+// Status records processed symbol (resource) with given status.
+func (f *Fetcher) Status(st manifest.Status) {
+	if f == nil {
+		return
+	}
+	f.Symbols.Inc(string(st))
+}
+
+// Written records number of data rows written.
+func (f *Fetcher) Written(rows int) {
+	if f == nil {
+		return
+	}
+	f.Bars.Add(float64(rows))
+}
+
+// SetAllowance records remaining API allowance of provider.
+func (f *Fetcher) SetAllowance(provider string, remaining int64) {
+	if f == nil {
+		return
+	}
+	f.Allowance.Set(float64(remaining), provider)
+}
+
+// Success records time of successful run.
+func (f *Fetcher) Success(t time.Time) {
+	if f == nil {
+		return
+	}
+	f.LastSuccess.Set(float64(t.Unix()))
+}
+
+// RestoreSuccess sets time of the last successful run from metrics file
+// written by previous run (see Registry.WriteFile), so failed run keeps it.
+// Missing file or metric is not an error.
+func (f *Fetcher) RestoreSuccess(path string) error {
+	if f == nil {
+		return nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	name := f.LastSuccess.f.name
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || !(fields[0] == name || strings.HasPrefix(fields[0], name+"{")) {
+			continue
+		}
+		v, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return fmt.Errorf("%s: invalid %s value: %v", path, name, err)
+		}
+		f.LastSuccess.Set(v)
+		return nil
+	}
+
+	return nil
+}
+
+// Manifest records symbol statuses and written rows of finished run.
+// Run without failed symbols is recorded as success.
+func (f *Fetcher) Manifest(m *manifest.Manifest) {
+	if f == nil {
+		return
+	}
+	for _, s := range m.Symbols {
+		f.Status(s.Status)
+		f.Written(s.New + s.Updated)
+	}
+	if m.Count(manifest.StatusFailed) == 0 && m.Count(manifest.StatusSkipped) == 0 {
+		f.Success(m.End)
+	}
+}
+
+// Serve starts HTTP server exposing metrics at /metrics on addr.
+// Returned function shuts the server down.
+func (f *Fetcher) Serve(addr string) (func(), error) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", f.Registry.Handler())
+	srv := &http.Server{Addr: addr, Handler: mux}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return func() {}, err
+	}
+	go srv.Serve(ln)
+
+	return func() { srv.Close() }, nil
+}
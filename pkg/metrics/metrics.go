@@ -0,0 +1,276 @@
+// Package metrics provides minimal Prometheus compatible metrics:
+// counters, gauges and histograms with labels exposed in text format
+// by HTTP handler or written to a file for node_exporter textfile collector.
+// See https://prometheus.io/docs/instrumenting/exposition_formats/
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/profioss/trada/pkg/osutil"
+)
+
+// DefBuckets are default histogram buckets of request latency in seconds.
+var DefBuckets = []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30}
+
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+// Registry is collection of metrics.
+type Registry struct {
+	mu          sync.Mutex
+	constLabels []string // name, value pairs added to all series
+	families    []*family
+}
+
+// NewRegistry creates Registry. Constant labels are name, value pairs
+// added to all metrics e.g. NewRegistry("command", "get-md-iex").
+func NewRegistry(constLabels ...string) *Registry {
+	if len(constLabels)%2 != 0 {
+		panic("metrics: odd number of constant label elements")
+	}
+	return &Registry{constLabels: constLabels}
+}
+
+// family is metric with all its labeled series.
+type family struct {
+	name    string
+	help    string
+	typ     string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*series // key: label values joined by \xff
+}
+
+// series is single labeled time series.
+type series struct {
+	values  []string
+	value   float64  // counter or gauge value
+	counts  []uint64 // histogram bucket counts (not cumulative)
+	sum     float64
+	samples uint64
+}
+
+func (r *Registry) register(name, help, typ string, labels []string, buckets []float64) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, f := range r.families {
+		if f.name == name {
+			panic(fmt.Sprintf("metrics: %s registered twice", name))
+		}
+	}
+	f := &family{
+		name: name, help: help, typ: typ,
+		labels: labels, buckets: buckets,
+		series: map[string]*series{},
+	}
+	r.families = append(r.families, f)
+
+	return f
+}
+
+// with returns series of given label values, creates it if needed.
+func (f *family) with(values []string) *series {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", f.name, len(f.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{values: append([]string{}, values...)}
+		if f.typ == typeHistogram {
+			s.counts = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// Counter is monotonically increasing value.
+type Counter struct{ f *family }
+
+// Counter registers counter with given label names.
+func (r *Registry) Counter(name, help string, labels ...string) Counter {
+	return Counter{r.register(name, help, typeCounter, labels, nil)}
+}
+
+// Add adds non-negative v to series of label values.
+func (c Counter) Add(v float64, values ...string) {
+	if v < 0 {
+		panic(fmt.Sprintf("metrics: counter %s can not decrease", c.f.name))
+	}
+	c.f.mu.Lock()
+	defer c.f.mu.Unlock()
+	c.f.with(values).value += v
+}
+
+// Inc increments series of label values by 1.
+func (c Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Gauge is value which can go up and down.
+type Gauge struct{ f *family }
+
+// Gauge registers gauge with given label names.
+func (r *Registry) Gauge(name, help string, labels ...string) Gauge {
+	return Gauge{r.register(name, help, typeGauge, labels, nil)}
+}
+
+// Set sets series of label values to v.
+func (g Gauge) Set(v float64, values ...string) {
+	g.f.mu.Lock()
+	defer g.f.mu.Unlock()
+	g.f.with(values).value = v
+}
+
+// Histogram samples observations into buckets.
+type Histogram struct{ f *family }
+
+// Histogram registers histogram with given bucket upper bounds
+// (sorted ascending) and label names.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) Histogram {
+	b := append([]float64{}, buckets...)
+	sort.Float64s(b)
+	return Histogram{r.register(name, help, typeHistogram, labels, b)}
+}
+
+// Observe adds observation v to series of label values.
+func (h Histogram) Observe(v float64, values ...string) {
+	h.f.mu.Lock()
+	defer h.f.mu.Unlock()
+
+	s := h.f.with(values)
+	i := sort.SearchFloat64s(h.f.buckets, v)
+	if i < len(s.counts) {
+		s.counts[i]++
+	}
+	s.sum += v
+	s.samples++
+}
+
+// WriteTo writes all metrics in Prometheus text format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	families := append([]*family{}, r.families...)
+	r.mu.Unlock()
+
+	buf := &bytes.Buffer{}
+	for _, f := range families {
+		f.write(buf, r.constLabels)
+	}
+
+	return buf.WriteTo(w)
+}
+
+func (f *family) write(buf *bytes.Buffer, constLabels []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.series) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(buf, "# TYPE %s %s\n", f.name, f.typ)
+
+	keys := make([]string, 0, len(f.series))
+	for k := range f.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		s := f.series[k]
+		pairs := append([]string{}, constLabels...)
+		for i, l := range f.labels {
+			pairs = append(pairs, l, s.values[i])
+		}
+
+		if f.typ != typeHistogram {
+			fmt.Fprintf(buf, "%s%s %s\n", f.name, labelStr(pairs), formatFloat(s.value))
+			continue
+		}
+
+		var cumulative uint64
+		for i, b := range f.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(buf, "%s_bucket%s %d\n",
+				f.name, labelStr(append(pairs, "le", formatFloat(b))), cumulative)
+		}
+		fmt.Fprintf(buf, "%s_bucket%s %d\n", f.name, labelStr(append(pairs, "le", "+Inf")), s.samples)
+		fmt.Fprintf(buf, "%s_sum%s %s\n", f.name, labelStr(pairs), formatFloat(s.sum))
+		fmt.Fprintf(buf, "%s_count%s %d\n", f.name, labelStr(pairs), s.samples)
+	}
+}
+
+// Handler returns HTTP handler exposing metrics e.g. at /metrics.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteTo(w)
+	})
+}
+
+// WriteFile atomically writes metrics to file
+// e.g. for node_exporter textfile collector (*.prom files).
+func (r *Registry) WriteFile(path string) error {
+	buf := &bytes.Buffer{}
+	r.WriteTo(buf)
+
+	err := osutil.WriteFile(path+".swp", buf.Bytes())
+	if err != nil {
+		return err
+	}
+	err = os.Rename(path+".swp", path)
+	if err != nil {
+		return fmt.Errorf("rename %s -> %s: %s", path+".swp", path, err)
+	}
+
+	return nil
+}
+
+// labelStr formats name, value pairs as {name="value",...}.
+func labelStr(pairs []string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+	lst := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		lst = append(lst, pairs[i]+`="`+escapeLabel(pairs[i+1])+`"`)
+	}
+	return "{" + strings.Join(lst, ",") + "}"
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
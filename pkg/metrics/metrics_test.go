@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/profioss/trada/pkg/manifest"
+)
+
+func TestWriteTo(t *testing.T) {
+	r := NewRegistry("command", "test")
+	c := r.Counter("test_requests_total", "Requests.", "code")
+	g := r.Gauge("test_remaining", "Remaining \"allowance\".")
+	h := r.Histogram("test_latency_seconds", "Latency.", []float64{1, 0.1}, "host")
+	r.Counter("test_unused_total", "Not written without series.")
+
+	c.Inc("200")
+	c.Add(2, "200")
+	c.Inc(`5"x`)
+	g.Set(42)
+	h.Observe(0.05, "a")
+	h.Observe(0.5, "a")
+	h.Observe(5, "a")
+
+	buf := &bytes.Buffer{}
+	if _, err := r.WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# HELP test_requests_total Requests.
+# TYPE test_requests_total counter
+test_requests_total{command="test",code="200"} 3
+test_requests_total{command="test",code="5\"x"} 1
+# HELP test_remaining Remaining "allowance".
+# TYPE test_remaining gauge
+test_remaining{command="test"} 42
+# HELP test_latency_seconds Latency.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{command="test",host="a",le="0.1"} 1
+test_latency_seconds_bucket{command="test",host="a",le="1"} 2
+test_latency_seconds_bucket{command="test",host="a",le="+Inf"} 3
+test_latency_seconds_sum{command="test",host="a"} 5.55
+test_latency_seconds_count{command="test",host="a"} 3
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", buf, expected)
+	}
+}
+
+func TestFetcher(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	f := NewFetcher("get-md-test")
+	client := &http.Client{Transport: f.Transport(nil)}
+	for _, p := range []string{"/ok", "/missing"} {
+		resp, err := client.Get(srv.URL + p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	m := manifest.New("get-md-test", "test", "")
+	m.Add(manifest.Symbol{Symbol: "A", Status: manifest.StatusOK, Changes: manifest.Changes{New: 3, Updated: 1}})
+	m.Finish()
+	f.Manifest(m)
+	f.SetAllowance("test", 1000)
+
+	dir, err := ioutil.TempDir("", "metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fpath := filepath.Join(dir, "get-md-test.prom")
+	if err := f.Registry.WriteFile(fpath); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	for _, line := range []string{
+		`trada_http_requests_total{command="get-md-test",host="` + host + `",code="200"} 1`,
+		`trada_http_requests_total{command="get-md-test",host="` + host + `",code="404"} 1`,
+		`trada_http_request_duration_seconds_count{command="get-md-test",host="` + host + `"} 2`,
+		`trada_bars_written_total{command="get-md-test"} 4`,
+		`trada_symbols_total{command="get-md-test",status="ok"} 1`,
+		`trada_api_allowance_remaining{command="get-md-test",provider="test"} 1000`,
+		`trada_last_success_timestamp_seconds{command="get-md-test"} `,
+	} {
+		if !strings.Contains(string(data), line) {
+			t.Errorf("missing %q in:\n%s", line, data)
+		}
+	}
+
+	// failed run keeps time of the last success
+	f2 := NewFetcher("get-md-test")
+	if err := f2.RestoreSuccess(fpath); err != nil {
+		t.Fatal(err)
+	}
+	failed := manifest.New("get-md-test", "test", "")
+	failed.Add(manifest.Symbol{Symbol: "A", Status: manifest.StatusFailed})
+	failed.Finish()
+	f2.Manifest(failed)
+	if err := f2.Registry.WriteFile(fpath); err != nil {
+		t.Fatal(err)
+	}
+	data2, err := ioutil.ReadFile(fpath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := `trada_last_success_timestamp_seconds{command="get-md-test"} ` + formatFloat(float64(m.End.Unix()))
+	if !strings.Contains(string(data2), line) {
+		t.Errorf("missing %q after failed run in:\n%s", line, data2)
+	}
+	if err := f2.RestoreSuccess(filepath.Join(dir, "missing.prom")); err != nil {
+		t.Errorf("missing file - unexpected error: %v", err)
+	}
+
+	var nilFetcher *Fetcher
+	nilFetcher.Manifest(m) // no panic
+}
@@ -0,0 +1,133 @@
+package stats
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/profioss/trada/pkg/typedef"
+)
+
+// daysPerYear is average length of year in days.
+const daysPerYear = 365.25
+
+// CAGR provides compound annual growth rate of prices (or equity)
+// based on calendar time between the first and the last date.
+func CAGR(prices Series) (float64, error) {
+	n := prices.Len()
+	if n < 2 {
+		return math.NaN(), errors.New("at least 2 values are required")
+	}
+	first, last := prices.Values[0], prices.Values[n-1]
+	if first <= 0 || last < 0 {
+		return math.NaN(), errors.New("values are not positive")
+	}
+	years := prices.Dates[n-1].Time().Sub(prices.Dates[0].Time()).Hours() / 24 / daysPerYear
+	if years <= 0 {
+		return math.NaN(), errors.New("zero time span")
+	}
+
+	return math.Pow(last/first, 1/years) - 1, nil
+}
+
+// Volatility provides annualized standard deviation of returns.
+func Volatility(returns Series, periods int) float64 {
+	return stdDev(returns.Values) * math.Sqrt(float64(periods))
+}
+
+// Sharpe provides annualized Sharpe ratio of returns;
+// rf is annual risk free rate.
+func Sharpe(returns Series, rf float64, periods int) float64 {
+	excess := mean(returns.Values) - rf/float64(periods)
+	return excess / stdDev(returns.Values) * math.Sqrt(float64(periods))
+}
+
+// Sortino provides annualized Sortino ratio of returns;
+// rf is annual risk free rate and also minimal acceptable return.
+func Sortino(returns Series, rf float64, periods int) float64 {
+	target := rf / float64(periods)
+	if returns.Len() == 0 {
+		return math.NaN()
+	}
+
+	sum := 0.0
+	for _, r := range returns.Values {
+		if r < target {
+			sum += (r - target) * (r - target)
+		}
+	}
+	downside := math.Sqrt(sum / float64(returns.Len()))
+
+	return (mean(returns.Values) - target) / downside * math.Sqrt(float64(periods))
+}
+
+// Drawdown is decline from peak.
+type Drawdown struct {
+	Max      float64      // maximal decline from peak as positive fraction e.g. 0.25
+	Peak     typedef.Date // date of peak before the maximal drawdown
+	Trough   typedef.Date // date of the maximal drawdown
+	Recovery typedef.Date // date of recovery to the peak; zero if not recovered
+
+	// Duration from peak to recovery (or the last date if not recovered).
+	Duration time.Duration
+}
+
+// Recovered reports whether drawdown was recovered.
+func (d Drawdown) Recovered() bool {
+	return !d.Recovery.Time().IsZero()
+}
+
+// MaxDrawdown provides maximal drawdown of prices (or equity).
+func MaxDrawdown(prices Series) Drawdown {
+	dd := Drawdown{}
+	if prices.Len() == 0 {
+		return dd
+	}
+
+	peak, peakIdx := prices.Values[0], 0
+	for i, v := range prices.Values {
+		if v >= peak {
+			peak, peakIdx = v, i
+			continue
+		}
+		if d := 1 - v/peak; d > dd.Max {
+			dd.Max = d
+			dd.Peak = prices.Dates[peakIdx]
+			dd.Trough = prices.Dates[i]
+		}
+	}
+	if dd.Max == 0 {
+		return dd
+	}
+
+	end := prices.Dates[prices.Len()-1]
+	peakValue := 0.0
+	for i, d := range prices.Dates {
+		switch {
+		case d == dd.Peak:
+			peakValue = prices.Values[i]
+		case d.Time().After(dd.Trough.Time()) && prices.Values[i] >= peakValue:
+			dd.Recovery = d
+			end = d
+		}
+		if dd.Recovered() {
+			break
+		}
+	}
+	dd.Duration = end.Time().Sub(dd.Peak.Time())
+
+	return dd
+}
+
+// Calmar provides ratio of CAGR and maximal drawdown of prices (or equity).
+func Calmar(prices Series) (float64, error) {
+	cagr, err := CAGR(prices)
+	if err != nil {
+		return math.NaN(), err
+	}
+	dd := MaxDrawdown(prices)
+	if dd.Max == 0 {
+		return math.Inf(1), nil
+	}
+	return cagr / dd.Max, nil
+}
@@ -0,0 +1,149 @@
+package stats
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/profioss/trada/pkg/typedef"
+)
+
+// Beta provides beta and annualized alpha (Jensen's alpha) of returns
+// versus benchmark returns on common dates; rf is annual risk free rate.
+func Beta(returns, benchmark Series, rf float64, periods int) (beta, alpha float64, err error) {
+	a := Align(returns, benchmark)
+	r, b := a[0].Values, a[1].Values
+	if len(r) < 2 {
+		return math.NaN(), math.NaN(), errors.New("at least 2 common dates are required")
+	}
+
+	varB := covariance(b, b)
+	if varB == 0 {
+		return math.NaN(), math.NaN(), errors.New("benchmark returns are constant")
+	}
+	beta = covariance(r, b) / varB
+
+	rfp := rf / float64(periods)
+	alpha = ((mean(r) - rfp) - beta*(mean(b)-rfp)) * float64(periods)
+
+	return beta, alpha, nil
+}
+
+// Correlation provides Pearson correlation coefficient of a and b
+// on common dates.
+func Correlation(a, b Series) float64 {
+	al := Align(a, b)
+	x, y := al[0].Values, al[1].Values
+	return covariance(x, y) / (stdDev(x) * stdDev(y))
+}
+
+// Matrix is correlation matrix at Date.
+type Matrix struct {
+	Date   typedef.Date
+	Values [][]float64
+}
+
+// CorrelationMatrix provides correlation matrix of series on common dates.
+func CorrelationMatrix(lst ...Series) Matrix {
+	if len(lst) == 0 {
+		return Matrix{Values: [][]float64{}}
+	}
+	aligned := Align(lst...)
+	m := Matrix{Values: corrMatrix(aligned, 0, len(aligned[0].Values))}
+	if n := aligned[0].Len(); n > 0 {
+		m.Date = aligned[0].Dates[n-1]
+	}
+	return m
+}
+
+// RollingCorrelation provides correlation matrices of series
+// on common dates over rolling window of given length.
+// Matrix at date d covers window ending at d.
+func RollingCorrelation(window int, lst ...Series) ([]Matrix, error) {
+	output := []Matrix{}
+	if window < 2 {
+		return output, fmt.Errorf("window %d is < 2", window)
+	}
+	if len(lst) == 0 {
+		return output, errors.New("no series")
+	}
+
+	aligned := Align(lst...)
+	for end := window; end <= aligned[0].Len(); end++ {
+		output = append(output, Matrix{
+			Date:   aligned[0].Dates[end-1],
+			Values: corrMatrix(aligned, end-window, end),
+		})
+	}
+
+	return output, nil
+}
+
+// corrMatrix provides correlation matrix of aligned series
+// at indices from - to (exclusive).
+func corrMatrix(aligned []Series, from, to int) [][]float64 {
+	n := len(aligned)
+	output := make([][]float64, n)
+	for i := range output {
+		output[i] = make([]float64, n)
+	}
+
+	for i := 0; i < n; i++ {
+		x := aligned[i].Values[from:to]
+		output[i][i] = 1
+		for j := i + 1; j < n; j++ {
+			y := aligned[j].Values[from:to]
+			c := covariance(x, y) / (stdDev(x) * stdDev(y))
+			output[i][j], output[j][i] = c, c
+		}
+	}
+
+	return output
+}
+
+// HistoricalVaR provides Value-at-Risk of returns at confidence level
+// (e.g. 0.95) as positive fraction: loss which is not exceeded
+// with given confidence according to historical returns.
+func HistoricalVaR(returns Series, confidence float64) (float64, error) {
+	if err := checkConfidence(confidence); err != nil {
+		return math.NaN(), err
+	}
+	if returns.Len() == 0 {
+		return math.NaN(), errors.New("no returns")
+	}
+
+	sorted := append([]float64{}, returns.Values...)
+	sort.Float64s(sorted)
+
+	// linear interpolation between closest ranks
+	pos := (1 - confidence) * float64(len(sorted)-1)
+	i := int(math.Floor(pos))
+	q := sorted[i]
+	if i+1 < len(sorted) {
+		q += (pos - float64(i)) * (sorted[i+1] - sorted[i])
+	}
+
+	return -q, nil
+}
+
+// ParametricVaR provides Value-at-Risk of returns at confidence level
+// (e.g. 0.95) as positive fraction assuming normally distributed returns.
+func ParametricVaR(returns Series, confidence float64) (float64, error) {
+	if err := checkConfidence(confidence); err != nil {
+		return math.NaN(), err
+	}
+	if returns.Len() < 2 {
+		return math.NaN(), errors.New("at least 2 returns are required")
+	}
+
+	z := math.Sqrt2 * math.Erfinv(2*confidence-1)
+	return z*stdDev(returns.Values) - mean(returns.Values), nil
+}
+
+func checkConfidence(c float64) error {
+	if c <= 0 || c >= 1 {
+		return fmt.Errorf("confidence %g is not in range (0, 1)", c)
+	}
+	return nil
+}
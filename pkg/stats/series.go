@@ -0,0 +1,149 @@
+// Package stats computes performance and risk statistics
+// of price or equity series.
+//
+// Statistics are computed in float64; annualized values use number
+// of periods per year e.g. TradingDays for daily series.
+package stats
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/profioss/trada/model/ohlc"
+	"github.com/profioss/trada/pkg/typedef"
+)
+
+// TradingDays is number of trading days per year.
+const TradingDays = 252
+
+// Series is series of values (prices, equity, returns) sorted by date.
+type Series struct {
+	Dates  []typedef.Date
+	Values []float64
+}
+
+// FromVec creates Series of Close prices.
+func FromVec(v ohlc.Vec) Series {
+	f := v.Floats()
+	return Series{Dates: append([]typedef.Date{}, f.Dates...), Values: f.Close}
+}
+
+// Validate checks if Series is valid.
+func (s Series) Validate() error {
+	if len(s.Dates) != len(s.Values) {
+		return fmt.Errorf("length of Dates %d and Values %d differs", len(s.Dates), len(s.Values))
+	}
+	for i := range s.Dates {
+		switch {
+		case i > 0 && !s.Dates[i-1].Time().Before(s.Dates[i].Time()):
+			return fmt.Errorf("dates are not sorted at %s", s.Dates[i])
+		case math.IsNaN(s.Values[i]) || math.IsInf(s.Values[i], 0):
+			return fmt.Errorf("invalid value at %s", s.Dates[i])
+		}
+	}
+	return nil
+}
+
+// Len provides number of values.
+func (s Series) Len() int {
+	return len(s.Values)
+}
+
+// Returns provides simple returns of series of prices (or equity).
+// Return at date d is change from the previous value.
+func (s Series) Returns() (Series, error) {
+	output := Series{Dates: []typedef.Date{}, Values: []float64{}}
+	if s.Len() < 2 {
+		return output, errors.New("at least 2 values are required")
+	}
+
+	for i := 1; i < s.Len(); i++ {
+		prev := s.Values[i-1]
+		if prev <= 0 {
+			return output, fmt.Errorf("value %g at %s is not positive", prev, s.Dates[i-1])
+		}
+		output.Dates = append(output.Dates, s.Dates[i])
+		output.Values = append(output.Values, s.Values[i]/prev-1)
+	}
+
+	return output, nil
+}
+
+// Range provides part of series within dates from and to (inclusive).
+// Zero date means unbounded.
+func (s Series) Range(from, to typedef.Date) Series {
+	output := Series{Dates: []typedef.Date{}, Values: []float64{}}
+	for i, d := range s.Dates {
+		if !from.Time().IsZero() && d.Time().Before(from.Time()) {
+			continue
+		}
+		if !to.Time().IsZero() && d.Time().After(to.Time()) {
+			break
+		}
+		output.Dates = append(output.Dates, d)
+		output.Values = append(output.Values, s.Values[i])
+	}
+	return output
+}
+
+// Align provides series restricted to common dates.
+func Align(lst ...Series) []Series {
+	count := map[typedef.Date]int{}
+	for _, s := range lst {
+		for _, d := range s.Dates {
+			count[d]++
+		}
+	}
+
+	output := make([]Series, len(lst))
+	for i, s := range lst {
+		a := Series{Dates: []typedef.Date{}, Values: []float64{}}
+		for j, d := range s.Dates {
+			if count[d] == len(lst) {
+				a.Dates = append(a.Dates, d)
+				a.Values = append(a.Values, s.Values[j])
+			}
+		}
+		output[i] = a
+	}
+
+	return output
+}
+
+func mean(lst []float64) float64 {
+	if len(lst) == 0 {
+		return math.NaN()
+	}
+	sum := 0.0
+	for _, v := range lst {
+		sum += v
+	}
+	return sum / float64(len(lst))
+}
+
+// stdDev provides sample standard deviation.
+func stdDev(lst []float64) float64 {
+	if len(lst) < 2 {
+		return math.NaN()
+	}
+	m := mean(lst)
+	sum := 0.0
+	for _, v := range lst {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(lst)-1))
+}
+
+// covariance provides sample covariance.
+func covariance(a, b []float64) float64 {
+	if len(a) < 2 || len(a) != len(b) {
+		return math.NaN()
+	}
+	ma, mb := mean(a), mean(b)
+	sum := 0.0
+	for i := range a {
+		sum += (a[i] - ma) * (b[i] - mb)
+	}
+	return sum / float64(len(a)-1)
+}
@@ -0,0 +1,135 @@
+package stats
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/profioss/trada/pkg/typedef"
+)
+
+// mkSeries creates daily series starting 2020-01-01.
+func mkSeries(values ...float64) Series {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := Series{Dates: make([]typedef.Date, len(values)), Values: values}
+	for i := range values {
+		s.Dates[i] = typedef.Date(start.AddDate(0, 0, i))
+	}
+	return s
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestPerformance(t *testing.T) {
+	two := Series{
+		Dates:  []typedef.Date{typedef.Date(time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)), typedef.Date(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))},
+		Values: []float64{100, 121},
+	}
+	cagr, err := CAGR(two)
+	if err != nil || math.Abs(cagr-0.1) > 1e-4 {
+		t.Errorf("CAGR should be ~0.1, got %v (%v)", cagr, err)
+	}
+	if _, err := CAGR(mkSeries(100)); err == nil {
+		t.Errorf("CAGR of single value - should have an error")
+	}
+
+	r, err := mkSeries(100, 110, 99).Returns()
+	if err != nil || r.Len() != 2 || !near(r.Values[0], 0.1) || !near(r.Values[1], -0.1) {
+		t.Errorf("unexpected returns: %v (%v)", r.Values, err)
+	}
+	if vol := Volatility(r, TradingDays); !near(vol, math.Sqrt(0.02)*math.Sqrt(TradingDays)) {
+		t.Errorf("unexpected volatility: %v", vol)
+	}
+	if s := Sharpe(r, 0, TradingDays); !near(s, 0) {
+		t.Errorf("Sharpe should be 0, got %v", s)
+	}
+	// downside deviation sqrt(0.01 / 2)
+	up, _ := mkSeries(100, 110, 104.5).Returns() // 0.1, -0.05
+	if s := Sortino(up, 0, 1); !near(s, 0.025/math.Sqrt(0.0025/2)) {
+		t.Errorf("unexpected Sortino: %v", s)
+	}
+}
+
+func TestMaxDrawdown(t *testing.T) {
+	tests := []struct {
+		label     string
+		values    []float64
+		max       float64
+		peak      int
+		trough    int
+		recovery  int // -1 if not recovered
+		durationD int
+	}{
+		{label: "recovered", values: []float64{100, 120, 90, 110, 125, 100}, max: 0.25, peak: 1, trough: 2, recovery: 4, durationD: 3},
+		{label: "not recovered", values: []float64{100, 80, 90, 60, 70}, max: 0.4, peak: 0, trough: 3, recovery: -1, durationD: 4},
+		{label: "rising", values: []float64{100, 101, 102}, recovery: -1},
+	}
+
+	for _, tc := range tests {
+		s := mkSeries(tc.values...)
+		dd := MaxDrawdown(s)
+		switch {
+		case !near(dd.Max, tc.max):
+			t.Errorf("%s - max should be %v, got %v", tc.label, tc.max, dd.Max)
+		case tc.max == 0:
+		case dd.Peak != s.Dates[tc.peak] || dd.Trough != s.Dates[tc.trough]:
+			t.Errorf("%s - unexpected peak %s or trough %s", tc.label, dd.Peak, dd.Trough)
+		case tc.recovery < 0 && dd.Recovered(), tc.recovery >= 0 && dd.Recovery != s.Dates[tc.recovery]:
+			t.Errorf("%s - unexpected recovery %s", tc.label, dd.Recovery)
+		case dd.Duration != time.Duration(tc.durationD)*24*time.Hour:
+			t.Errorf("%s - duration should be %d days, got %s", tc.label, tc.durationD, dd.Duration)
+		}
+	}
+
+	calmar, err := Calmar(mkSeries(100, 120, 90, 110, 125, 95))
+	if err != nil || calmar >= 0 {
+		t.Errorf("Calmar of losing series should be negative, got %v (%v)", calmar, err)
+	}
+}
+
+func TestRisk(t *testing.T) {
+	b := mkSeries(0.01, -0.02, 0.015, 0.005, -0.01)
+	r := mkSeries(0.02, -0.04, 0.03, 0.01, -0.02)
+
+	beta, alpha, err := Beta(r, b, 0, TradingDays)
+	if err != nil || !near(beta, 2) || !near(alpha, 0) {
+		t.Errorf("beta should be 2 and alpha 0, got %v, %v (%v)", beta, alpha, err)
+	}
+
+	neg := mkSeries(-0.01, 0.02, -0.015, -0.005, 0.01)
+	if c := Correlation(b, neg); !near(c, -1) {
+		t.Errorf("correlation should be -1, got %v", c)
+	}
+
+	m := CorrelationMatrix(b, r, neg)
+	if !near(m.Values[0][1], 1) || !near(m.Values[1][2], -1) || m.Values[2][2] != 1 || m.Date != b.Dates[4] {
+		t.Errorf("unexpected correlation matrix: %+v", m)
+	}
+	rolling, err := RollingCorrelation(3, b, r)
+	if err != nil || len(rolling) != 3 || rolling[0].Date != b.Dates[2] || !near(rolling[2].Values[0][1], 1) {
+		t.Errorf("unexpected rolling correlation: %+v (%v)", rolling, err)
+	}
+	// partially overlapping dates
+	short := Series{Dates: b.Dates[1:], Values: b.Values[1:]}
+	if rolling, _ := RollingCorrelation(2, short, r); len(rolling) != 3 {
+		t.Errorf("rolling correlation on common dates should have 3 matrices, got %d", len(rolling))
+	}
+
+	ret := mkSeries(0.04, -0.05, 0.03, -0.04, 0.02, -0.03, 0.01, -0.02, 0, -0.01)
+	v, err := HistoricalVaR(ret, 0.9)
+	if err != nil || !near(v, 0.041) {
+		t.Errorf("historical VaR should be 0.041, got %v (%v)", v, err)
+	}
+
+	alt := mkSeries(0.01, -0.01, 0.01, -0.01)
+	v, err = ParametricVaR(alt, 0.95)
+	expected := 1.6448536269514722 * stdDev(alt.Values)
+	if err != nil || math.Abs(v-expected) > 1e-9 {
+		t.Errorf("parametric VaR should be %v, got %v (%v)", expected, v, err)
+	}
+	if _, err := ParametricVaR(alt, 1); err == nil {
+		t.Errorf("confidence 1 - should have an error")
+	}
+}
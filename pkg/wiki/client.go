@@ -0,0 +1,192 @@
+package wiki
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// defaultRetryAfter is used if API does not suggest delay.
+const defaultRetryAfter = 5 * time.Second
+
+// Page identifies wiki page or its revision.
+type Page struct {
+	Name  string
+	OldID int64 // revision id; latest revision if 0
+}
+
+// Client is Wiki API client.
+type Client struct {
+	// API is API endpoint e.g. https://en.wikipedia.org/w/api.php
+	API string
+
+	HTTP *http.Client
+
+	// MaxLag is maxlag parameter in seconds, 0 disables it.
+	// https://www.mediawiki.org/wiki/Manual:Maxlag_parameter
+	MaxLag int
+
+	// Retries is number of retries of rate limited and maxlag responses.
+	Retries int
+}
+
+// ParseURL creates URL of page section content with revision id.
+// Redirects are followed.
+func (c *Client) ParseURL(p Page, section int) (url.URL, error) {
+	return c.mkURL(map[string]string{
+		"action":    "parse",
+		"prop":      "text|revid",
+		"redirects": "1",
+		"section":   strconv.Itoa(section),
+	}, p)
+}
+
+// SectionsURL creates URL of page sections list.
+func (c *Client) SectionsURL(p Page) (url.URL, error) {
+	return c.mkURL(map[string]string{
+		"action":    "parse",
+		"prop":      "sections",
+		"redirects": "1",
+	}, p)
+}
+
+// RevisionURL creates URL of revision info.
+func (c *Client) RevisionURL(revID int64) (url.URL, error) {
+	return c.mkURL(map[string]string{
+		"action": "query",
+		"prop":   "revisions",
+		"rvprop": "ids|timestamp|user|comment",
+		"revids": strconv.FormatInt(revID, 10),
+	}, Page{})
+}
+
+func (c *Client) mkURL(params map[string]string, p Page) (url.URL, error) {
+	u, err := url.Parse(c.API)
+	if err != nil {
+		return url.URL{}, err
+	}
+
+	q := u.Query()
+	q.Set("format", "json")
+	for k, v := range params {
+		q.Set(k, v)
+	}
+	switch {
+	case p.OldID > 0: // pinned revision
+		q.Set("oldid", strconv.FormatInt(p.OldID, 10))
+	case p.Name != "":
+		q.Set("page", p.Name)
+	}
+	u.RawQuery = q.Encode()
+
+	return *u, nil
+}
+
+// Get fetches raw API response.
+// Rate limited and maxlag responses are retried after delay suggested by API.
+// Other API errors are not detected, use Parse functions.
+func (c *Client) Get(ctx context.Context, u url.URL) ([]byte, error) {
+	if c.MaxLag > 0 {
+		q := u.Query()
+		q.Set("maxlag", strconv.Itoa(c.MaxLag))
+		u.RawQuery = q.Encode()
+	}
+
+	for attempt := 0; ; attempt++ {
+		data, err := c.get(ctx, u)
+		apiErr := &APIError{}
+		if err == nil || !errors.As(err, &apiErr) || !apiErr.Temporary() || attempt >= c.Retries {
+			return data, err
+		}
+
+		timer := time.NewTimer(apiErr.RetryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return data, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) get(ctx context.Context, u url.URL) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response error: %v", err)
+	}
+
+	// MediaWiki reports API error code in header
+	code := resp.Header.Get("MediaWiki-API-Error")
+	if resp.StatusCode == http.StatusTooManyRequests {
+		code = "ratelimited"
+	}
+	switch {
+	case code == "ratelimited" || code == "maxlag":
+		return data, &APIError{
+			Code:       code,
+			Info:       fmt.Sprintf("HTTP status: %s, database lag: %ss", resp.Status, resp.Header.Get("X-Database-Lag")),
+			RetryAfter: retryAfter(resp.Header),
+		}
+
+	case resp.StatusCode != http.StatusOK:
+		return data, fmt.Errorf("HTTP error: %d for %s", resp.StatusCode, u.String())
+	}
+
+	return data, nil
+}
+
+func (c *Client) client() *http.Client {
+	if c.HTTP == nil {
+		return http.DefaultClient
+	}
+	return c.HTTP
+}
+
+// Sections fetches list of page sections.
+func (c *Client) Sections(ctx context.Context, p Page) ([]Section, error) {
+	u, err := c.SectionsURL(p)
+	if err != nil {
+		return []Section{}, err
+	}
+	data, err := c.Get(ctx, u)
+	if err != nil {
+		return []Section{}, err
+	}
+
+	return ParseSections(bytes.NewReader(data))
+}
+
+// SectionByTitle finds page section by heading title.
+func (c *Client) SectionByTitle(ctx context.Context, p Page, title string) (Section, error) {
+	sections, err := c.Sections(ctx, p)
+	if err != nil {
+		return Section{}, err
+	}
+
+	return FindSection(sections, title)
+}
+
+// retryAfter parses Retry-After header in seconds.
+func retryAfter(h http.Header) time.Duration {
+	sec, err := strconv.Atoi(h.Get("Retry-After"))
+	if err != nil || sec < 1 {
+		return defaultRetryAfter
+	}
+	return time.Duration(sec) * time.Second
+}
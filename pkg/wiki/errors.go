@@ -0,0 +1,56 @@
+package wiki
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Errors reported by Wiki API. Use errors.Is to check *APIError kind.
+var (
+	ErrMissingPage    = errors.New("wiki: missing page")
+	ErrInvalidSection = errors.New("wiki: invalid section")
+	ErrRateLimited    = errors.New("wiki: rate limited")
+	ErrMaxLag         = errors.New("wiki: database lag exceeds maxlag")
+)
+
+// error codes of Wiki API
+// https://www.mediawiki.org/wiki/API:Errors_and_warnings
+var codeErrMap = map[string]error{
+	"missingtitle":   ErrMissingPage,
+	"nosuchpageid":   ErrMissingPage,
+	"nosuchrevid":    ErrMissingPage,
+	"missingcontent": ErrMissingPage,
+	"nosuchsection":  ErrInvalidSection,
+	"invalidsection": ErrInvalidSection,
+	"ratelimited":    ErrRateLimited,
+	"maxlag":         ErrMaxLag,
+}
+
+// APIError is error reported by Wiki API.
+type APIError struct {
+	Code string
+	Info string
+
+	// RetryAfter is delay suggested by API for rate limited and maxlag errors.
+	RetryAfter time.Duration
+}
+
+func newAPIError(code, info string) *APIError {
+	return &APIError{Code: code, Info: info}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("wiki API error: %s, %s", e.Code, e.Info)
+}
+
+// Is reports whether APIError is of kind given by target e.g. ErrMissingPage.
+func (e *APIError) Is(target error) bool {
+	kind, ok := codeErrMap[e.Code]
+	return ok && kind == target
+}
+
+// Temporary reports whether request can be retried later.
+func (e *APIError) Temporary() bool {
+	return e.Is(ErrRateLimited) || e.Is(ErrMaxLag)
+}
@@ -0,0 +1,81 @@
+package wiki
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Section is wiki page section as listed in page "Contents" box.
+type Section struct {
+	TocLevel int    `json:"toclevel"`
+	Level    string `json:"level"`
+	Line     string `json:"line"`   // heading title, can contain HTML markup
+	Number   string `json:"number"` // e.g. 3.1
+	Index    string `json:"index"`  // section parameter of parse API; T-<n> for transcluded section
+	Anchor   string `json:"anchor"`
+}
+
+// Title returns section heading as plain text.
+func (s Section) Title() string {
+	doc, err := html.Parse(strings.NewReader(s.Line))
+	if err != nil {
+		return s.Line
+	}
+
+	var sb strings.Builder
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(doc)
+
+	return strings.TrimSpace(sb.String())
+}
+
+// ID returns section index usable as section parameter of parse API.
+func (s Section) ID() (int, error) {
+	id, err := strconv.Atoi(s.Index)
+	if err != nil {
+		return 0, fmt.Errorf("section %q has index %q which is not a page section", s.Title(), s.Index)
+	}
+	return id, nil
+}
+
+// ParseSections parses response of action=parse&prop=sections.
+func ParseSections(r io.Reader) ([]Section, error) {
+	d, err := Parse(r)
+	if err != nil {
+		return []Section{}, err
+	}
+
+	return d.Parsed.Sections, nil
+}
+
+// FindSection finds section by heading title.
+// Title comparison is case insensitive, section anchor is accepted as well.
+func FindSection(sections []Section, title string) (Section, error) {
+	t := strings.TrimSpace(title)
+	for _, s := range sections {
+		if strings.EqualFold(s.Title(), t) || strings.EqualFold(s.Anchor, t) {
+			return s, nil
+		}
+	}
+
+	titles := make([]string, 0, len(sections))
+	for _, s := range sections {
+		titles = append(titles, fmt.Sprintf("%q", s.Title()))
+	}
+	return Section{}, &APIError{
+		Code: "nosuchsection",
+		Info: fmt.Sprintf("section %q not found; available: %s", title, strings.Join(titles, ", ")),
+	}
+}
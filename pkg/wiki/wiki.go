@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"time"
 )
 
 // response from API
@@ -15,15 +16,24 @@ type Data struct {
 }
 
 type jdata struct {
-	Title   string  `json:"title"`
-	PageID  int64   `json:"pageid"`
-	Content content `json:"text"`
+	Title     string     `json:"title"`
+	PageID    int64      `json:"pageid"`
+	RevID     int64      `json:"revid"`     // requires prop=revid
+	Redirects []Redirect `json:"redirects"` // requires redirects=1
+	Sections  []Section  `json:"sections"`  // requires prop=sections
+	Content   content    `json:"text"`
 }
 
 type content struct {
 	Text string `json:"*"`
 }
 
+// Redirect describes followed page redirect.
+type Redirect struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
 // Error is Wiki API error response.
 type Error struct {
 	Error    APIerr `json:"error"`
@@ -37,27 +47,74 @@ type APIerr struct {
 	content
 }
 
+// response is either Data or Error.
+type response struct {
+	Data
+	Error *APIerr `json:"error"`
+}
+
 // Parse parses input as error or Data.
-func Parse(r io.ReadSeeker) (Data, error) {
-	// func parseWikiData(fname string) (Data, error) {
-	d := Data{}
-
-	// try parse as wikiError & check if there is actual error message
-	dErr := Error{}
-	err := json.NewDecoder(r).Decode(&dErr)
-	if err == nil && len(dErr.Error.Code) > 0 {
-		return d, fmt.Errorf("data error: %s, %s", dErr.Error.Code, dErr.Error.Info)
+// API error is returned as *APIError.
+func Parse(r io.Reader) (Data, error) {
+	resp := response{}
+	err := json.NewDecoder(r).Decode(&resp)
+	if err != nil {
+		return resp.Data, err
 	}
+	if resp.Error != nil {
+		return resp.Data, newAPIError(resp.Error.Code, resp.Error.Info)
+	}
+
+	return resp.Data, nil
+}
 
-	// parse as wikiData if data doesn't contain error details
-	_, err = r.Seek(0, 0)
+// Revision is page revision info.
+// Parse API does not provide revision timestamp, it is available
+// from query API: action=query&prop=revisions&revids=<RevID>.
+type Revision struct {
+	RevID     int64     `json:"revid"`
+	ParentID  int64     `json:"parentid"`
+	User      string    `json:"user"`
+	Timestamp time.Time `json:"timestamp"`
+	Comment   string    `json:"comment"`
+}
+
+// response of action=query&prop=revisions
+type revQuery struct {
+	Error *APIerr `json:"error"`
+	Query struct {
+		BadRevIDs map[string]struct {
+			RevID int64 `json:"revid"`
+		} `json:"badrevids"`
+		Pages map[string]struct {
+			Title     string     `json:"title"`
+			Revisions []Revision `json:"revisions"`
+		} `json:"pages"`
+	} `json:"query"`
+}
+
+// ParseRevision parses query API response as error or Revision.
+// If response contains more revisions the first one is returned.
+func ParseRevision(r io.Reader) (Revision, error) {
+	rev := Revision{}
+
+	d := revQuery{}
+	err := json.NewDecoder(r).Decode(&d)
 	if err != nil {
-		return d, fmt.Errorf("rewind IO reader failed: %s", err)
+		return rev, err
 	}
-	err = json.NewDecoder(r).Decode(&d)
-	if err != nil {
-		return d, err
+	if d.Error != nil {
+		return rev, newAPIError(d.Error.Code, d.Error.Info)
+	}
+
+	for id := range d.Query.BadRevIDs {
+		return rev, newAPIError("nosuchrevid", fmt.Sprintf("There is no revision with ID %s.", id))
+	}
+	for _, p := range d.Query.Pages {
+		if len(p.Revisions) > 0 {
+			return p.Revisions[0], nil
+		}
 	}
 
-	return d, nil
+	return rev, fmt.Errorf("data error: no revision found")
 }
@@ -0,0 +1,137 @@
+package wiki
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		label string
+		input string
+		kind  error
+	}{
+		{
+			label: "missing page",
+			input: `{"error":{"code":"missingtitle","info":"The page you specified doesn't exist.","*":""},"servedby":"mw1"}`,
+			kind:  ErrMissingPage,
+		},
+		{
+			label: "invalid section",
+			input: `{"error":{"code":"nosuchsection","info":"There is no section 99.","*":""}}`,
+			kind:  ErrInvalidSection,
+		},
+		{
+			label: "maxlag",
+			input: `{"error":{"code":"maxlag","info":"Waiting for a database server: 6 seconds lagged.","*":""}}`,
+			kind:  ErrMaxLag,
+		},
+	}
+
+	for _, tc := range tests {
+		_, err := Parse(strings.NewReader(tc.input))
+		apiErr := &APIError{}
+		switch {
+		case !errors.Is(err, tc.kind):
+			t.Errorf("%s - expected %v, got %v", tc.label, tc.kind, err)
+		case !errors.As(err, &apiErr):
+			t.Errorf("%s - expected *APIError, got %T", tc.label, err)
+		}
+	}
+
+	d, err := Parse(strings.NewReader(`{"parse":{"title":"NASDAQ-100","pageid":1,"revid":2,` +
+		`"redirects":[{"from":"Nasdaq-100","to":"NASDAQ-100"}],"text":{"*":"<p>x</p>"}}}`))
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case d.Parsed.RevID != 2 || len(d.Parsed.Redirects) != 1 || d.Parsed.Content.Text != "<p>x</p>":
+		t.Errorf("unexpected data: %#v", d)
+	}
+}
+
+func TestFindSection(t *testing.T) {
+	input := `{"parse":{"title":"NASDAQ-100","pageid":1,"sections":[
+		{"toclevel":1,"level":"2","line":"History","number":"1","index":"1","anchor":"History"},
+		{"toclevel":2,"level":"3","line":"<i>Annual</i> returns","number":"1.1","index":"2","anchor":"Annual_returns"},
+		{"toclevel":1,"level":"2","line":"Components","number":"2","index":"3","anchor":"Components"},
+		{"toclevel":1,"level":"2","line":"Transcluded","number":"3","index":"T-1","anchor":"Transcluded"}]}}`
+
+	sections, err := ParseSections(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		title  string
+		id     int
+		hasErr bool
+	}{
+		{title: "Components", id: 3},
+		{title: "components", id: 3},
+		{title: "Annual returns", id: 2},
+		{title: "Annual_returns", id: 2},
+		{title: "Transcluded", hasErr: true},
+		{title: "Nonexistent", hasErr: true},
+	}
+
+	for _, tc := range tests {
+		s, err := FindSection(sections, tc.title)
+		if err == nil {
+			var id int
+			id, err = s.ID()
+			if err == nil && id != tc.id {
+				t.Errorf("%s - expected section %d, got %d", tc.title, tc.id, id)
+			}
+		}
+		switch {
+		case tc.hasErr && err == nil:
+			t.Errorf("%s - should have an error", tc.title)
+		case !tc.hasErr && err != nil:
+			t.Errorf("%s - unexpected error: %v", tc.title, err)
+		}
+	}
+
+	_, err = FindSection(sections, "Nonexistent")
+	if !errors.Is(err, ErrInvalidSection) {
+		t.Errorf("section not found should be ErrInvalidSection, got %v", err)
+	}
+}
+
+func TestClientRetry(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Query().Get("maxlag") != "5" {
+			t.Errorf("maxlag parameter should be 5, got %q", r.URL.Query().Get("maxlag"))
+		}
+		if calls == 1 {
+			w.Header().Set("MediaWiki-API-Error", "maxlag")
+			w.Header().Set("Retry-After", "1")
+			w.Write([]byte(`{"error":{"code":"maxlag","info":"lagged"}}`))
+			return
+		}
+		w.Write([]byte(`{"parse":{"title":"T","pageid":1,"sections":[]}}`))
+	}))
+	defer srv.Close()
+
+	c := Client{API: srv.URL, MaxLag: 5, Retries: 1}
+	_, err := c.Sections(context.Background(), Page{Name: "T"})
+	switch {
+	case err != nil:
+		t.Errorf("unexpected error: %v", err)
+	case calls != 2:
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+
+	// retries exhausted
+	calls = 0
+	c.Retries = 0
+	_, err = c.Sections(context.Background(), Page{Name: "T"})
+	if !errors.Is(err, ErrMaxLag) {
+		t.Errorf("expected ErrMaxLag, got %v", err)
+	}
+}